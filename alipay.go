@@ -3,6 +3,7 @@ package alipay
 
 import (
 	"bytes"
+	"context"
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
@@ -27,7 +28,8 @@ import (
 
 // AlipayGateway ...
 const (
-	AlipayGateway = "https://openapi.alipay.com/gateway.do"
+	AlipayGateway        = "https://openapi.alipay.com/gateway.do"
+	AlipaySandboxGateway = "https://openapi.alipaydev.com/gateway.do" // 沙箱环境
 )
 
 var client = &http.Client{
@@ -39,18 +41,45 @@ var client = &http.Client{
 
 // CommonParam ...
 type CommonParam struct {
-	AppID        string `url:"app_id,omitempty"`         // 支付宝分配给开发者的应用ID
-	Method       string `url:"method,omitempty"`         // 接口名称
-	Format       string `url:"format,omitempty"`         // 仅支持JSON
-	ReturnURL    string `url:"return_url,omitempty"`     // 同步返回地址
-	Charset      string `url:"charset,omitempty"`        // 请求使用的编码格式，如utf-8,gbk,gb2312等
-	SignType     string `url:"sign_type,omitempty"`      // 商户生成签名字符串所使用的签名算法类型，目前支持RSA2和RSA，推荐使用RSA2
-	Sign         string `url:"sign,omitempty"`           // 商户请求参数的签名串
-	Timestamp    string `url:"timestamp,omitempty"`      // 发送请求的时间，格式"yyyy-MM-dd HH:mm:ss"
-	Version      string `url:"version,omitempty"`        // 调用的接口版本，固定为：1.0
-	NotifyURL    string `url:"notify_url,omitempty"`     // 支付宝服务器主动通知商户服务器里指定的页面http/https路径。
-	AppAuthToken string `url:"app_auth_token,omitempty"` // 详见应用授权概述
-	BizContent   string `url:"biz_content,omitempty"`    // 请求参数的集合
+	AppID        string     `url:"app_id,omitempty"`              // 支付宝分配给开发者的应用ID
+	Method       string     `url:"method,omitempty"`              // 接口名称
+	Format       string     `url:"format,omitempty"`              // 仅支持JSON
+	ReturnURL    string     `url:"return_url,omitempty"`          // 同步返回地址
+	Charset      string     `url:"charset,omitempty"`             // 请求使用的编码格式，如utf-8,gbk,gb2312等
+	SignType     string     `url:"sign_type,omitempty"`           // 商户生成签名字符串所使用的签名算法类型，目前支持RSA2和RSA，推荐使用RSA2
+	Sign         string     `url:"sign,omitempty"`                // 商户请求参数的签名串
+	Timestamp    string     `url:"timestamp,omitempty"`           // 发送请求的时间，格式"yyyy-MM-dd HH:mm:ss"
+	Version      string     `url:"version,omitempty"`             // 调用的接口版本，固定为：1.0
+	NotifyURL    string     `url:"notify_url,omitempty"`          // 支付宝服务器主动通知商户服务器里指定的页面http/https路径。
+	AppAuthToken string     `url:"app_auth_token,omitempty"`      // 详见应用授权概述
+	AuthToken    string     `url:"auth_token,omitempty"`          // 用户授权令牌，用户信息类接口使用
+	AppCertSN    string     `url:"app_cert_sn,omitempty"`         // 应用公钥证书SN，公钥证书模式下必填
+	RootCertSN   string     `url:"alipay_root_cert_sn,omitempty"` // 支付宝根证书SN，公钥证书模式下必填
+	WsServiceURL string     `url:"ws_service_url,omitempty"`      // 部分接口需要传入的商户服务地址
+	BizContent   string     `url:"biz_content,omitempty"`         // 请求参数的集合
+	ExtraParam   url.Values `url:"-"`                             // 其他系统参数，参与签名
+
+	Context context.Context `url:"-"` // 不是请求参数，用于取消请求以及作为追踪的父Span，见WithContext
+}
+
+// ctx Context为空时返回context.Background()
+func (cp *CommonParam) ctx() context.Context {
+	if cp.Context == nil {
+		return context.Background()
+	}
+	return cp.Context
+}
+
+// Values 把公共参数序列化成url.Values，ExtraParam中同名参数会覆盖结构体字段。
+func (cp *CommonParam) Values() (url.Values, error) {
+	values, err := query.Values(cp)
+	if err != nil {
+		return nil, err
+	}
+	for key, list := range cp.ExtraParam {
+		values[key] = list
+	}
+	return values, nil
 }
 
 var defaultCommonParam = CommonParam{
@@ -65,7 +94,19 @@ type Alipay struct {
 	appID string
 	*rsa.PrivateKey
 	*rsa.PublicKey
-	client *http.Client
+	client     *http.Client
+	gateway    string
+	appCertSN  string
+	rootCertSN string
+
+	interceptors []Interceptor
+	tracer       Tracer
+	metrics      Metrics
+}
+
+// IsCertMode 是否使用公钥证书模式
+func (alipay *Alipay) IsCertMode() bool {
+	return len(alipay.appCertSN) > 0
 }
 
 // HTTPClient ...
@@ -73,6 +114,24 @@ func (alipay *Alipay) HTTPClient() *http.Client {
 	return alipay.client
 }
 
+// SetHTTPClient 替换默认的http.Client，例如设置更短的超时或者自定义Transport
+func (alipay *Alipay) SetHTTPClient(client *http.Client) {
+	alipay.client = client
+}
+
+// Gateway 请求的网关地址，默认为AlipayGateway
+func (alipay *Alipay) Gateway() string {
+	if len(alipay.gateway) == 0 {
+		return AlipayGateway
+	}
+	return alipay.gateway
+}
+
+// SetGateway 设置网关地址，例如AlipaySandboxGateway或者测试用的本地网关
+func (alipay *Alipay) SetGateway(gateway string) {
+	alipay.gateway = gateway
+}
+
 // AppID ...
 func (alipay *Alipay) AppID() string {
 	return alipay.appID
@@ -102,6 +161,13 @@ func WithAppAuthToken(authToken string) Fill {
 	}
 }
 
+// WithAuthToken ...
+func WithAuthToken(authToken string) Fill {
+	return func(cp *CommonParam) {
+		cp.AuthToken = authToken
+	}
+}
+
 // WithSignType ...
 func WithSignType(signType string) Fill {
 	return func(cp *CommonParam) {
@@ -109,14 +175,62 @@ func WithSignType(signType string) Fill {
 	}
 }
 
-// MakeParam ...
+// WithAppCertSN 公钥证书模式下的应用公钥证书SN
+func WithAppCertSN(appCertSN string) Fill {
+	return func(cp *CommonParam) {
+		cp.AppCertSN = appCertSN
+	}
+}
+
+// WithWsServiceURL ...
+func WithWsServiceURL(wsServiceURL string) Fill {
+	return func(cp *CommonParam) {
+		cp.WsServiceURL = wsServiceURL
+	}
+}
+
+// WithContext 请求使用ctx，ctx结束时取消请求，设置了Tracer时ctx中的Span作为父Span
+func WithContext(ctx context.Context) Fill {
+	return func(cp *CommonParam) {
+		cp.Context = ctx
+	}
+}
+
+// WithExtraParam 添加其他系统参数，例如alipay_root_cert_sn
+func WithExtraParam(key, value string) Fill {
+	return func(cp *CommonParam) {
+		if cp.ExtraParam == nil {
+			cp.ExtraParam = url.Values{}
+		}
+		cp.ExtraParam.Set(key, value)
+	}
+}
+
+// MakeParam content为nil时不传biz_content
 func (alipay *Alipay) MakeParam(content interface{}, method string, fillList ...Fill) (string, error) {
-	biz, err := json.Marshal(&content)
+	requestParam, err := alipay.newCommonParam(content, method, fillList...)
 	if err != nil {
-		return "", fmt.Errorf("支付宝请求业务参数序列化失败: %w", err)
+		return "", err
+	}
+	values, err := alipay.signParam(requestParam)
+	if err != nil {
+		return "", err
+	}
+	return values.Encode(), nil
+}
+
+// newCommonParam 生成公共参数并应用fillList，尚未签名
+func (alipay *Alipay) newCommonParam(content interface{}, method string, fillList ...Fill) (*CommonParam, error) {
+	var biz []byte
+	if content != nil {
+		var err error
+		biz, err = json.Marshal(&content)
+		if err != nil {
+			return nil, fmt.Errorf("支付宝请求业务参数序列化失败: %w", err)
+		}
 	}
 
-	requestParam := CommonParam{
+	requestParam := &CommonParam{
 		AppID:      alipay.appID,
 		Method:     method,
 		Format:     defaultCommonParam.Format,
@@ -124,62 +238,92 @@ func (alipay *Alipay) MakeParam(content interface{}, method string, fillList ...
 		SignType:   defaultCommonParam.SignType,
 		Timestamp:  time.Now().Format("2006-01-02 15:04:05"),
 		Version:    defaultCommonParam.Version,
+		AppCertSN:  alipay.appCertSN,
+		RootCertSN: alipay.rootCertSN,
 		BizContent: string(biz),
 	}
 
 	for _, fill := range fillList {
-		fill(&requestParam)
+		fill(requestParam)
 	}
+	return requestParam, nil
+}
 
-	requestSignValues, err := query.Values(&requestParam)
+// signParam 对除sign外的参数签名，返回带有sign的参数
+func (alipay *Alipay) signParam(requestParam *CommonParam) (url.Values, error) {
+	requestParam.Sign = ""
+	requestSignValues, err := requestParam.Values()
 	if err != nil {
-		return "", fmt.Errorf("支付宝请求参数序列化失败: %w", err)
+		return nil, fmt.Errorf("支付宝请求参数序列化失败: %w", err)
 	}
 
 	requestSignedStr := NormValues(requestSignValues)
 
 	requestSign, err := RSA2(alipay.PrivateKey, requestSignedStr)
 	if err != nil {
-		return "", fmt.Errorf("支付宝商户私钥签名不成功: %w", err)
+		return nil, fmt.Errorf("支付宝商户私钥签名不成功: %w", err)
 	}
 
 	requestParam.Sign = requestSign
-	values, err := query.Values(&requestParam)
+	values, err := requestParam.Values()
 	if err != nil {
-		return "", fmt.Errorf("支付宝请求结构体不能序列化: %w", err)
+		return nil, fmt.Errorf("支付宝请求结构体不能序列化: %w", err)
 	}
-
-	return values.Encode(), nil
+	return values, nil
 }
 
-// OnRequest ...
+// OnRequest 依次经过Use注册的拦截器之后发出请求
 func (alipay *Alipay) OnRequest(content interface{}, method string, fillList ...Fill) (int, []byte, error) {
-	request, err := http.NewRequest(http.MethodGet, AlipayGateway, nil)
+	param, err := alipay.newCommonParam(content, method, fillList...)
 	if err != nil {
-		return 0, nil, fmt.Errorf("支付宝生成请求失败: %w", err)
+		return 0, nil, fmt.Errorf("支付宝构造请求参数失败: %w", err)
 	}
 
-	param, err := alipay.MakeParam(content, method, fillList...)
+	inv := &Invocation{
+		Method:  method,
+		Param:   param,
+		Content: content,
+	}
+	ctx, span := alipay.startSpan(param.ctx(), SpanOnRequest)
+	param.Context = ctx
+	start := time.Now()
+	err = alipay.invoker()(inv)
+	alipay.observeInvocation(span, inv, err, time.Since(start))
 	if err != nil {
-		return 0, nil, fmt.Errorf("支付宝构造请求参数失败: %w", err)
+		return 0, nil, err
+	}
+	return inv.StatusCode, inv.Result, nil
+}
+
+// invoke 拦截器链的最后一环，签名、发出请求并验证响应签名
+func (alipay *Alipay) invoke(inv *Invocation) error {
+	request, err := http.NewRequestWithContext(inv.Param.ctx(), http.MethodGet, alipay.Gateway(), nil)
+	if err != nil {
+		return fmt.Errorf("支付宝生成请求失败: %w", err)
 	}
 
-	request.URL.RawQuery = param
+	values, err := alipay.signParam(inv.Param)
+	if err != nil {
+		return fmt.Errorf("支付宝构造请求参数失败: %w", err)
+	}
+
+	request.URL.RawQuery = values.Encode()
 	response, err := alipay.client.Do(request)
 	if err != nil {
-		return 0, nil, fmt.Errorf("支付宝发起请求失败: %w", err)
+		return fmt.Errorf("支付宝发起请求失败: %w", err)
 	}
 	defer response.Body.Close()
+	inv.StatusCode = response.StatusCode
 
 	body, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		return 0, nil, fmt.Errorf("支付宝请求结果读取body失败: %w", err)
+		return fmt.Errorf("支付宝请求结果读取body失败: %w", err)
 	}
 
 	obj := make(map[string]*json.RawMessage)
 
 	if err := json.Unmarshal(body, &obj); err != nil {
-		return 0, nil, fmt.Errorf("支付宝请求结果反序列化失败: %w", err)
+		return fmt.Errorf("支付宝请求结果反序列化失败: %w", err)
 	}
 
 	var (
@@ -202,20 +346,26 @@ func (alipay *Alipay) OnRequest(content interface{}, method string, fillList ...
 		var base64SignStr string
 
 		if err := json.Unmarshal(sig, &base64SignStr); err != nil {
-			return 0, nil, fmt.Errorf("反序列化签名失败: %w", err)
+			return fmt.Errorf("反序列化签名失败: %w", err)
 		}
 
 		signStr, err := base64.StdEncoding.DecodeString(base64SignStr)
 		if err != nil {
-			return 0, nil, fmt.Errorf("base64解码签名失败: %w", err)
+			return fmt.Errorf("base64解码签名失败: %w", err)
 		}
 
 		if err := Verify(alipay.PublicKey, data, signStr); err != nil {
-			return 0, nil, fmt.Errorf("支付宝同步请求签名验证不通过: %w", err)
+			alipay.incCounter(MetricSignVerifyFailuresTotal, map[string]string{"source": "response", "method": inv.Method})
+			return fmt.Errorf("支付宝同步请求签名验证不通过: %w", err)
 		}
 	}
 
-	return response.StatusCode, data, nil
+	inv.Result = data
+	inv.Response = ResponseError{}
+	if len(data) > 0 {
+		_ = json.Unmarshal(data, &inv.Response)
+	}
+	return nil
 }
 
 func (alipay *Alipay) asyncVerifyRequest(values url.Values) error {
@@ -224,6 +374,10 @@ func (alipay *Alipay) asyncVerifyRequest(values url.Values) error {
 	// 2） 对剩下参数进行url decode
 	// 3） 字典序排序
 	// 4) sign解码
+	notifyType := notifyTypeLabel(values.Get("notify_type"))
+	_, span := alipay.startSpan(context.Background(), SpanNotify)
+	span.SetAttribute("alipay.notify_type", notifyType)
+
 	base64Sign := values.Get("sign")
 
 	toVerifyValues := url.Values{}
@@ -240,7 +394,20 @@ func (alipay *Alipay) asyncVerifyRequest(values url.Values) error {
 
 	normToVerifyStr := NormValues(toVerifyValues)
 	signBytes, _ := base64.StdEncoding.DecodeString(base64Sign)
-	return Verify(alipay.PublicKey, []byte(normToVerifyStr), signBytes)
+	err := Verify(alipay.PublicKey, []byte(normToVerifyStr), signBytes)
+
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeError
+		span.RecordError(err)
+		alipay.incCounter(MetricSignVerifyFailuresTotal, map[string]string{"source": "notify", "method": notifyType})
+	} else {
+		span.SetAttribute("alipay.notify_id", values.Get("notify_id"))
+	}
+	span.SetAttribute("alipay.outcome", outcome)
+	span.End()
+	alipay.incCounter(MetricNotificationsTotal, map[string]string{"notify_type": notifyType, "outcome": outcome})
+	return err
 }
 
 // NormValues ...
@@ -0,0 +1,150 @@
+package alipay_test
+
+import (
+	"testing"
+
+	"github.com/xiaojiaoyu100/alipay"
+	"github.com/xiaojiaoyu100/alipay/alipaytest"
+)
+
+func newTestClient(t *testing.T) (*alipay.Alipay, *alipaytest.Gateway) {
+	t.Helper()
+	client, gateway, err := alipaytest.NewClient("2021000000000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(gateway.Close)
+	return client, gateway
+}
+
+func TestTradeFlow(t *testing.T) {
+	client, gateway := newTestClient(t)
+
+	_, precreateResp, err := client.Precreate(&alipay.PrecreateParam{
+		OutTradeNo:  "T001",
+		TotalAmount: "10.00",
+		Subject:     "测试商品",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !precreateResp.Success() || precreateResp.OutTradeNo != "T001" {
+		t.Fatalf("precreate: %+v", precreateResp)
+	}
+
+	_, queryResp, err := client.Query(&alipay.QueryParam{OutTradeNo: "T001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queryResp.TradeStatus != alipay.WaitBuyerPay {
+		t.Fatalf("trade_status = %s, want %s", queryResp.TradeStatus, alipay.WaitBuyerPay)
+	}
+
+	if err := gateway.PayTrade("T001"); err != nil {
+		t.Fatal(err)
+	}
+	_, queryResp, err = client.Query(&alipay.QueryParam{OutTradeNo: "T001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queryResp.TradeStatus != alipay.TradeSuccess || queryResp.ReceiptAmount != "10.00" {
+		t.Fatalf("query after pay: %+v", queryResp)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, refundResp, err := client.Refund(&alipay.RefundParam{
+			OutTradeNo:   "T001",
+			RefundAmount: "3.00",
+			OutRequestNo: "R001",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !refundResp.Success() || refundResp.RefundFee != "3.00" {
+			t.Fatalf("refund #%d: %+v", i+1, refundResp)
+		}
+	}
+
+	_, refundResp, err := client.Refund(&alipay.RefundParam{
+		OutTradeNo:   "T001",
+		RefundAmount: "4.00",
+		OutRequestNo: "R001",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refundResp.Success() || refundResp.SubCode != alipaytest.ErrDiscordantRepeatRequest.SubCode {
+		t.Fatalf("repeated out_request_no with another amount: %+v", refundResp)
+	}
+
+	_, refundResp, err = client.Refund(&alipay.RefundParam{
+		OutTradeNo:   "T001",
+		RefundAmount: "8.00",
+		OutRequestNo: "R002",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refundResp.Success() || !refundResp.IsNotEqualTotal() {
+		t.Fatalf("over refund: %+v", refundResp)
+	}
+
+	trade, _ := gateway.Trade("T001")
+	if trade.RefundAmount != 300 {
+		t.Fatalf("refund amount = %d, want 300", trade.RefundAmount)
+	}
+}
+
+func TestTradeCancelAndClose(t *testing.T) {
+	client, gateway := newTestClient(t)
+
+	if _, _, err := client.Precreate(&alipay.PrecreateParam{OutTradeNo: "T001", TotalAmount: "10.00", Subject: "测试商品"}); err != nil {
+		t.Fatal(err)
+	}
+	_, closeResp, err := client.Close(&alipay.CloseParam{OutTradeNo: "T001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closeResp.Success() {
+		t.Fatalf("close: %+v", closeResp)
+	}
+	if err := gateway.PayTrade("T001"); err != alipaytest.ErrTradeHasClose {
+		t.Fatalf("err = %v, want ErrTradeHasClose", err)
+	}
+
+	if _, _, err := client.Precreate(&alipay.PrecreateParam{OutTradeNo: "T002", TotalAmount: "10.00", Subject: "测试商品"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gateway.PayTrade("T002"); err != nil {
+		t.Fatal(err)
+	}
+	_, cancelResp, err := client.Cancel(alipay.CancelParam{OutTradeNo: "T002"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cancelResp.Success() || cancelResp.Action != "refund" {
+		t.Fatalf("cancel: %+v", cancelResp)
+	}
+	if trade, _ := gateway.Trade("T002"); trade.Status != alipay.TradeClosed || trade.RefundAmount != 1000 {
+		t.Fatalf("trade: %+v", trade)
+	}
+
+	_, cancelResp, err = client.Cancel(alipay.CancelParam{OutTradeNo: "T003"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cancelResp.Success() || cancelResp.Action != "close" {
+		t.Fatalf("cancel unknown trade: %+v", cancelResp)
+	}
+}
+
+func TestGatewayRejectsUnknownMethod(t *testing.T) {
+	client, _ := newTestClient(t)
+	_, resp, err := client.DataBillBalanceQuery(&alipay.DataBillBalanceQueryParam{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Success() || resp.SubCode != "isv.invalid-method" {
+		t.Fatalf("resp: %+v", resp)
+	}
+}
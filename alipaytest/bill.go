@@ -0,0 +1,411 @@
+package alipaytest
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+
+	"github.com/xiaojiaoyu100/alipay"
+)
+
+// BillAccount 账单头部默认的账号
+const BillAccount = "20880000000000000156"
+
+// BillPath 本地网关账单下载地址的路径
+const BillPath = "/bill/"
+
+// billHeaderTimeLayout 账单头尾中的时间格式
+const billHeaderTimeLayout = alipay.BillTimeLayout
+
+// BillArchive 按支付宝的格式生成账单压缩包：文件名和内容为GBK编码，#开头的头尾，明细的每个字段后面带一个制表符
+type BillArchive struct {
+	Account          string // 为空时使用BillAccount
+	BillDate         string // 日账单为yyyy-MM-dd，月账单为yyyy-MM
+	ExportTime       time.Time
+	TradeList        []*alipay.BillTradeEntry
+	SigncustomerList []*alipay.BillSigncustomerEntry
+}
+
+// billColumns 按结构体bill标签的第一个名称生成表头，Extras中的列按名称排序追加在后面
+func billColumns(t reflect.Type, extras []string) []string {
+	header := make([]string, 0, t.NumField()+len(extras))
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("bill")
+		if len(tag) == 0 || tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		header = append(header, strings.Split(name, "|")[0])
+	}
+	return append(header, extras...)
+}
+
+func billValues(entry interface{}, extras []string) []string {
+	v := reflect.ValueOf(entry).Elem()
+	t := v.Type()
+	values := make([]string, 0, t.NumField()+len(extras))
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("bill")
+		if len(tag) == 0 || tag == "-" {
+			continue
+		}
+		values = append(values, v.Field(i).String())
+	}
+	extraValues, _ := v.FieldByName("Extras").Interface().(map[string]string)
+	for _, name := range extras {
+		values = append(values, extraValues[name])
+	}
+	return values
+}
+
+// billExtras 所有明细Extras中出现过的列
+func billExtras(entries []interface{}) []string {
+	set := make(map[string]bool)
+	for _, entry := range entries {
+		extraValues, _ := reflect.ValueOf(entry).Elem().FieldByName("Extras").Interface().(map[string]string)
+		for name := range extraValues {
+			set[name] = true
+		}
+	}
+	extras := make([]string, 0, len(set))
+	for name := range set {
+		extras = append(extras, name)
+	}
+	sort.Strings(extras)
+	return extras
+}
+
+// billRow 明细中每个字段后面带制表符，包含逗号或引号的字段按CSV规则加引号
+func billRow(values []string, tabSuffix bool) string {
+	fields := make([]string, 0, len(values))
+	for _, value := range values {
+		if tabSuffix {
+			value += "\t"
+		}
+		if strings.ContainsAny(value, ",\"\r\n") {
+			value = `"` + strings.Replace(value, `"`, `""`, -1) + `"`
+		}
+		fields = append(fields, value)
+	}
+	return strings.Join(fields, ",")
+}
+
+func cent(amount string) int64 {
+	value, _ := alipay.Int64ifyCent(amount)
+	return value
+}
+
+// period 账单的起止时间以及文件名中的日期
+func (archive *BillArchive) period() (time.Time, time.Time, string, error) {
+	if start, err := time.ParseInLocation(alipay.BillDateDayLayout, archive.BillDate, time.Local); err == nil {
+		return start, start.AddDate(0, 0, 1), start.Format("20060102"), nil
+	}
+	if start, err := time.ParseInLocation(alipay.BillDateMonthLayout, archive.BillDate, time.Local); err == nil {
+		return start, start.AddDate(0, 1, 0), start.Format("200601"), nil
+	}
+	return time.Time{}, time.Time{}, "", fmt.Errorf("账单时间格式应为yyyy-MM-dd或yyyy-MM: %s", archive.BillDate)
+}
+
+type billFile struct {
+	name  string
+	lines []string
+}
+
+func (archive *BillArchive) header(title, listName string, start, end time.Time) []string {
+	account := archive.Account
+	if len(account) == 0 {
+		account = BillAccount
+	}
+	return []string{
+		"#支付宝" + title + "查询",
+		"#账号：[" + account + "]",
+		"#起始日期：[" + start.Format(billHeaderTimeLayout) + "]   终止日期：[" + end.Format(billHeaderTimeLayout) + "]",
+		"#-----------------------------------------" + listName + "----------------------------------------",
+	}
+}
+
+func (archive *BillArchive) exportTime() string {
+	exportTime := archive.ExportTime
+	if exportTime.IsZero() {
+		exportTime = time.Now()
+	}
+	return "#导出时间：[" + exportTime.Format(billHeaderTimeLayout) + "]"
+}
+
+func (archive *BillArchive) tradeFiles(prefix string, start, end time.Time) []*billFile {
+	entries := make([]interface{}, 0, len(archive.TradeList))
+	for _, entry := range archive.TradeList {
+		entries = append(entries, entry)
+	}
+	extras := billExtras(entries)
+
+	detail := &billFile{name: prefix + alipay.BillFileKindTrade + ".csv"}
+	detail.lines = archive.header("业务明细", "业务明细列表", start, end)
+	detail.lines = append(detail.lines, billRow(billColumns(reflect.TypeOf(alipay.BillTradeEntry{}), extras), false))
+
+	type shopTotal struct {
+		shopName                                                          string
+		tradeCount, refundCount                                           int
+		total, receipt, alipayOff, sellerOff, handlingCharge, service, fr int64
+	}
+	var (
+		counts   = make(map[string]int)
+		receipts = make(map[string]int64)
+		offs     = make(map[string]int64)
+		shops    = make(map[string]*shopTotal)
+		shopNos  []string
+	)
+	for _, entry := range archive.TradeList {
+		detail.lines = append(detail.lines, billRow(billValues(entry, extras), true))
+		counts[entry.BusinessType]++
+		receipts[entry.BusinessType] += cent(entry.ReceiptAmount)
+		offs[entry.BusinessType] += cent(entry.SellerOff)
+
+		shop, ok := shops[entry.ShopNo]
+		if !ok {
+			shop = &shopTotal{shopName: entry.ShopName}
+			shops[entry.ShopNo] = shop
+			shopNos = append(shopNos, entry.ShopNo)
+		}
+		if entry.BusinessType == alipay.CsvBusinessTypeRefund {
+			shop.refundCount++
+		} else {
+			shop.tradeCount++
+		}
+		shop.total += cent(entry.TotalAmount)
+		shop.receipt += cent(entry.ReceiptAmount)
+		shop.alipayOff += cent(entry.AlipayOff)
+		shop.sellerOff += cent(entry.SellerOff)
+		shop.handlingCharge += cent(entry.HandlingCharge)
+		shop.service += cent(entry.Service)
+		shop.fr += cent(entry.Fr)
+	}
+	trade, refund := alipay.CsvBusinessTypeTrade, alipay.CsvBusinessTypeRefund
+	detail.lines = append(detail.lines,
+		"#-----------------------------------------业务明细列表结束------------------------------------",
+		fmt.Sprintf("#交易合计：%d笔，商家实收共：%s元，商家优惠共：%s元", counts[trade], alipay.StringifyCent(receipts[trade]), alipay.StringifyCent(offs[trade])),
+		fmt.Sprintf("#退款合计：%d笔，商家实收退款共：%s元，商家优惠退款共：%s元", counts[refund], alipay.StringifyCent(receipts[refund]), alipay.StringifyCent(offs[refund])),
+		archive.exportTime(),
+	)
+
+	summary := &billFile{name: prefix + alipay.BillFileKindTradeSummary + ".csv"}
+	summary.lines = archive.header("业务汇总", "业务汇总列表", start, end)
+	summary.lines = append(summary.lines, billRow(billColumns(reflect.TypeOf(alipay.BillTradeSummaryEntry{}), nil), false))
+	all := &shopTotal{}
+	row := func(shopNo string, shop *shopTotal) string {
+		return billRow([]string{
+			shopNo, shop.shopName,
+			fmt.Sprint(shop.tradeCount), fmt.Sprint(shop.refundCount),
+			alipay.StringifyCent(shop.total), alipay.StringifyCent(shop.receipt),
+			alipay.StringifyCent(shop.alipayOff), alipay.StringifyCent(shop.sellerOff),
+			alipay.StringifyCent(shop.handlingCharge), alipay.StringifyCent(shop.service),
+			alipay.StringifyCent(shop.fr), alipay.StringifyCent(shop.receipt + shop.service + shop.fr),
+		}, true)
+	}
+	for _, shopNo := range shopNos {
+		shop := shops[shopNo]
+		summary.lines = append(summary.lines, row(shopNo, shop))
+		all.tradeCount += shop.tradeCount
+		all.refundCount += shop.refundCount
+		all.total += shop.total
+		all.receipt += shop.receipt
+		all.alipayOff += shop.alipayOff
+		all.sellerOff += shop.sellerOff
+		all.handlingCharge += shop.handlingCharge
+		all.service += shop.service
+		all.fr += shop.fr
+	}
+	summary.lines = append(summary.lines,
+		row(alipay.BillSummaryTotalRow, all),
+		"#-----------------------------------------业务汇总列表结束------------------------------------",
+		archive.exportTime(),
+	)
+	return []*billFile{detail, summary}
+}
+
+func (archive *BillArchive) signcustomerFiles(prefix string, start, end time.Time) []*billFile {
+	entries := make([]interface{}, 0, len(archive.SigncustomerList))
+	for _, entry := range archive.SigncustomerList {
+		entries = append(entries, entry)
+	}
+	extras := billExtras(entries)
+
+	detail := &billFile{name: prefix + alipay.BillFileKindSigncustomer + ".csv"}
+	detail.lines = archive.header("账务明细", "账务明细列表", start, end)
+	detail.lines = append(detail.lines, billRow(billColumns(reflect.TypeOf(alipay.BillSigncustomerEntry{}), extras), false))
+
+	type typeTotal struct {
+		incomeCount, expenseCount int
+		income, expense           int64
+	}
+	var (
+		types        = make(map[string]*typeTotal)
+		businessType []string
+		all          = &typeTotal{}
+	)
+	for _, entry := range archive.SigncustomerList {
+		detail.lines = append(detail.lines, billRow(billValues(entry, extras), true))
+		total, ok := types[entry.BusinessType]
+		if !ok {
+			total = &typeTotal{}
+			types[entry.BusinessType] = total
+			businessType = append(businessType, entry.BusinessType)
+		}
+		for _, t := range []*typeTotal{total, all} {
+			if income := cent(entry.IncomeAmount); income != 0 {
+				t.incomeCount++
+				t.income += income
+			} else {
+				t.expenseCount++
+				t.expense += cent(entry.ExpensesAmount)
+			}
+		}
+	}
+	detail.lines = append(detail.lines,
+		"#-----------------------------------------账务明细列表结束------------------------------------",
+		fmt.Sprintf("#支出合计：%d笔，共%s元", all.expenseCount, alipay.StringifyCent(all.expense)),
+		fmt.Sprintf("#收入合计：%d笔，共%s元", all.incomeCount, alipay.StringifyCent(all.income)),
+		archive.exportTime(),
+	)
+
+	summary := &billFile{name: prefix + alipay.BillFileKindSigncustomerSummary + ".csv"}
+	summary.lines = archive.header("账务汇总", "账务汇总列表", start, end)
+	summary.lines = append(summary.lines, billRow(billColumns(reflect.TypeOf(alipay.BillSigncustomerSummaryEntry{}), nil), false))
+	row := func(name string, t *typeTotal) string {
+		return billRow([]string{
+			name,
+			fmt.Sprint(t.incomeCount), alipay.StringifyCent(t.income),
+			fmt.Sprint(t.expenseCount), alipay.StringifyCent(t.expense),
+			alipay.StringifyCent(t.income + t.expense),
+		}, true)
+	}
+	for _, name := range businessType {
+		summary.lines = append(summary.lines, row(name, types[name]))
+	}
+	summary.lines = append(summary.lines,
+		row(alipay.BillSummaryTotalRow, all),
+		"#-----------------------------------------账务汇总列表结束------------------------------------",
+		archive.exportTime(),
+	)
+	return []*billFile{detail, summary}
+}
+
+// Zip 生成billType对应的账单压缩包，支持BillTypeTrade和BillTypeSigncustomer
+func (archive *BillArchive) Zip(billType string) ([]byte, error) {
+	start, end, date, err := archive.period()
+	if err != nil {
+		return nil, err
+	}
+	account := archive.Account
+	if len(account) == 0 {
+		account = BillAccount
+	}
+	prefix := account + "_" + date + "_"
+
+	var files []*billFile
+	switch billType {
+	case alipay.BillTypeTrade:
+		files = archive.tradeFiles(prefix, start, end)
+	case alipay.BillTypeSigncustomer:
+		files = archive.signcustomerFiles(prefix, start, end)
+	default:
+		return nil, fmt.Errorf("不支持的账单类型: %s", billType)
+	}
+
+	encoder := simplifiedchinese.GBK.NewEncoder()
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for _, file := range files {
+		name, err := encoder.String(file.name)
+		if err != nil {
+			return nil, err
+		}
+		content, err := encoder.String(strings.Join(file.lines, "\r\n") + "\r\n")
+		if err != nil {
+			return nil, fmt.Errorf("%s包含无法GBK编码的内容: %w", file.name, err)
+		}
+		writer, err := zipWriter.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: archive.ExportTime,
+			NonUTF8:  true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type billDownload struct {
+	data    []byte
+	expires time.Time
+}
+
+// AddBill 添加可以通过alipay.data.dataservice.bill.downloadurl.query下载的账单，没有添加的账单返回isp.bill_not_exist
+func (gateway *Gateway) AddBill(billType, billDate string, data []byte) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	gateway.bills[billType+"/"+billDate] = data
+}
+
+// ExpireBillURLs 使已经返回的下载地址全部失效，用于测试重新查询下载地址
+func (gateway *Gateway) ExpireBillURLs() {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	gateway.billDownloads = make(map[string]*billDownload)
+}
+
+func (gateway *Gateway) handleBillDownloadurlQuery(req *Request) (Result, error) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	data, ok := gateway.bills[req.Biz("bill_type")+"/"+req.Biz("bill_date")]
+	if !ok {
+		return nil, NewBusinessError("isp.bill_not_exist", "账单不存在")
+	}
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(tokenBytes)
+	gateway.billDownloads[token] = &billDownload{
+		data:    data,
+		expires: gateway.Now().Add(gateway.BillURLTTL),
+	}
+	return Result{
+		"bill_download_url": gateway.Server.URL + BillPath + token,
+	}, nil
+}
+
+// serveBill 下载地址过期或者不存在时返回403
+func (gateway *Gateway) serveBill(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.URL.Path, BillPath)
+	gateway.mu.Lock()
+	download, ok := gateway.billDownloads[token]
+	if ok && gateway.Now().After(download.expires) {
+		delete(gateway.billDownloads, token)
+		ok = false
+	}
+	gateway.mu.Unlock()
+	if !ok {
+		http.Error(w, "AccessDenied: Request has expired", http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Content-Type", "application/oct-stream")
+	w.Write(download.data)
+}
@@ -0,0 +1,142 @@
+package alipaytest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xiaojiaoyu100/alipay"
+)
+
+func testBillArchive() *BillArchive {
+	return &BillArchive{
+		BillDate:   "2020-01-02",
+		ExportTime: time.Date(2020, 1, 3, 9, 0, 0, 0, time.Local),
+		TradeList: []*alipay.BillTradeEntry{
+			{TradeNo: "2020010222001", OutTradeNo: "T001", BusinessType: alipay.CsvBusinessTypeTrade, Subject: "商品,大号", ShopNo: "S1", TotalAmount: "10.00", ReceiptAmount: "10.00", Service: "-0.06"},
+			{TradeNo: "2020010222002", OutTradeNo: "T002", BusinessType: alipay.CsvBusinessTypeTrade, ShopNo: "S2", TotalAmount: "5.00", ReceiptAmount: "4.00", SellerOff: "1.00", Extras: map[string]string{"新增列": "x"}},
+			{TradeNo: "2020010222001", OutTradeNo: "T001", BusinessType: alipay.CsvBusinessTypeRefund, OutRequestNo: "R001", ShopNo: "S1", TotalAmount: "-3.00", ReceiptAmount: "-3.00"},
+		},
+		SigncustomerList: []*alipay.BillSigncustomerEntry{
+			{FundFlowID: "F001", TransactionID: "2020010222001", BusinessType: "在线支付", IncomeAmount: "10.00", ExpensesAmount: "0.00"},
+			{FundFlowID: "F002", TransactionID: "2020010222001", BusinessType: "交易退款", IncomeAmount: "0.00", ExpensesAmount: "-3.00"},
+		},
+	}
+}
+
+func TestBillArchiveTrade(t *testing.T) {
+	client, _ := newTestClient(t)
+	data, err := testBillArchive().Zip(alipay.BillTypeTrade)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta := new(alipay.BillMeta)
+	entryList, err := client.BillTradeList(data, alipay.WithBillStrict(), alipay.WithBillVerifyTotals(), alipay.WithBillMeta(meta))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entryList) != 3 || entryList[0].Subject != "商品,大号" || entryList[1].Extras["新增列"] != "x" || entryList[2].OutRequestNo != "R001" {
+		t.Fatalf("entries: %+v %+v %+v", entryList[0], entryList[1], entryList[2])
+	}
+	if meta.Account != BillAccount || meta.StartDate != "2020年01月02日 00:00:00" || meta.EndDate != "2020年01月03日 00:00:00" {
+		t.Fatalf("meta: %+v", meta)
+	}
+
+	summaryList, err := client.BillTradeSummaryList(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaryList) != 3 || !summaryList[2].IsTotal() {
+		t.Fatalf("summary: %+v", summaryList)
+	}
+	total := summaryList[2]
+	if total.TradeCount != "2" || total.RefundCount != "1" || total.ReceiptAmount != "11.00" || total.NetAmount != "10.94" {
+		t.Fatalf("total: %+v", total)
+	}
+}
+
+func TestBillArchiveSigncustomer(t *testing.T) {
+	client, _ := newTestClient(t)
+	archive := testBillArchive()
+	archive.BillDate = "2020-01"
+	data, err := archive.Zip(alipay.BillTypeSigncustomer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta := new(alipay.BillMeta)
+	entryList, err := client.BillSigncustomerList(data, alipay.WithBillStrict(), alipay.WithBillVerifyTotals(), alipay.WithBillMeta(meta))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entryList) != 2 || meta.EndDate != "2020年02月01日 00:00:00" {
+		t.Fatalf("entries = %d, meta: %+v", len(entryList), meta)
+	}
+	if len(meta.Files) != 1 || meta.Files[0] != BillAccount+"_202001_账务明细.csv" {
+		t.Fatalf("files: %v", meta.Files)
+	}
+
+	if _, err := archive.Zip("merchant_act"); err == nil {
+		t.Fatal("unsupported bill type should fail")
+	}
+	archive.BillDate = "20200102"
+	if _, err := archive.Zip(alipay.BillTypeTrade); err == nil {
+		t.Fatal("invalid bill date should fail")
+	}
+	archive.BillDate = "2020-01-02"
+	archive.TradeList[0].Subject = "😀"
+	if _, err := archive.Zip(alipay.BillTypeTrade); err == nil {
+		t.Fatal("content outside GBK should fail")
+	}
+}
+
+func TestFetchBill(t *testing.T) {
+	client, gateway := newTestClient(t)
+	data, err := testBillArchive().Zip(alipay.BillTypeTrade)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gateway.AddBill(alipay.BillTypeTrade, "2020-01-02", data)
+
+	bill, err := client.FetchBill("2020-01-02", alipay.BillTypeTrade, alipay.WithBillStrict(), alipay.WithBillVerifyTotals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bill.TradeList) != 3 || bill.Meta.Totals[alipay.BillTotalNameRefund].Count != 1 {
+		t.Fatalf("bill: %+v", bill)
+	}
+	if _, err := client.FetchBill("2020-01-03", alipay.BillTypeTrade); !errors.Is(err, alipay.ErrBillNotExist) {
+		t.Fatalf("err = %v, want ErrBillNotExist", err)
+	}
+}
+
+func TestBillURLExpired(t *testing.T) {
+	client, gateway := newTestClient(t)
+	data, err := testBillArchive().Zip(alipay.BillTypeTrade)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gateway.AddBill(alipay.BillTypeTrade, "2020-01-02", data)
+
+	_, resp, err := client.BillDownloadurlQuery(&alipay.BillDownloadURLQueryParam{BillType: alipay.BillTypeTrade, BillDate: "2020-01-02"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.DownloadBill(resp.BillDownloadURL); err != nil {
+		t.Fatal(err)
+	}
+	gateway.ExpireBillURLs()
+	if _, err := client.DownloadBill(resp.BillDownloadURL); !errors.Is(err, alipay.ErrBillDownloadStatus) {
+		t.Fatalf("err = %v, want ErrBillDownloadStatus", err)
+	}
+
+	gateway.BillURLTTL = -time.Second
+	_, resp, err = client.BillDownloadurlQuery(&alipay.BillDownloadURLQueryParam{BillType: alipay.BillTypeTrade, BillDate: "2020-01-02"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.DownloadBill(resp.BillDownloadURL); !errors.Is(err, alipay.ErrBillDownloadStatus) {
+		t.Fatalf("err = %v, want ErrBillDownloadStatus", err)
+	}
+}
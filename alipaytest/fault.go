@@ -0,0 +1,162 @@
+package alipaytest
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/xiaojiaoyu100/alipay"
+)
+
+// FaultKind 注入的故障类型
+const (
+	FaultUnknownError  = "unknown_error"  // 20000 isp.unknow-error
+	FaultInProgress    = "in_progress"    // 10003 业务处理中，trade.pay只创建交易不支付
+	FaultSystemError   = "system_error"   // 40004 ACQ.SYSTEM_ERROR
+	FaultTruncatedJSON = "truncated_json" // 正常处理，响应只返回一半
+	FaultMissingSign   = "missing_sign"   // 正常处理，响应没有sign
+	FaultWrongSign     = "wrong_sign"     // 正常处理，响应的sign不正确
+	FaultSlow          = "slow"           // 等待Delay后正常处理
+)
+
+// 注入故障时返回的错误
+var (
+	ErrInProgress = &BizError{
+		Code: "10003",
+		Msg:  "order success pay inprocess",
+	}
+	ErrSystemError = NewBusinessError("ACQ.SYSTEM_ERROR", "系统错误")
+)
+
+// FaultRule 按接口和商户订单号匹配请求并注入故障，按添加顺序匹配第一条
+type FaultRule struct {
+	Method     string        // 为空时匹配所有接口
+	OutTradeNo string        // 为空时匹配所有订单
+	Kind       string        // FaultKind
+	Times      int           // 生效次数，0表示一直生效
+	Delay      time.Duration // 响应之前等待的时间，对所有类型都生效；客户端在此期间超时也会继续处理
+	Process    bool          // 返回错误码的故障是否仍然执行业务处理，例如退款已经成功但返回系统错误
+
+	hits int
+}
+
+// AddFault 添加故障规则
+func (gateway *Gateway) AddFault(rule *FaultRule) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	gateway.faults = append(gateway.faults, rule)
+}
+
+// ClearFaults 删除所有故障规则
+func (gateway *Gateway) ClearFaults() {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	gateway.faults = nil
+}
+
+// FaultHits 规则已经生效的次数
+func (gateway *Gateway) FaultHits(rule *FaultRule) int {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	return rule.hits
+}
+
+func (gateway *Gateway) matchFault(req *Request) *FaultRule {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	for _, rule := range gateway.faults {
+		if len(rule.Method) > 0 && rule.Method != req.Method {
+			continue
+		}
+		if len(rule.OutTradeNo) > 0 && rule.OutTradeNo != req.Biz("out_trade_no") {
+			continue
+		}
+		if rule.Times > 0 && rule.hits >= rule.Times {
+			continue
+		}
+		rule.hits++
+		return rule
+	}
+	return nil
+}
+
+// inProgressPay trade.pay返回10003时交易已创建、等待用户输入密码
+func (gateway *Gateway) inProgressPay(req *Request) (Result, *BizError) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	trade, err := gateway.createTradeLocked(req)
+	if err != nil {
+		return nil, err.(*BizError)
+	}
+	return Result{
+		"trade_no":     trade.TradeNo,
+		"out_trade_no": trade.OutTradeNo,
+	}, ErrInProgress
+}
+
+// aliveWriter 客户端已经断开（例如超时）时丢弃响应内容
+type aliveWriter struct {
+	http.ResponseWriter
+	ctx context.Context
+}
+
+func (w aliveWriter) Write(p []byte) (int, error) {
+	if w.ctx.Err() != nil {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// serveFault 与支付宝一致，客户端在Delay期间超时断开后仍然完成业务处理，只是不再返回响应，用于测试超时后查询的场景
+func (gateway *Gateway) serveFault(w http.ResponseWriter, r *http.Request, req *Request, handler HandlerFunc, rule *FaultRule) {
+	if rule.Delay > 0 {
+		time.Sleep(rule.Delay)
+	}
+	w = aliveWriter{ResponseWriter: w, ctx: r.Context()}
+
+	key := responseKey(req)
+	switch rule.Kind {
+	case FaultUnknownError, FaultSystemError, FaultInProgress:
+		if rule.Kind == FaultInProgress && req.Method == alipay.MethodAlipayTradePay {
+			result, bizErr := gateway.inProgressPay(req)
+			gateway.writeResponse(w, key, result, bizErr)
+			return
+		}
+		if rule.Process {
+			callHandler(handler, req)
+		}
+		bizErr := map[string]*BizError{
+			FaultUnknownError: ErrUnknown,
+			FaultSystemError:  ErrSystemError,
+			FaultInProgress:   ErrInProgress,
+		}[rule.Kind]
+		gateway.writeResponse(w, key, nil, bizErr)
+	case FaultTruncatedJSON, FaultMissingSign, FaultWrongSign:
+		data, err := MarshalResponse(callHandler(handler, req))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var body []byte
+		switch rule.Kind {
+		case FaultTruncatedJSON:
+			body, err = gateway.SignResponse(key, data)
+			body = body[:len(body)/2]
+		case FaultMissingSign:
+			body = joinResponse(key, data, "")
+		case FaultWrongSign:
+			var sign string
+			sign, err = alipay.RSA2(gateway.AlipayPrivateKey(), string(data)+" ")
+			body = joinResponse(key, data, sign)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html;charset=utf-8")
+		w.Write(body)
+	default:
+		result, bizErr := callHandler(handler, req)
+		gateway.writeResponse(w, key, result, bizErr)
+	}
+}
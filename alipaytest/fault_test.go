@@ -0,0 +1,112 @@
+package alipaytest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/xiaojiaoyu100/alipay"
+)
+
+func TestFaultSlowThenQuery(t *testing.T) {
+	client, gateway := newTestClient(t)
+	client.SetHTTPClient(&http.Client{Timeout: 50 * time.Millisecond})
+	rule := &FaultRule{Method: alipay.MethodAlipayTradePay, Kind: FaultSlow, Delay: 200 * time.Millisecond, Times: 1}
+	gateway.AddFault(rule)
+
+	if _, _, err := client.Pay(testPayParam("T001"), "", ""); err == nil {
+		t.Fatal("pay should time out")
+	}
+
+	// 客户端已经超时，网关仍然完成支付
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if trade, ok := gateway.Trade("T001"); ok && trade.Status == alipay.TradeSuccess {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("slow pay was not processed after the client timed out")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	_, resp, err := client.Query(&alipay.QueryParam{OutTradeNo: "T001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.TradeStatus != alipay.TradeSuccess {
+		t.Fatalf("query: %+v", resp)
+	}
+	if gateway.FaultHits(rule) != 1 {
+		t.Fatalf("hits = %d, want 1", gateway.FaultHits(rule))
+	}
+}
+
+func TestFaultInProgress(t *testing.T) {
+	client, gateway := newTestClient(t)
+	gateway.AddFault(&FaultRule{Method: alipay.MethodAlipayTradePay, Kind: FaultInProgress, Times: 1})
+
+	_, resp, err := client.Pay(testPayParam("T001"), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Code != "10003" {
+		t.Fatalf("pay: %+v", resp)
+	}
+	_, queryResp, err := client.Query(&alipay.QueryParam{OutTradeNo: "T001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queryResp.TradeStatus != alipay.WaitBuyerPay {
+		t.Fatalf("query: %+v", queryResp)
+	}
+}
+
+func TestFaultResponse(t *testing.T) {
+	for _, kind := range []string{FaultTruncatedJSON, FaultWrongSign} {
+		t.Run(kind, func(t *testing.T) {
+			client, gateway := newTestClient(t)
+			gateway.AddFault(&FaultRule{Method: alipay.MethodAlipayTradePay, Kind: kind})
+
+			if _, _, err := client.Pay(testPayParam("T001"), "", ""); err == nil {
+				t.Fatal("pay should fail")
+			}
+			if trade, ok := gateway.Trade("T001"); !ok || trade.Status != alipay.TradeSuccess {
+				t.Fatal("the request should be processed even though the response is broken")
+			}
+		})
+	}
+}
+
+func TestFaultProcess(t *testing.T) {
+	client, gateway := newTestClient(t)
+	if _, _, err := client.Pay(testPayParam("T001"), "", ""); err != nil {
+		t.Fatal(err)
+	}
+	gateway.AddFault(&FaultRule{Method: alipay.MethodAlipayTradeRefund, Kind: FaultSystemError, Times: 2, Process: true})
+	gateway.AddFault(&FaultRule{Method: alipay.MethodAlipayTradeRefund, Kind: FaultUnknownError, OutTradeNo: "T001"})
+
+	refund := &alipay.RefundParam{OutTradeNo: "T001", RefundAmount: "1.00", OutRequestNo: "R001"}
+	_, resp, err := client.Refund(refund)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.SubCode != ErrSystemError.SubCode {
+		t.Fatalf("refund: %+v", resp)
+	}
+	if trade, _ := gateway.Trade("T001"); trade.RefundAmount != 100 {
+		t.Fatalf("refund amount = %d, want 100", trade.RefundAmount)
+	}
+
+	client.Refund(refund)
+	_, resp, err = client.Refund(refund)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Code != "20000" {
+		t.Fatalf("third refund should match the second rule: %+v", resp)
+	}
+	if trade, _ := gateway.Trade("T001"); trade.RefundAmount != 100 {
+		t.Fatalf("refund amount = %d, want 100", trade.RefundAmount)
+	}
+}
@@ -0,0 +1,356 @@
+// Package alipaytest 提供基于httptest的本地支付宝网关，用于在没有网络的环境下测试alipay.Alipay
+package alipaytest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xiaojiaoyu100/alipay"
+)
+
+// GatewayPath 本地网关的路径
+const GatewayPath = "/gateway.do"
+
+// Result 业务响应中除code、msg之外的字段
+type Result map[string]interface{}
+
+// BizError 网关返回的错误响应
+type BizError struct {
+	Code    string
+	Msg     string
+	SubCode string
+	SubMsg  string
+}
+
+func (e *BizError) Error() string {
+	return fmt.Sprintf("%s %s %s %s", e.Code, e.Msg, e.SubCode, e.SubMsg)
+}
+
+// NewBusinessError 业务处理失败，code为40004
+func NewBusinessError(subCode, subMsg string) *BizError {
+	return &BizError{
+		Code:    "40004",
+		Msg:     "Business Failed",
+		SubCode: subCode,
+		SubMsg:  subMsg,
+	}
+}
+
+// NewInvalidArgumentsError 参数错误，code为40002
+func NewInvalidArgumentsError(subCode, subMsg string) *BizError {
+	return &BizError{
+		Code:    "40002",
+		Msg:     "Invalid Arguments",
+		SubCode: subCode,
+		SubMsg:  subMsg,
+	}
+}
+
+// ErrUnknown 支付宝的系统繁忙，code为20000
+var ErrUnknown = &BizError{
+	Code:    "20000",
+	Msg:     "Service Currently Unavailable",
+	SubCode: "isp.unknow-error",
+	SubMsg:  "系统繁忙",
+}
+
+// Request 网关收到的一次请求
+type Request struct {
+	Method     string
+	Values     url.Values             // 所有公共参数，包括sign
+	BizContent map[string]interface{} // 解析后的biz_content
+	Time       time.Time
+}
+
+// Biz 取biz_content中的字符串或数字
+func (req *Request) Biz(key string) string {
+	value, ok := req.BizContent[key]
+	if !ok || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+// HandlerFunc 处理一个接口，返回*BizError时响应对应的错误，返回其他错误时响应ErrUnknown
+type HandlerFunc func(req *Request) (Result, error)
+
+// Gateway 本地支付宝网关，用商户公钥验证请求签名，用生成的支付宝私钥签名响应
+type Gateway struct {
+	AppID  string
+	Server *httptest.Server
+	Now    func() time.Time // 交易时间，默认为time.Now
+
+	BillURLTTL time.Duration // 账单下载地址的有效期，默认30秒
+
+	alipayKey    *KeyPair
+	appPublicKey *rsa.PublicKey
+
+	mu       sync.Mutex
+	handlers map[string]HandlerFunc
+	requests []*Request
+	trades   map[string]*Trade // key为out_trade_no
+	tradeNos map[string]string // trade_no到out_trade_no
+	sequence int
+
+	notifier     *Notifier
+	notifyWait   sync.WaitGroup
+	notifyCtx    context.Context // Close时取消，停止正在重试的通知
+	notifyCancel context.CancelFunc
+
+	faults []*FaultRule
+
+	bills         map[string][]byte // key为bill_type/bill_date
+	billDownloads map[string]*billDownload
+}
+
+// NewGateway 启动本地网关，appPublicKey为商户应用公钥，用完需要Close
+func NewGateway(appID string, appPublicKey *rsa.PublicKey) (*Gateway, error) {
+	alipayKey, err := GenerateKeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("生成支付宝密钥失败: %w", err)
+	}
+	gateway := &Gateway{
+		AppID:         appID,
+		Now:           time.Now,
+		alipayKey:     alipayKey,
+		appPublicKey:  appPublicKey,
+		handlers:      make(map[string]HandlerFunc),
+		trades:        make(map[string]*Trade),
+		tradeNos:      make(map[string]string),
+		BillURLTTL:    30 * time.Second,
+		bills:         make(map[string][]byte),
+		billDownloads: make(map[string]*billDownload),
+	}
+	gateway.notifyCtx, gateway.notifyCancel = context.WithCancel(context.Background())
+	gateway.registerTradeHandlers()
+	gateway.handlers[alipay.MethodAlipayDataDataserviceBillDownloadurlQuery] = gateway.handleBillDownloadurlQuery
+	mux := http.NewServeMux()
+	mux.Handle(GatewayPath, gateway)
+	mux.HandleFunc(BillPath, gateway.serveBill)
+	gateway.Server = httptest.NewServer(mux)
+	return gateway, nil
+}
+
+// NewClient 生成商户密钥并启动本地网关，返回已经指向该网关的客户端
+func NewClient(appID string) (*alipay.Alipay, *Gateway, error) {
+	appKey, err := GenerateKeyPair()
+	if err != nil {
+		return nil, nil, fmt.Errorf("生成商户密钥失败: %w", err)
+	}
+	gateway, err := NewGateway(appID, &appKey.PrivateKey.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := alipay.New(appID, gateway.AlipayPublicKeyPEM(), appKey.PrivateKeyPEM)
+	if err != nil {
+		gateway.Close()
+		return nil, nil, err
+	}
+	client.SetGateway(gateway.URL())
+	return client, gateway, nil
+}
+
+// URL 传给alipay.Alipay.SetGateway的网关地址
+func (gateway *Gateway) URL() string {
+	return gateway.Server.URL + GatewayPath
+}
+
+// Close 取消正在投递的通知并等待投递结束，然后关闭网关
+func (gateway *Gateway) Close() {
+	gateway.mu.Lock()
+	gateway.notifyCancel()
+	gateway.mu.Unlock()
+	gateway.notifyWait.Wait()
+	gateway.Server.Close()
+}
+
+// AlipayPublicKeyPEM 传给alipay.New的支付宝公钥
+func (gateway *Gateway) AlipayPublicKeyPEM() []byte {
+	return gateway.alipayKey.PublicKeyPEM
+}
+
+// AlipayPrivateKey 支付宝私钥，用于签名响应和异步通知
+func (gateway *Gateway) AlipayPrivateKey() *rsa.PrivateKey {
+	return gateway.alipayKey.PrivateKey
+}
+
+// Handle 注册或者替换一个接口的处理函数
+func (gateway *Gateway) Handle(method string, handler HandlerFunc) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	gateway.handlers[method] = handler
+}
+
+// Requests 按顺序返回收到的请求，包括验签失败的请求
+func (gateway *Gateway) Requests() []*Request {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	requests := make([]*Request, len(gateway.requests))
+	copy(requests, gateway.requests)
+	return requests
+}
+
+// verifyRequest 与支付宝一致，除sign外的所有参数排序后验签
+func (gateway *Gateway) verifyRequest(values url.Values) error {
+	sign, err := base64.StdEncoding.DecodeString(values.Get("sign"))
+	if err != nil {
+		return err
+	}
+	toVerifyValues := url.Values{}
+	for key, list := range values {
+		if key == "sign" {
+			continue
+		}
+		toVerifyValues[key] = list
+	}
+	return alipay.Verify(gateway.appPublicKey, []byte(alipay.NormValues(toVerifyValues)), sign)
+}
+
+func (gateway *Gateway) parseRequest(r *http.Request) (*Request, *BizError) {
+	if err := r.ParseForm(); err != nil {
+		return nil, NewInvalidArgumentsError("isv.invalid-parameter", err.Error())
+	}
+	req := &Request{
+		Method: r.Form.Get("method"),
+		Values: r.Form,
+		Time:   gateway.Now(),
+	}
+	gateway.mu.Lock()
+	gateway.requests = append(gateway.requests, req)
+	gateway.mu.Unlock()
+
+	if len(req.Values.Get("sign")) == 0 {
+		return req, NewInvalidArgumentsError("isv.missing-signature", "缺少签名参数")
+	}
+	if err := gateway.verifyRequest(req.Values); err != nil {
+		return req, NewInvalidArgumentsError("isv.invalid-signature", "验签出错")
+	}
+	if req.Values.Get("app_id") != gateway.AppID {
+		return req, NewInvalidArgumentsError("isv.invalid-app-id", "无效的AppID参数")
+	}
+	if bizContent := req.Values.Get("biz_content"); len(bizContent) > 0 {
+		decoder := json.NewDecoder(strings.NewReader(bizContent))
+		decoder.UseNumber()
+		if err := decoder.Decode(&req.BizContent); err != nil {
+			return req, NewInvalidArgumentsError("isv.invalid-parameter", "biz_content不是合法的JSON")
+		}
+	}
+	return req, nil
+}
+
+// ServeHTTP ...
+func (gateway *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req, bizErr := gateway.parseRequest(r)
+	if bizErr != nil {
+		gateway.writeResponse(w, responseKey(req), nil, bizErr)
+		return
+	}
+
+	gateway.mu.Lock()
+	handler, ok := gateway.handlers[req.Method]
+	gateway.mu.Unlock()
+	if !ok {
+		gateway.writeResponse(w, "error_response", nil, NewInvalidArgumentsError("isv.invalid-method", "不存在的方法名"))
+		return
+	}
+
+	if rule := gateway.matchFault(req); rule != nil {
+		gateway.serveFault(w, r, req, handler, rule)
+		return
+	}
+
+	result, bizErr := callHandler(handler, req)
+	gateway.writeResponse(w, responseKey(req), result, bizErr)
+}
+
+// callHandler 非*BizError的错误转换成ErrUnknown
+func callHandler(handler HandlerFunc, req *Request) (Result, *BizError) {
+	result, err := handler(req)
+	if err == nil {
+		return result, nil
+	}
+	if bizErr, ok := err.(*BizError); ok {
+		return nil, bizErr
+	}
+	return nil, ErrUnknown
+}
+
+// responseKey 例如alipay.trade.query对应alipay_trade_query_response
+func responseKey(req *Request) string {
+	if req == nil || len(req.Method) == 0 {
+		return "error_response"
+	}
+	return strings.Replace(req.Method, ".", "_", -1) + "_response"
+}
+
+// MarshalResponse 生成响应中key对应的JSON，签名针对这段JSON的原始字节，bizErr不为nil时result中的字段也会输出
+func MarshalResponse(result Result, bizErr *BizError) ([]byte, error) {
+	object := make(map[string]interface{}, len(result)+4)
+	for key, value := range result {
+		object[key] = value
+	}
+	if bizErr != nil {
+		object["code"] = bizErr.Code
+		object["msg"] = bizErr.Msg
+		object["sub_code"] = bizErr.SubCode
+		object["sub_msg"] = bizErr.SubMsg
+	} else {
+		object["code"] = alipay.GatewaySuccess
+		object["msg"] = "Success"
+	}
+	return json.Marshal(object)
+}
+
+// SignResponse 拼接成{"xxx_response":data,"sign":"..."}
+func (gateway *Gateway) SignResponse(key string, data []byte) ([]byte, error) {
+	sign, err := alipay.RSA2(gateway.AlipayPrivateKey(), string(data))
+	if err != nil {
+		return nil, err
+	}
+	return joinResponse(key, data, sign), nil
+}
+
+// joinResponse sign为空时不输出sign字段
+func joinResponse(key string, data []byte, sign string) []byte {
+	quotedKey, _ := json.Marshal(key)
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	buf.Write(quotedKey)
+	buf.WriteByte(':')
+	buf.Write(data)
+	if len(sign) > 0 {
+		quotedSign, _ := json.Marshal(sign)
+		buf.WriteString(`,"sign":`)
+		buf.Write(quotedSign)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+func (gateway *Gateway) writeResponse(w http.ResponseWriter, key string, result Result, bizErr *BizError) {
+	data, err := MarshalResponse(result, bizErr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	body, err := gateway.SignResponse(key, data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html;charset=utf-8")
+	w.Write(body)
+}
@@ -0,0 +1,41 @@
+package alipaytest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+)
+
+// KeyBits 生成的RSA密钥长度，与支付宝RSA2一致
+const KeyBits = 2048
+
+// KeyPair PEM格式与alipay.New的参数一致：私钥为PKCS1，公钥为PKIX
+type KeyPair struct {
+	PrivateKey    *rsa.PrivateKey
+	PrivateKeyPEM []byte
+	PublicKeyPEM  []byte
+}
+
+// GenerateKeyPair ...
+func GenerateKeyPair() (*KeyPair, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, KeyBits)
+	if err != nil {
+		return nil, err
+	}
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{
+		PrivateKey: privateKey,
+		PrivateKeyPEM: pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+		}),
+		PublicKeyPEM: pem.EncodeToMemory(&pem.Block{
+			Type:  "PUBLIC KEY",
+			Bytes: publicKeyBytes,
+		}),
+	}, nil
+}
@@ -0,0 +1,243 @@
+package alipaytest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xiaojiaoyu100/alipay"
+)
+
+// NotifyTypeTradeStatusSync 交易状态变化的异步通知
+const NotifyTypeTradeStatusSync = "trade_status_sync"
+
+// RetrySchedule 支付宝异步通知的重试间隔，首次通知失败后按该间隔重试，共通知8次
+var RetrySchedule = []time.Duration{
+	4 * time.Minute,
+	10 * time.Minute,
+	10 * time.Minute,
+	time.Hour,
+	2 * time.Hour,
+	6 * time.Hour,
+	15 * time.Hour,
+}
+
+// CompressSchedule 按比例缩短重试间隔，例如factor为60000时4分钟变成4毫秒
+func CompressSchedule(schedule []time.Duration, factor int64) []time.Duration {
+	compressed := make([]time.Duration, 0, len(schedule))
+	for _, delay := range schedule {
+		compressed = append(compressed, delay/time.Duration(factor))
+	}
+	return compressed
+}
+
+// SignNotification 与支付宝一致，去掉sign、sign_type后排序签名，并设置sign_type为RSA2
+func (gateway *Gateway) SignNotification(values url.Values) error {
+	toSignValues := url.Values{}
+	for key, list := range values {
+		if key == "sign" || key == "sign_type" {
+			continue
+		}
+		toSignValues[key] = list
+	}
+	sign, err := alipay.RSA2(gateway.AlipayPrivateKey(), alipay.NormValues(toSignValues))
+	if err != nil {
+		return err
+	}
+	values.Set("sign_type", "RSA2")
+	values.Set("sign", sign)
+	return nil
+}
+
+// TradeNotification 按交易的当前状态生成签名后的trade_status_sync通知，可以直接传给ParseAsyncResponse
+func (gateway *Gateway) TradeNotification(trade *Trade) (url.Values, error) {
+	values := url.Values{}
+	values.Set("notify_time", formatTime(gateway.Now()))
+	values.Set("notify_type", NotifyTypeTradeStatusSync)
+	values.Set("notify_id", fmt.Sprintf("%x", rand.Int63()))
+	values.Set("app_id", gateway.AppID)
+	values.Set("charset", "utf-8")
+	values.Set("version", "1.0")
+	values.Set("trade_no", trade.TradeNo)
+	values.Set("out_trade_no", trade.OutTradeNo)
+	values.Set("trade_status", trade.Status)
+	values.Set("total_amount", alipay.StringifyCent(trade.TotalAmount))
+	values.Set("subject", trade.Subject)
+	values.Set("gmt_create", formatTime(trade.GmtCreate))
+	if !trade.GmtPayment.IsZero() {
+		amount := alipay.StringifyCent(trade.TotalAmount)
+		values.Set("buyer_id", trade.BuyerID)
+		values.Set("buyer_logon_id", trade.BuyerLogonID)
+		values.Set("receipt_amount", amount)
+		values.Set("invoice_amount", amount)
+		values.Set("buyer_pay_amount", amount)
+		values.Set("point_amount", "0.00")
+		values.Set("gmt_payment", formatTime(trade.GmtPayment))
+	}
+	if len(trade.Refunds) > 0 {
+		refund := trade.Refunds[len(trade.Refunds)-1]
+		values.Set("out_biz_no", refund.OutRequestNo)
+		values.Set("refund_fee", alipay.StringifyCent(trade.RefundAmount))
+		values.Set("gmt_refund", refund.GmtRefund.Format("2006-01-02 15:04:05.000"))
+	}
+	if !trade.GmtClose.IsZero() {
+		values.Set("gmt_close", formatTime(trade.GmtClose))
+	}
+	if err := gateway.SignNotification(values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// DeliveryAttempt 一次通知
+type DeliveryAttempt struct {
+	Time       time.Time
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+// Delivery 一个通知的所有投递记录
+type Delivery struct {
+	NotifyURL string
+	Values    url.Values
+	Attempts  []*DeliveryAttempt
+	Success   bool // 商户返回了success
+}
+
+// Notifier 模拟支付宝投递异步通知：POST表单，商户返回success之前按Schedule重试
+type Notifier struct {
+	Client     *http.Client
+	Schedule   []time.Duration // 默认为压缩后的RetrySchedule
+	Duplicates int             // 成功之后再重复投递的次数，用于测试幂等
+	Rand       *rand.Rand      // 不为nil时DeliverAll打乱投递顺序
+
+	mu         sync.Mutex
+	deliveries []*Delivery
+}
+
+// NewNotifier 重试间隔压缩为原来的六万分之一，4分钟变成4毫秒
+func NewNotifier() *Notifier {
+	return &Notifier{
+		Client:   http.DefaultClient,
+		Schedule: CompressSchedule(RetrySchedule, 60000),
+	}
+}
+
+// Deliveries 按完成顺序返回投递记录
+func (notifier *Notifier) Deliveries() []*Delivery {
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	deliveries := make([]*Delivery, len(notifier.deliveries))
+	copy(deliveries, notifier.deliveries)
+	return deliveries
+}
+
+func (notifier *Notifier) post(ctx context.Context, notifyURL string, values url.Values) *DeliveryAttempt {
+	attempt := &DeliveryAttempt{Time: time.Now()}
+	request, err := http.NewRequest(http.MethodPost, notifyURL, strings.NewReader(values.Encode()))
+	if err != nil {
+		attempt.Err = err
+		return attempt
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	response, err := notifier.Client.Do(request)
+	if err != nil {
+		attempt.Err = err
+		return attempt
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, 1024))
+	attempt.StatusCode = response.StatusCode
+	attempt.Body = string(body)
+	attempt.Err = err
+	return attempt
+}
+
+// Deliver 投递一个通知，返回success视为成功，否则按Schedule重试直到用完或者ctx结束
+func (notifier *Notifier) Deliver(ctx context.Context, notifyURL string, values url.Values) *Delivery {
+	delivery := &Delivery{
+		NotifyURL: notifyURL,
+		Values:    values,
+	}
+	defer func() {
+		notifier.mu.Lock()
+		notifier.deliveries = append(notifier.deliveries, delivery)
+		notifier.mu.Unlock()
+	}()
+
+	for idx := 0; ; idx++ {
+		attempt := notifier.post(ctx, notifyURL, values)
+		delivery.Attempts = append(delivery.Attempts, attempt)
+		if attempt.Err == nil && attempt.StatusCode == http.StatusOK && strings.TrimSpace(attempt.Body) == "success" {
+			delivery.Success = true
+			break
+		}
+		if idx >= len(notifier.Schedule) {
+			return delivery
+		}
+		select {
+		case <-ctx.Done():
+			return delivery
+		case <-time.After(notifier.Schedule[idx]):
+		}
+	}
+
+	for idx := 0; idx < notifier.Duplicates; idx++ {
+		delivery.Attempts = append(delivery.Attempts, notifier.post(ctx, notifyURL, values))
+	}
+	return delivery
+}
+
+// DeliverAll 依次投递多个通知，Rand不为nil时打乱顺序，用于测试乱序到达
+func (notifier *Notifier) DeliverAll(ctx context.Context, notifyURL string, valuesList []url.Values) []*Delivery {
+	ordered := make([]url.Values, len(valuesList))
+	copy(ordered, valuesList)
+	if notifier.Rand != nil {
+		notifier.Rand.Shuffle(len(ordered), func(i, j int) {
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		})
+	}
+	deliveries := make([]*Delivery, 0, len(ordered))
+	for _, values := range ordered {
+		deliveries = append(deliveries, notifier.Deliver(ctx, notifyURL, values))
+	}
+	return deliveries
+}
+
+// SetNotifier 设置后，交易支付、退款、关闭时会向下单时的notify_url异步投递通知
+func (gateway *Gateway) SetNotifier(notifier *Notifier) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	gateway.notifier = notifier
+}
+
+// WaitNotifications 等待所有自动投递的通知完成
+func (gateway *Gateway) WaitNotifications() {
+	gateway.notifyWait.Wait()
+}
+
+// notifyLocked 交易状态变化后调用，没有设置Notifier或者notify_url时以及网关关闭后忽略
+func (gateway *Gateway) notifyLocked(trade *Trade) {
+	if gateway.notifier == nil || len(trade.NotifyURL) == 0 || gateway.notifyCtx.Err() != nil {
+		return
+	}
+	values, err := gateway.TradeNotification(trade)
+	if err != nil {
+		return
+	}
+	notifier, notifyURL, ctx := gateway.notifier, trade.NotifyURL, gateway.notifyCtx
+	gateway.notifyWait.Add(1)
+	go func() {
+		defer gateway.notifyWait.Done()
+		notifier.Deliver(ctx, notifyURL, values)
+	}()
+}
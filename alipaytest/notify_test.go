@@ -0,0 +1,194 @@
+package alipaytest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xiaojiaoyu100/alipay"
+)
+
+func newTestClient(t *testing.T) (*alipay.Alipay, *Gateway) {
+	t.Helper()
+	client, gateway, err := NewClient("2021000000000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(gateway.Close)
+	return client, gateway
+}
+
+func testPayParam(outTradeNo string) *alipay.PayParam {
+	return &alipay.PayParam{
+		OutTradeNo:  outTradeNo,
+		Scene:       "bar_code",
+		AuthCode:    "281234567890123456",
+		Subject:     "测试商品",
+		TotalAmount: "10.00",
+	}
+}
+
+// testMerchant 记录收到的通知，前failures次返回fail
+type testMerchant struct {
+	mu       sync.Mutex
+	failures int
+	received []url.Values
+}
+
+func (merchant *testMerchant) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	merchant.mu.Lock()
+	defer merchant.mu.Unlock()
+	merchant.received = append(merchant.received, r.PostForm)
+	if len(merchant.received) <= merchant.failures {
+		w.Write([]byte("fail"))
+		return
+	}
+	w.Write([]byte("success"))
+}
+
+func (merchant *testMerchant) values() []url.Values {
+	merchant.mu.Lock()
+	defer merchant.mu.Unlock()
+	return append([]url.Values(nil), merchant.received...)
+}
+
+func TestTradeNotification(t *testing.T) {
+	client, gateway := newTestClient(t)
+	if _, _, err := client.Pay(testPayParam("T001"), "", ""); err != nil {
+		t.Fatal(err)
+	}
+	trade, _ := gateway.Trade("T001")
+	values, err := gateway.TradeNotification(trade)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.ParseAsyncResponse(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.OutTradeNo != "T001" || resp.TradeStatus != alipay.TradeSuccess || resp.TotalAmount != "10.00" || resp.BuyerID != BuyerID {
+		t.Fatalf("async response: %+v", resp)
+	}
+
+	values.Set("total_amount", "0.01")
+	if _, err := client.ParseAsyncResponse(values); err != alipay.ErrAsyncVerify {
+		t.Fatalf("tampered err = %v, want ErrAsyncVerify", err)
+	}
+}
+
+func TestNotifierRetry(t *testing.T) {
+	merchant := &testMerchant{failures: 2}
+	server := httptest.NewServer(merchant)
+	defer server.Close()
+
+	notifier := NewNotifier()
+	notifier.Duplicates = 1
+	delivery := notifier.Deliver(context.Background(), server.URL, url.Values{"out_trade_no": {"T001"}})
+	if !delivery.Success || len(delivery.Attempts) != 4 {
+		t.Fatalf("delivery: success = %v, attempts = %d", delivery.Success, len(delivery.Attempts))
+	}
+	if len(merchant.values()) != 4 || len(notifier.Deliveries()) != 1 {
+		t.Fatalf("received = %d, deliveries = %d", len(merchant.values()), len(notifier.Deliveries()))
+	}
+
+	merchant = &testMerchant{failures: len(RetrySchedule) + 1}
+	server2 := httptest.NewServer(merchant)
+	defer server2.Close()
+	notifier.Schedule = CompressSchedule(RetrySchedule, 6000000)
+	delivery = notifier.Deliver(context.Background(), server2.URL, url.Values{"out_trade_no": {"T002"}})
+	if delivery.Success || len(delivery.Attempts) != len(RetrySchedule)+1 {
+		t.Fatalf("delivery: success = %v, attempts = %d", delivery.Success, len(delivery.Attempts))
+	}
+}
+
+func TestNotifierCanceled(t *testing.T) {
+	merchant := &testMerchant{failures: 100}
+	server := httptest.NewServer(merchant)
+	defer server.Close()
+
+	notifier := NewNotifier()
+	notifier.Schedule = []time.Duration{time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	delivery := notifier.Deliver(ctx, server.URL, url.Values{"out_trade_no": {"T001"}})
+	if delivery.Success || len(delivery.Attempts) != 1 || time.Since(start) > time.Second {
+		t.Fatalf("delivery: success = %v, attempts = %d, elapsed = %s", delivery.Success, len(delivery.Attempts), time.Since(start))
+	}
+}
+
+func TestGatewayNotifier(t *testing.T) {
+	merchant := new(testMerchant)
+	server := httptest.NewServer(merchant)
+	defer server.Close()
+
+	client, gateway := newTestClient(t)
+	gateway.SetNotifier(NewNotifier())
+	if _, _, err := client.Pay(testPayParam("T001"), server.URL, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.Refund(&alipay.RefundParam{OutTradeNo: "T001", RefundAmount: "3.00", OutRequestNo: "R001"}); err != nil {
+		t.Fatal(err)
+	}
+	gateway.WaitNotifications()
+
+	received := merchant.values()
+	if len(received) != 2 {
+		t.Fatalf("received %d notifications, want 2", len(received))
+	}
+	refundFees := map[string]bool{}
+	for _, values := range received {
+		resp, err := client.ParseAsyncResponse(values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		refundFees[resp.RefundFee] = true
+	}
+	if !refundFees[""] || !refundFees["3.00"] {
+		t.Fatalf("refund fees: %v", refundFees)
+	}
+}
+
+func TestGatewayCloseStopsNotifications(t *testing.T) {
+	merchant := &testMerchant{failures: 100}
+	server := httptest.NewServer(merchant)
+	defer server.Close()
+
+	client, gateway, err := NewClient("2021000000000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier := NewNotifier()
+	notifier.Schedule = []time.Duration{time.Hour}
+	gateway.SetNotifier(notifier)
+	if _, _, err := client.Pay(testPayParam("T001"), server.URL, ""); err != nil {
+		t.Fatal(err)
+	}
+	for len(merchant.values()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		gateway.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Close should cancel the pending retry")
+	}
+	deliveries := notifier.Deliveries()
+	if len(deliveries) != 1 || deliveries[0].Success || len(deliveries[0].Attempts) != 1 {
+		t.Fatalf("Close should wait for the delivery to finish, deliveries: %+v", deliveries)
+	}
+}
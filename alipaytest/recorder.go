@@ -0,0 +1,382 @@
+package alipaytest
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/xiaojiaoyu100/alipay"
+)
+
+// Redacted 脱敏后的字段值
+const Redacted = "[REDACTED]"
+
+// RedactFields 默认脱敏的字段，包括公共参数、biz_content以及响应中的同名字段
+var RedactFields = []string{
+	"app_auth_token", "app_refresh_token", "auth_token", "auth_code",
+	"access_token", "refresh_token",
+	"buyer_id", "buyer_user_id", "buyer_logon_id", "buyer_open_id",
+	"user_id", "open_id", "alipay_user_id", "payer_user_id",
+	"cert_no", "cert_name", "mobile", "phone_num", "email", "nick_name", "avatar",
+	"payee_info", "identity", "name", "payer_logon_id", "other_account",
+}
+
+// ErrUnredactedBody 录制到了无法脱敏的非网关响应，需要设置Recorder.BodyRedactor
+var ErrUnredactedBody = errors.New("非网关响应无法脱敏")
+
+// ErrNoInteraction 回放时没有匹配的录制记录，或者匹配的记录已经用完
+var ErrNoInteraction = errors.New("没有匹配的录制记录")
+
+// RecordMode ...
+type RecordMode int
+
+const (
+	// ModeReplay 只从录制文件回放，不发出真实请求
+	ModeReplay RecordMode = iota
+	// ModeRecord 发出真实请求并录制，Save之后写入文件
+	ModeRecord
+)
+
+// Interaction 一次录制的请求和响应，网关请求按method和biz_content匹配，其他请求（例如账单下载）按HTTP方法和去掉query的地址匹配
+type Interaction struct {
+	Key         string            `json:"key"`                   // 网关请求为接口名称，其他请求为"GET https://..."
+	BizContent  json.RawMessage   `json:"biz_content,omitempty"` // 脱敏后按key排序的biz_content
+	Params      map[string]string `json:"params,omitempty"`      // 脱敏后的公共参数，不包括sign、timestamp、biz_content，仅供查看
+	StatusCode  int               `json:"status_code"`
+	ContentType string            `json:"content_type,omitempty"`
+	ResponseKey string            `json:"response_key,omitempty"` // 例如alipay_trade_query_response
+	Response    json.RawMessage   `json:"response,omitempty"`     // 脱敏后的响应内容，不包括sign
+	Body        []byte            `json:"body,omitempty"`         // 不是网关JSON响应时的原始内容
+}
+
+// Cassette 录制文件的内容
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Recorder 录制和回放支付宝请求的http.RoundTripper，通过alipay.Alipay.SetHTTPClient(recorder.Client())使用。
+// timestamp和sign每次请求都会变化，因此不参与匹配；相同条件的多次请求按录制顺序依次回放
+type Recorder struct {
+	Path         string
+	Mode         RecordMode
+	Transport    http.RoundTripper // 录制时实际发出请求，默认为http.DefaultTransport
+	RedactFields []string          // 默认为RedactFields
+	SignKey      *rsa.PrivateKey   // 回放时用于签名响应，客户端需要使用对应的公钥；为nil时响应不带sign
+
+	// BodyRedactor 脱敏非网关响应（例如账单下载）的内容。为nil时账单压缩包按BillRedactColumns脱敏，
+	// 状态码不是200的其他响应不保存内容，状态码为200的其他响应返回ErrUnredactedBody
+	BodyRedactor func(req *http.Request, statusCode int, body []byte) ([]byte, error)
+
+	mu       sync.Mutex
+	cassette *Cassette
+	cursor   map[string]int
+}
+
+// NewRecorder 录制模式，transport为nil时使用http.DefaultTransport
+func NewRecorder(path string, transport http.RoundTripper) *Recorder {
+	return &Recorder{
+		Path:      path,
+		Mode:      ModeRecord,
+		Transport: transport,
+		cassette:  &Cassette{},
+		cursor:    make(map[string]int),
+	}
+}
+
+// NewReplayer 回放模式，读取path中的录制记录
+func NewReplayer(path string) (*Recorder, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取录制文件失败: %w", err)
+	}
+	cassette := &Cassette{}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("解析录制文件失败: %w", err)
+	}
+	// 写入文件时MarshalIndent会缩进json.RawMessage，匹配前恢复成紧凑格式
+	for _, interaction := range cassette.Interactions {
+		interaction.BizContent = compactJSON(interaction.BizContent)
+		interaction.Response = compactJSON(interaction.Response)
+	}
+	return &Recorder{
+		Path:     path,
+		Mode:     ModeReplay,
+		cassette: cassette,
+		cursor:   make(map[string]int),
+	}, nil
+}
+
+// Client 使用该Recorder的http.Client
+func (recorder *Recorder) Client() *http.Client {
+	return &http.Client{Transport: recorder}
+}
+
+// Interactions 已经录制或者读取的记录
+func (recorder *Recorder) Interactions() []*Interaction {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	interactions := make([]*Interaction, len(recorder.cassette.Interactions))
+	copy(interactions, recorder.cassette.Interactions)
+	return interactions
+}
+
+// Save 把录制的记录写入Path
+func (recorder *Recorder) Save() error {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	data, err := json.MarshalIndent(recorder.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(recorder.Path, append(data, '\n'), 0644)
+}
+
+// RoundTrip ...
+func (recorder *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	match, err := recorder.matchRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if recorder.Mode == ModeRecord {
+		return recorder.record(req, match)
+	}
+	return recorder.replay(req, match)
+}
+
+func (recorder *Recorder) redactFields() []string {
+	if recorder.RedactFields == nil {
+		return RedactFields
+	}
+	return recorder.RedactFields
+}
+
+func (recorder *Recorder) isRedacted(key string) bool {
+	for _, field := range recorder.redactFields() {
+		if field == key {
+			return true
+		}
+	}
+	return false
+}
+
+// redact 递归替换需要脱敏的字段，bill_download_url去掉带有令牌的query
+func (recorder *Recorder) redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if recorder.isRedacted(key) {
+				v[key] = Redacted
+				continue
+			}
+			if s, ok := item.(string); ok && key == "bill_download_url" {
+				v[key] = stripQuery(s)
+				continue
+			}
+			v[key] = recorder.redact(item)
+		}
+	case []interface{}:
+		for idx, item := range v {
+			v[idx] = recorder.redact(item)
+		}
+	}
+	return value
+}
+
+// redactJSON 解析、脱敏之后重新序列化，map按key排序，因此结果与字段顺序无关
+func (recorder *Recorder) redactJSON(data []byte) (json.RawMessage, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(recorder.redact(value))
+}
+
+func compactJSON(data json.RawMessage) json.RawMessage {
+	if len(data) == 0 {
+		return data
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return data
+	}
+	return buf.Bytes()
+}
+
+func stripQuery(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.RawQuery = ""
+	return u.String()
+}
+
+// matchRequest 生成用于匹配的Interaction，只包含请求部分
+func (recorder *Recorder) matchRequest(req *http.Request) (*Interaction, error) {
+	values := req.URL.Query()
+	method := values.Get("method")
+	if len(method) == 0 {
+		return &Interaction{Key: req.Method + " " + stripQuery(req.URL.String())}, nil
+	}
+	match := &Interaction{
+		Key:    method,
+		Params: make(map[string]string),
+	}
+	for key := range values {
+		switch key {
+		case "sign", "timestamp", "biz_content":
+			continue
+		}
+		if recorder.isRedacted(key) {
+			match.Params[key] = Redacted
+		} else {
+			match.Params[key] = values.Get(key)
+		}
+	}
+	if bizContent := values.Get("biz_content"); len(bizContent) > 0 {
+		normalized, err := recorder.redactJSON([]byte(bizContent))
+		if err != nil {
+			return nil, fmt.Errorf("biz_content不是合法的JSON: %w", err)
+		}
+		match.BizContent = normalized
+	}
+	return match, nil
+}
+
+func (match *Interaction) matchKey() string {
+	return match.Key + "\n" + string(match.BizContent)
+}
+
+func (recorder *Recorder) record(req *http.Request, match *Interaction) (*http.Response, error) {
+	transport := recorder.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	response, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	// 调用方拿到的是未经修改的响应，签名仍然可以验证
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	match.StatusCode = response.StatusCode
+	match.ContentType = response.Header.Get("Content-Type")
+	if err := recorder.recordBody(req, match, body); err != nil {
+		return nil, err
+	}
+	recorder.mu.Lock()
+	recorder.cassette.Interactions = append(recorder.cassette.Interactions, match)
+	recorder.mu.Unlock()
+	return response, nil
+}
+
+// recordBody 网关响应只保存脱敏后的*_response，不是JSON的网关响应（例如错误页面）原样保存，其他响应由BodyRedactor脱敏
+func (recorder *Recorder) recordBody(req *http.Request, match *Interaction, body []byte) error {
+	if match.Params == nil {
+		redactor := recorder.BodyRedactor
+		if redactor == nil {
+			redactor = redactBody
+		}
+		redacted, err := redactor(req, match.StatusCode, body)
+		if err != nil {
+			return fmt.Errorf("录制%s失败: %w", match.Key, err)
+		}
+		match.Body = redacted
+		return nil
+	}
+	obj := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(body, &obj); err != nil {
+		match.Body = body
+		return nil
+	}
+	for key, value := range obj {
+		if !strings.HasSuffix(key, "_response") {
+			continue
+		}
+		redacted, err := recorder.redactJSON(value)
+		if err != nil {
+			return fmt.Errorf("脱敏%s失败: %w", key, err)
+		}
+		match.ResponseKey = key
+		match.Response = redacted
+		return nil
+	}
+	match.Body = body
+	return nil
+}
+
+// redactBody 默认的BodyRedactor
+func redactBody(req *http.Request, statusCode int, body []byte) ([]byte, error) {
+	if bytes.HasPrefix(body, []byte("PK\x03\x04")) {
+		return RedactBillArchive(body, BillRedactColumns)
+	}
+	if statusCode != http.StatusOK || len(body) == 0 {
+		return nil, nil
+	}
+	return nil, ErrUnredactedBody
+}
+
+func (recorder *Recorder) replay(req *http.Request, match *Interaction) (*http.Response, error) {
+	recorder.mu.Lock()
+	key := match.matchKey()
+	skip := recorder.cursor[key]
+	var found *Interaction
+	for _, interaction := range recorder.cassette.Interactions {
+		if interaction.matchKey() != key {
+			continue
+		}
+		if skip == 0 {
+			found = interaction
+			break
+		}
+		skip--
+	}
+	if found != nil {
+		recorder.cursor[key]++
+	}
+	recorder.mu.Unlock()
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, match.Key, match.BizContent)
+	}
+
+	body := found.Body
+	if len(found.ResponseKey) > 0 {
+		var sign string
+		if recorder.SignKey != nil {
+			var err error
+			if sign, err = alipay.RSA2(recorder.SignKey, string(found.Response)); err != nil {
+				return nil, err
+			}
+		}
+		body = joinResponse(found.ResponseKey, found.Response, sign)
+	}
+	header := make(http.Header)
+	if len(found.ContentType) > 0 {
+		header.Set("Content-Type", found.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", found.StatusCode, http.StatusText(found.StatusCode)),
+		StatusCode:    found.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
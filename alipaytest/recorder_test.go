@@ -0,0 +1,165 @@
+package alipaytest
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xiaojiaoyu100/alipay"
+)
+
+func tempPath(t *testing.T, name string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "alipaytest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, name)
+}
+
+func TestRecorderReplay(t *testing.T) {
+	client, gateway := newTestClient(t)
+	path := tempPath(t, "cassette.json")
+	recorder := NewRecorder(path, nil)
+	client.SetHTTPClient(recorder.Client())
+
+	_, payResp, err := client.Pay(testPayParam("T001"), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payResp.BuyerUserID != BuyerID {
+		t.Fatalf("recording should return the real response, got %+v", payResp)
+	}
+	for i := 0; i < 2; i++ {
+		if _, _, err := client.Query(&alipay.QueryParam{OutTradeNo: "T001"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(recorder.Interactions()) != 3 {
+		t.Fatalf("interactions = %d, want 3", len(recorder.Interactions()))
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"281234567890123456", BuyerID, BuyerLogonID} {
+		if strings.Contains(string(saved), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+
+	replayer, err := NewReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayer.SignKey = gateway.AlipayPrivateKey()
+	gateway.Close()
+	client.SetHTTPClient(replayer.Client())
+
+	_, payResp, err = client.Pay(testPayParam("T001"), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !payResp.Success() || len(payResp.TradeNo) == 0 || payResp.BuyerUserID != Redacted {
+		t.Fatalf("replayed pay: %+v", payResp)
+	}
+	for i := 0; i < 2; i++ {
+		_, queryResp, err := client.Query(&alipay.QueryParam{OutTradeNo: "T001"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if queryResp.TradeStatus != alipay.TradeSuccess {
+			t.Fatalf("replayed query: %+v", queryResp)
+		}
+	}
+	if _, _, err := client.Query(&alipay.QueryParam{OutTradeNo: "T001"}); !errors.Is(err, ErrNoInteraction) {
+		t.Fatalf("err = %v, want ErrNoInteraction", err)
+	}
+	if _, _, err := client.Pay(testPayParam("T002"), "", ""); !errors.Is(err, ErrNoInteraction) {
+		t.Fatalf("err = %v, want ErrNoInteraction", err)
+	}
+}
+
+func TestRecorderRedactBill(t *testing.T) {
+	client, gateway := newTestClient(t)
+	archive := &BillArchive{
+		BillDate: "2020-01-02",
+		TradeList: []*alipay.BillTradeEntry{
+			{TradeNo: "2020010222001", OutTradeNo: "T001", BusinessType: alipay.CsvBusinessTypeTrade, BuyerEmail: "buyer@example.com", Operator: "张三", Subject: "商品", TotalAmount: "10.00", ReceiptAmount: "10.00"},
+		},
+	}
+	data, err := archive.Zip(alipay.BillTypeTrade)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gateway.AddBill(alipay.BillTypeTrade, "2020-01-02", data)
+
+	path := tempPath(t, "cassette.json")
+	recorder := NewRecorder(path, nil)
+	client.SetHTTPClient(recorder.Client())
+	bill, err := client.FetchBill("2020-01-02", alipay.BillTypeTrade)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bill.TradeList[0].BuyerEmail != "buyer@example.com" {
+		t.Fatalf("recording should return the real bill, got %+v", bill.TradeList[0])
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := NewReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayer.SignKey = gateway.AlipayPrivateKey()
+	gateway.Close()
+	client.SetHTTPClient(replayer.Client())
+
+	bill, err = client.FetchBill("2020-01-02", alipay.BillTypeTrade, alipay.WithBillStrict(), alipay.WithBillVerifyTotals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := bill.TradeList[0]
+	if entry.BuyerEmail != Redacted || entry.Operator != Redacted || entry.OutTradeNo != "T001" || entry.TotalAmount != "10.00" {
+		t.Fatalf("replayed bill entry: %+v", entry)
+	}
+}
+
+func TestRecorderUnredactedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("buyer@example.com"))
+	}))
+	defer server.Close()
+
+	recorder := NewRecorder(tempPath(t, "cassette.json"), nil)
+	if _, err := recorder.Client().Get(server.URL + "/bill"); !errors.Is(err, ErrUnredactedBody) {
+		t.Fatalf("err = %v, want ErrUnredactedBody", err)
+	}
+	if len(recorder.Interactions()) != 0 {
+		t.Fatal("unredacted body should not be recorded")
+	}
+
+	recorder.BodyRedactor = func(req *http.Request, statusCode int, body []byte) ([]byte, error) {
+		return []byte(Redacted), nil
+	}
+	response, err := recorder.Client().Get(server.URL + "/bill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	interactions := recorder.Interactions()
+	if len(interactions) != 1 || string(interactions[0].Body) != Redacted {
+		t.Fatalf("interactions: %+v", interactions)
+	}
+}
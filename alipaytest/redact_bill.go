@@ -0,0 +1,101 @@
+package alipaytest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io/ioutil"
+	"strings"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// BillRedactColumns 录制账单时默认脱敏的列
+var BillRedactColumns = []string{"对方账户", "对方名称", "买家账号", "买家支付宝账号", "操作员"}
+
+// RedactBillArchive 把账单压缩包中各个CSV文件的columns列替换成Redacted，文件名、#开头的头尾以及其他列保持不变
+func RedactBillArchive(data []byte, columns []string) ([]byte, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for _, file := range zipReader.File {
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(strings.ToLower(file.Name), ".csv") {
+			if content, err = redactBillCSV(content, columns); err != nil {
+				return nil, err
+			}
+		}
+		writer, err := zipWriter.CreateHeader(&zip.FileHeader{
+			Name:     file.Name,
+			Method:   zip.Deflate,
+			Modified: file.Modified,
+			NonUTF8:  file.NonUTF8,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// redactBillCSV 第一行不以#开头的内容为表头
+func redactBillCSV(content []byte, columns []string) ([]byte, error) {
+	text, err := simplifiedchinese.GBK.NewDecoder().Bytes(content)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(text), "\n")
+	var redactIndexes []int
+	header := true
+	for idx, line := range lines {
+		trimmed := strings.TrimRight(line, "\r")
+		if len(strings.TrimSpace(trimmed)) == 0 || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		reader := csv.NewReader(strings.NewReader(trimmed))
+		reader.LazyQuotes = true
+		reader.FieldsPerRecord = -1
+		fields, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		if header {
+			header = false
+			for fieldIdx, field := range fields {
+				for _, column := range columns {
+					if strings.TrimSpace(field) == column {
+						redactIndexes = append(redactIndexes, fieldIdx)
+					}
+				}
+			}
+			if len(redactIndexes) == 0 {
+				return content, nil
+			}
+			continue
+		}
+		for _, fieldIdx := range redactIndexes {
+			if fieldIdx < len(fields) && len(strings.TrimSpace(fields[fieldIdx])) > 0 {
+				fields[fieldIdx] = Redacted + "\t"
+			}
+		}
+		lines[idx] = billRow(fields, false) + strings.TrimPrefix(line, trimmed)
+	}
+	return simplifiedchinese.GBK.NewEncoder().Bytes([]byte(strings.Join(lines, "\n")))
+}
@@ -0,0 +1,400 @@
+package alipaytest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xiaojiaoyu100/alipay"
+)
+
+// 本地网关中默认的买家
+const (
+	BuyerID      = "2088000000000001"
+	BuyerLogonID = "buy***@example.com"
+)
+
+// TimeLayout 响应中时间的格式
+const TimeLayout = "2006-01-02 15:04:05"
+
+// Refund 一次退款，同一个out_request_no重复请求不会重复退款
+type Refund struct {
+	OutRequestNo string
+	Amount       int64 // 单位分
+	GmtRefund    time.Time
+}
+
+// Trade 本地网关中的交易，金额单位为分
+type Trade struct {
+	TradeNo      string
+	OutTradeNo   string
+	Subject      string
+	TotalAmount  int64
+	RefundAmount int64 // 累计退款金额
+	Status       string
+	BuyerID      string
+	BuyerLogonID string
+	NotifyURL    string
+	GmtCreate    time.Time
+	GmtPayment   time.Time
+	GmtClose     time.Time
+	Refunds      []*Refund
+}
+
+func (trade *Trade) clone() *Trade {
+	copied := *trade
+	copied.Refunds = make([]*Refund, 0, len(trade.Refunds))
+	for _, refund := range trade.Refunds {
+		copiedRefund := *refund
+		copied.Refunds = append(copied.Refunds, &copiedRefund)
+	}
+	return &copied
+}
+
+func (trade *Trade) refund(outRequestNo string) *Refund {
+	for _, refund := range trade.Refunds {
+		if refund.OutRequestNo == outRequestNo {
+			return refund
+		}
+	}
+	return nil
+}
+
+// 常用的业务错误
+var (
+	ErrTradeNotExist           = NewBusinessError("ACQ.TRADE_NOT_EXIST", "交易不存在")
+	ErrTradeHasSuccess         = NewBusinessError("ACQ.TRADE_HAS_SUCCESS", "交易已被支付")
+	ErrTradeHasClose           = NewBusinessError("ACQ.TRADE_HAS_CLOSE", "交易已经关闭")
+	ErrTradeStatusError        = NewBusinessError("ACQ.TRADE_STATUS_ERROR", "交易状态不合法")
+	ErrRefundAmountNotEnough   = NewBusinessError("ACQ.REFUND_AMT_NOT_EQUAL_TOTAL", "退款金额超限")
+	ErrDiscordantRepeatRequest = NewBusinessError("ACQ.DISCORDANT_REPEAT_REQUEST", "请求信息不一致")
+)
+
+func newInvalidParameterError(format string, a ...interface{}) *BizError {
+	return NewBusinessError("ACQ.INVALID_PARAMETER", fmt.Sprintf(format, a...))
+}
+
+func (gateway *Gateway) registerTradeHandlers() {
+	gateway.handlers[alipay.MethodAlipayTradeCreate] = gateway.handleCreate
+	gateway.handlers[alipay.MethodAlipayTradePrecreate] = gateway.handlePrecreate
+	gateway.handlers[alipay.MethodAlipayTradePay] = gateway.handlePay
+	gateway.handlers[alipay.MethodAlipayTradeQuery] = gateway.handleQuery
+	gateway.handlers[alipay.MethodAlipayTradeRefund] = gateway.handleRefund
+	gateway.handlers[alipay.MethodAlipayTradeCancel] = gateway.handleCancel
+	gateway.handlers[alipay.MethodAlipayTradeClose] = gateway.handleClose
+}
+
+// Trade 按商户订单号返回交易的副本
+func (gateway *Gateway) Trade(outTradeNo string) (*Trade, bool) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	trade, ok := gateway.trades[outTradeNo]
+	if !ok {
+		return nil, false
+	}
+	return trade.clone(), true
+}
+
+// PayTrade 模拟买家支付一笔等待付款的交易，例如扫描了预下单的二维码
+func (gateway *Gateway) PayTrade(outTradeNo string) error {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	trade, ok := gateway.trades[outTradeNo]
+	if !ok {
+		return ErrTradeNotExist
+	}
+	return gateway.payLocked(trade)
+}
+
+func (gateway *Gateway) payLocked(trade *Trade) error {
+	switch trade.Status {
+	case alipay.WaitBuyerPay:
+	case alipay.TradeClosed:
+		return ErrTradeHasClose
+	default:
+		return ErrTradeHasSuccess
+	}
+	trade.Status = alipay.TradeSuccess
+	trade.BuyerID = BuyerID
+	trade.BuyerLogonID = BuyerLogonID
+	trade.GmtPayment = gateway.Now()
+	gateway.notifyLocked(trade)
+	return nil
+}
+
+func (gateway *Gateway) closeLocked(trade *Trade) {
+	trade.Status = alipay.TradeClosed
+	trade.GmtClose = gateway.Now()
+}
+
+// findTradeLocked 优先按trade_no查找
+func (gateway *Gateway) findTradeLocked(req *Request) (*Trade, error) {
+	outTradeNo := req.Biz("out_trade_no")
+	if tradeNo := req.Biz("trade_no"); len(tradeNo) > 0 {
+		outTradeNo = gateway.tradeNos[tradeNo]
+	}
+	if len(outTradeNo) == 0 && len(req.Biz("trade_no")) == 0 {
+		return nil, newInvalidParameterError("out_trade_no和trade_no不能同时为空")
+	}
+	trade, ok := gateway.trades[outTradeNo]
+	if !ok {
+		return nil, ErrTradeNotExist
+	}
+	return trade, nil
+}
+
+// createTradeLocked out_trade_no已存在且等待付款时返回原交易
+func (gateway *Gateway) createTradeLocked(req *Request) (*Trade, error) {
+	outTradeNo := req.Biz("out_trade_no")
+	if len(outTradeNo) == 0 {
+		return nil, newInvalidParameterError("out_trade_no不能为空")
+	}
+	totalAmount, err := alipay.Int64ifyCent(req.Biz("total_amount"))
+	if err != nil || totalAmount <= 0 {
+		return nil, newInvalidParameterError("total_amount不正确: %s", req.Biz("total_amount"))
+	}
+
+	if trade, ok := gateway.trades[outTradeNo]; ok {
+		switch trade.Status {
+		case alipay.WaitBuyerPay:
+			return trade, nil
+		case alipay.TradeClosed:
+			return nil, ErrTradeHasClose
+		default:
+			return nil, ErrTradeHasSuccess
+		}
+	}
+
+	gateway.sequence++
+	now := gateway.Now()
+	trade := &Trade{
+		TradeNo:     fmt.Sprintf("%s22%010d", now.Format("20060102"), gateway.sequence),
+		OutTradeNo:  outTradeNo,
+		Subject:     req.Biz("subject"),
+		TotalAmount: totalAmount,
+		Status:      alipay.WaitBuyerPay,
+		NotifyURL:   req.Values.Get("notify_url"),
+		GmtCreate:   now,
+	}
+	gateway.trades[trade.OutTradeNo] = trade
+	gateway.tradeNos[trade.TradeNo] = trade.OutTradeNo
+	return trade, nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(TimeLayout)
+}
+
+func (gateway *Gateway) handleCreate(req *Request) (Result, error) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	trade, err := gateway.createTradeLocked(req)
+	if err != nil {
+		return nil, err
+	}
+	return Result{
+		"out_trade_no": trade.OutTradeNo,
+		"trade_no":     trade.TradeNo,
+	}, nil
+}
+
+func (gateway *Gateway) handlePrecreate(req *Request) (Result, error) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	trade, err := gateway.createTradeLocked(req)
+	if err != nil {
+		return nil, err
+	}
+	return Result{
+		"out_trade_no": trade.OutTradeNo,
+		"qr_code":      "https://qr.alipay.com/" + trade.TradeNo,
+	}, nil
+}
+
+// handlePay 条码支付直接支付成功
+func (gateway *Gateway) handlePay(req *Request) (Result, error) {
+	if len(req.Biz("auth_code")) == 0 && len(req.Biz("auth_no")) == 0 && req.BizContent["agreement_params"] == nil {
+		return nil, newInvalidParameterError("auth_code不能为空")
+	}
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	trade, err := gateway.createTradeLocked(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := gateway.payLocked(trade); err != nil {
+		return nil, err
+	}
+	amount := alipay.StringifyCent(trade.TotalAmount)
+	return Result{
+		"trade_no":         trade.TradeNo,
+		"out_trade_no":     trade.OutTradeNo,
+		"buyer_logon_id":   trade.BuyerLogonID,
+		"buyer_user_id":    trade.BuyerID,
+		"total_amount":     amount,
+		"receipt_amount":   amount,
+		"buyer_pay_amount": amount,
+		"invoice_amount":   amount,
+		"point_amount":     "0.00",
+		"gmt_payment":      formatTime(trade.GmtPayment),
+	}, nil
+}
+
+func (gateway *Gateway) handleQuery(req *Request) (Result, error) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	trade, err := gateway.findTradeLocked(req)
+	if err != nil {
+		return nil, err
+	}
+	result := Result{
+		"trade_no":     trade.TradeNo,
+		"out_trade_no": trade.OutTradeNo,
+		"trade_status": trade.Status,
+		"total_amount": alipay.StringifyCent(trade.TotalAmount),
+	}
+	if !trade.GmtPayment.IsZero() {
+		amount := alipay.StringifyCent(trade.TotalAmount)
+		result["buyer_logon_id"] = trade.BuyerLogonID
+		result["buyer_user_id"] = trade.BuyerID
+		result["receipt_amount"] = amount
+		result["buyer_pay_amount"] = amount
+		result["invoice_amount"] = amount
+		result["point_amount"] = "0.00"
+		result["send_pay_date"] = formatTime(trade.GmtPayment)
+	}
+	return result, nil
+}
+
+// handleRefund out_request_no为空时等于out_trade_no，同一个out_request_no只退一次
+func (gateway *Gateway) handleRefund(req *Request) (Result, error) {
+	refundAmount, err := alipay.Int64ifyCent(req.Biz("refund_amount"))
+	if err != nil || refundAmount <= 0 {
+		return nil, newInvalidParameterError("refund_amount不正确: %s", req.Biz("refund_amount"))
+	}
+
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	trade, err := gateway.findTradeLocked(req)
+	if err != nil {
+		return nil, err
+	}
+	outRequestNo := req.Biz("out_request_no")
+	if len(outRequestNo) == 0 {
+		outRequestNo = trade.OutTradeNo
+	}
+
+	fundChange := "N"
+	refund := trade.refund(outRequestNo)
+	if refund != nil && refund.Amount != refundAmount {
+		// 与支付宝一致，同一个out_request_no重复请求时退款金额必须相同
+		return nil, ErrDiscordantRepeatRequest
+	}
+	if refund == nil {
+		if trade.Status != alipay.TradeSuccess {
+			return nil, ErrTradeStatusError
+		}
+		if trade.RefundAmount+refundAmount > trade.TotalAmount {
+			return nil, ErrRefundAmountNotEnough
+		}
+		refund = &Refund{
+			OutRequestNo: outRequestNo,
+			Amount:       refundAmount,
+			GmtRefund:    gateway.Now(),
+		}
+		trade.Refunds = append(trade.Refunds, refund)
+		trade.RefundAmount += refundAmount
+		if trade.RefundAmount == trade.TotalAmount {
+			gateway.closeLocked(trade)
+		}
+		gateway.notifyLocked(trade)
+		fundChange = "Y"
+	}
+
+	return Result{
+		"trade_no":       trade.TradeNo,
+		"out_trade_no":   trade.OutTradeNo,
+		"buyer_logon_id": trade.BuyerLogonID,
+		"buyer_user_id":  trade.BuyerID,
+		"fund_change":    fundChange,
+		"refund_fee":     alipay.StringifyCent(trade.RefundAmount),
+		"gmt_refund_pay": formatTime(refund.GmtRefund),
+	}, nil
+}
+
+// handleCancel 未支付的交易关闭，已支付的交易全额退款，交易不存在时记录一笔关闭的交易防止之后支付
+func (gateway *Gateway) handleCancel(req *Request) (Result, error) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	trade, err := gateway.findTradeLocked(req)
+	if err == ErrTradeNotExist && len(req.Biz("out_trade_no")) > 0 {
+		gateway.sequence++
+		now := gateway.Now()
+		trade = &Trade{
+			TradeNo:    fmt.Sprintf("%s22%010d", now.Format("20060102"), gateway.sequence),
+			OutTradeNo: req.Biz("out_trade_no"),
+			GmtCreate:  now,
+		}
+		gateway.trades[trade.OutTradeNo] = trade
+		gateway.tradeNos[trade.TradeNo] = trade.OutTradeNo
+		gateway.closeLocked(trade)
+		return Result{
+			"trade_no":     trade.TradeNo,
+			"out_trade_no": trade.OutTradeNo,
+			"retry_flag":   "N",
+			"action":       "close",
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	action := ""
+	switch trade.Status {
+	case alipay.WaitBuyerPay:
+		gateway.closeLocked(trade)
+		gateway.notifyLocked(trade)
+		action = "close"
+	case alipay.TradeSuccess:
+		if remain := trade.TotalAmount - trade.RefundAmount; remain > 0 {
+			trade.Refunds = append(trade.Refunds, &Refund{
+				OutRequestNo: trade.OutTradeNo,
+				Amount:       remain,
+				GmtRefund:    gateway.Now(),
+			})
+			trade.RefundAmount = trade.TotalAmount
+		}
+		gateway.closeLocked(trade)
+		gateway.notifyLocked(trade)
+		action = "refund"
+	case alipay.TradeFinished:
+		return nil, ErrTradeStatusError
+	}
+	return Result{
+		"trade_no":     trade.TradeNo,
+		"out_trade_no": trade.OutTradeNo,
+		"retry_flag":   "N",
+		"action":       action,
+	}, nil
+}
+
+// handleClose 只有等待付款的交易可以关闭
+func (gateway *Gateway) handleClose(req *Request) (Result, error) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	trade, err := gateway.findTradeLocked(req)
+	if err != nil {
+		return nil, err
+	}
+	if trade.Status != alipay.WaitBuyerPay {
+		return nil, ErrTradeStatusError
+	}
+	gateway.closeLocked(trade)
+	gateway.notifyLocked(trade)
+	return Result{
+		"trade_no":     trade.TradeNo,
+		"out_trade_no": trade.OutTradeNo,
+	}, nil
+}
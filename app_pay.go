@@ -46,7 +46,7 @@ type AppPayParam struct {
 }
 
 // AppPay ...
-func (alipay *Alipay) AppPay(param *AppPayParam, notifyURL string) (string, error) {
+func (alipay *Alipay) AppPay(param *AppPayParam, notifyURL string, fillList ...Fill) (string, error) {
 	param.ProductCode = "QUICK_MSECURITY_PAY"
 
 	if len(param.OutTradeNo) == 0 {
@@ -68,12 +68,34 @@ func (alipay *Alipay) AppPay(param *AppPayParam, notifyURL string) (string, erro
 	paramStr, err := alipay.MakeParam(
 		param,
 		MethodAlipayTradeAppPay,
-		WithNotifyURL(notifyURL),
+		append([]Fill{WithNotifyURL(notifyURL)}, fillList...)...,
 	)
 	if err != nil {
 		return "", fmt.Errorf("支付宝app支付构造参数失败: %w", err)
 	}
-	url, err := url.Parse(AlipayGateway)
+	url, err := url.Parse(alipay.Gateway())
+	if err != nil {
+		return "", fmt.Errorf("解析支付宝网关失败: %w", err)
+	}
+	url.RawQuery = paramStr
+	return url.String(), nil
+}
+
+// AppMergePay 合并支付，preOrderNo为MergePrecreate返回的预下单号，各子订单的支付结果分别异步通知
+func (alipay *Alipay) AppMergePay(preOrderNo string, fillList ...Fill) (string, error) {
+	if len(preOrderNo) == 0 {
+		return "", errors.New("合并支付预下单号不能为空")
+	}
+
+	paramStr, err := alipay.MakeParam(
+		&MergePayParam{PreOrderNo: preOrderNo},
+		MethodAlipayTradeAppMergePay,
+		fillList...,
+	)
+	if err != nil {
+		return "", fmt.Errorf("支付宝app合并支付构造参数失败: %w", err)
+	}
+	url, err := url.Parse(alipay.Gateway())
 	if err != nil {
 		return "", fmt.Errorf("解析支付宝网关失败: %w", err)
 	}
@@ -50,6 +50,7 @@ func (alipay *Alipay) ParseAsyncResponse(values url.Values) (*AsyncResponse, err
 	asyncResponse.FundBillList = values.Get("fund_bill_list")
 	asyncResponse.PassbackParams = values.Get("passback_params")
 	asyncResponse.VoucherDetailList = values.Get("voucher_detail_list")
+	asyncResponse.AuthTradePayMode = values.Get("auth_trade_pay_mode")
 
 	return asyncResponse, nil
 }
@@ -88,4 +89,5 @@ type AsyncResponse struct {
 	FundBillList      string
 	PassbackParams    string
 	VoucherDetailList string
+	AuthTradePayMode  string // 预授权转支付时返回CREDIT_PREAUTH_PAY
 }
@@ -0,0 +1,167 @@
+package alipay
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrBillHeaderMismatch 账单表头缺少必需的列，通常是账单格式发生了变化
+var (
+	ErrBillHeaderMismatch = errors.New("账单表头缺少必需的列")
+)
+
+// billColumnField 结构体中带有bill标签的字段，标签格式为`bill:"列名|别名,required"`
+type billColumnField struct {
+	index    int
+	names    []string
+	required bool
+}
+
+var billColumnFieldCache sync.Map
+
+// billColumnFields 解析结构体的bill标签，结果会被缓存
+func billColumnFields(t reflect.Type) []*billColumnField {
+	if cached, ok := billColumnFieldCache.Load(t); ok {
+		return cached.([]*billColumnField)
+	}
+
+	fields := make([]*billColumnField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("bill")
+		if len(tag) == 0 || tag == "-" {
+			continue
+		}
+		field := &billColumnField{index: i}
+		parts := strings.Split(tag, ",")
+		for _, name := range strings.Split(parts[0], "|") {
+			field.names = append(field.names, normBillHeader(name))
+		}
+		for _, option := range parts[1:] {
+			if option == "required" {
+				field.required = true
+			}
+		}
+		fields = append(fields, field)
+	}
+
+	billColumnFieldCache.Store(t, fields)
+	return fields
+}
+
+// normBillHeader 去掉空白，全角括号转成半角，使不同版本账单的表头可以互相匹配
+func normBillHeader(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\u3000', '\ufeff':
+			return -1
+		case '（':
+			return '('
+		case '）':
+			return ')'
+		}
+		return r
+	}, name)
+	return name
+}
+
+// billColumnMapping 表头中每一列对应的结构体字段，-1表示放到Extras中
+type billColumnMapping struct {
+	header     []string
+	fieldIndex []int
+	extrasIdx  int
+}
+
+// newBillColumnMapping 根据表头建立映射，缺少required列时返回ErrBillHeaderMismatch
+func newBillColumnMapping(t reflect.Type, header []string) (*billColumnMapping, error) {
+	mapping := &billColumnMapping{
+		header:     header,
+		fieldIndex: make([]int, len(header)),
+		extrasIdx:  -1,
+	}
+
+	if extras, ok := t.FieldByName("Extras"); ok {
+		mapping.extrasIdx = extras.Index[0]
+	}
+
+	columnIndex := make(map[string]int, len(header))
+	for idx, name := range header {
+		mapping.fieldIndex[idx] = -1
+		name = normBillHeader(name)
+		if _, ok := columnIndex[name]; !ok {
+			columnIndex[name] = idx
+		}
+	}
+
+	missing := make([]string, 0)
+	for _, field := range billColumnFields(t) {
+		found := false
+		for _, name := range field.names {
+			if idx, ok := columnIndex[name]; ok && mapping.fieldIndex[idx] == -1 {
+				mapping.fieldIndex[idx] = field.index
+				found = true
+				break
+			}
+		}
+		if !found && field.required {
+			missing = append(missing, field.names[0])
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrBillHeaderMismatch, strings.Join(missing, "、"))
+	}
+
+	return mapping, nil
+}
+
+// fill 把一行数据填到entry中，entry为结构体指针，列数与表头不一致时返回*BillParseError
+func (mapping *billColumnMapping) fill(entry interface{}, record []string) error {
+	if len(record) != len(mapping.header) {
+		return &BillParseError{Err: fmt.Errorf("%w: 表头%d列，实际%d列", ErrBillFieldCount, len(mapping.header), len(record))}
+	}
+
+	value := reflect.ValueOf(entry).Elem()
+	for idx, field := range record {
+		fieldIndex := mapping.fieldIndex[idx]
+		if fieldIndex >= 0 {
+			value.Field(fieldIndex).SetString(field)
+			continue
+		}
+		if mapping.extrasIdx < 0 {
+			continue
+		}
+		extras := value.Field(mapping.extrasIdx)
+		if extras.IsNil() {
+			extras.Set(reflect.ValueOf(make(map[string]string)))
+		}
+		extras.SetMapIndex(reflect.ValueOf(mapping.header[idx]), reflect.ValueOf(field))
+	}
+	return nil
+}
+
+// billColumnMapper 每个文件的表头只解析一次，月账单等多个文件时每个文件都有自己的表头
+type billColumnMapper struct {
+	t       reflect.Type
+	opts    *billOptions
+	file    int
+	mapping *billColumnMapping
+}
+
+func newBillColumnMapper(entry interface{}, opts *billOptions) *billColumnMapper {
+	return &billColumnMapper{t: reflect.TypeOf(entry).Elem(), opts: opts}
+}
+
+func (mapper *billColumnMapper) fill(header, record []string, entry interface{}) error {
+	if mapper.mapping == nil || mapper.file != mapper.opts.file {
+		mapping, err := newBillColumnMapping(mapper.t, header)
+		if err != nil {
+			return err
+		}
+		mapper.mapping = mapping
+		mapper.file = mapper.opts.file
+	}
+	return mapper.mapping.fill(entry, record)
+}
@@ -0,0 +1,86 @@
+package alipay
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBillColumnMapping(t *testing.T) {
+	header := []string{"商户订单号", "支付宝交易号", "新增列", "业务类型", " 订单金额(元)", "商家实收（元）"}
+	mapping, err := newBillColumnMapping(reflect.TypeOf(BillTradeEntry{}), header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := new(BillTradeEntry)
+	if err := mapping.fill(entry, []string{"O001", "T001", "x", "交易", "10.00", "9.00"}); err != nil {
+		t.Fatal(err)
+	}
+	if entry.TradeNo != "T001" || entry.OutTradeNo != "O001" || entry.TotalAmount != "10.00" || entry.ReceiptAmount != "9.00" {
+		t.Fatalf("entry: %+v", entry)
+	}
+	if len(entry.Extras) != 1 || entry.Extras["新增列"] != "x" {
+		t.Fatalf("extras: %v", entry.Extras)
+	}
+
+	var parseErr *BillParseError
+	if err := mapping.fill(new(BillTradeEntry), []string{"O001", "T001"}); !errors.As(err, &parseErr) || !errors.Is(err, ErrBillFieldCount) {
+		t.Fatalf("err = %v, want ErrBillFieldCount", err)
+	}
+
+	if _, err := newBillColumnMapping(reflect.TypeOf(BillTradeEntry{}), header[1:]); !errors.Is(err, ErrBillHeaderMismatch) {
+		t.Fatalf("err = %v, want ErrBillHeaderMismatch", err)
+	}
+}
+
+// testBillTradeReorderedLines 调整了列顺序并且多了一列的业务明细
+func testBillTradeReorderedLines() []string {
+	lines := testBillTradeLines()
+	for idx, line := range lines {
+		if line == testBillTradeHeader {
+			lines[idx] = "商户订单号,支付宝交易号,业务类型,订单金额（元）,商家实收（元）,商家优惠（元）,新增列"
+			lines[idx+1] = testBillRow("O001", "T001", "交易", "10.00", "10.00", "0.00", "x")
+			lines[idx+2] = testBillRow("O002", "T002", "交易", "5.00", "5.00", "0.00", "y")
+			lines[idx+3] = testBillRow("O001", "T001", "退款", "-5.00", "-5.00", "0.00", "z")
+			break
+		}
+	}
+	return lines
+}
+
+func TestWalkBillTradeReorderedColumns(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	bill := testBillZip(t, testBillFile{name: "20880000000000000156_20200102_业务明细.csv", lines: testBillTradeReorderedLines()})
+	entryList, err := alipay.BillTradeList(bill, WithBillVerifyTotals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entryList) != 3 || entryList[1].TradeNo != "T002" || entryList[1].OutTradeNo != "O002" || entryList[2].Extras["新增列"] != "z" {
+		t.Fatalf("entries: %+v", entryList)
+	}
+}
+
+func TestWalkBillTradeMonthlyHeaders(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	bill := testBillZip(t,
+		testBillFile{name: "20880000000000000156_202001_业务明细_1.csv", lines: testBillTradeLines()},
+		testBillFile{name: "20880000000000000156_202001_业务明细_2.csv", lines: testBillTradeReorderedLines()},
+		testBillFile{name: "20880000000000000156_202001_业务明细_3.csv", lines: testBillTradeLines()},
+	)
+	entryList, err := alipay.BillTradeList(bill)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entryList) != 9 {
+		t.Fatalf("entries = %d, want 9", len(entryList))
+	}
+	for idx, entry := range entryList {
+		_, extra := entry.Extras["新增列"]
+		if extra != (idx >= 3 && idx < 6) || len(entry.TradeNo) == 0 || len(entry.OutTradeNo) == 0 {
+			t.Fatalf("entry #%d of file %d used a stale header: %+v", idx+1, idx/3+1, entry)
+		}
+	}
+	if entryList[4].TradeNo != "T002" || entryList[4].OutTradeNo != "O002" {
+		t.Fatalf("entry #5: %+v", entryList[4])
+	}
+}
@@ -2,13 +2,13 @@
 package alipay
 
 import (
-	"archive/zip"
 	"bytes"
-	"encoding/csv"
+	"context"
 	"encoding/json"
-	"io"
+	"fmt"
 	"io/ioutil"
-	"strings"
+	"net/http"
+	"time"
 )
 
 const (
@@ -17,8 +17,42 @@ const (
 
 	// BillTypeSigncustomer 指基于商户支付宝余额收入及支出等资金变动的帐务账单
 	BillTypeSigncustomer = "signcustomer"
+
+	// BillTypeMerchantAct 营销活动账单，包含营销活动的发放、核销记录
+	BillTypeMerchantAct = "merchant_act"
+
+	// BillTypeTradeZftMerchant 直付通二级商户查询交易的业务账单
+	BillTypeTradeZftMerchant = "trade_zft_merchant"
+
+	// BillTypeZftAcc 直付通平台商查询二级商户流水使用，返回所有二级商户流水
+	BillTypeZftAcc = "zft_acc"
+
+	// BillTypeSettlementMerge 每日结算到卡的资金对应的明细，下载内容包含批次结算到卡明细文件和批次结算到卡汇总文件
+	BillTypeSettlementMerge = "settlementMerge"
 )
 
+// BillDate ...
+const (
+	BillDateDayLayout   = "2006-01-02"
+	BillDateMonthLayout = "2006-01"
+)
+
+// BillDateDay 日账单的bill_date
+func BillDateDay(t time.Time) string {
+	return t.Format(BillDateDayLayout)
+}
+
+// BillDateMonth 月账单的bill_date，月账单压缩包中包含当月的汇总文件以及明细文件
+func BillDateMonth(t time.Time) string {
+	return t.Format(BillDateMonthLayout)
+}
+
+// IsMonthlyBillDate ...
+func IsMonthlyBillDate(billDate string) bool {
+	_, err := time.Parse(BillDateMonthLayout, billDate)
+	return err == nil
+}
+
 // CsvBusinessType ...
 const (
 	CsvBusinessTypeTrade  = "交易"
@@ -27,53 +61,58 @@ const (
 
 // BillTradeEntry ...
 type BillTradeEntry struct {
-	TradeNo             string // 支付宝交易号
-	OutTradeNo          string // 商户订单号
-	BusinessType        string // 业务类型
-	Subject             string // 商品名称
-	TimeStart           string // 创建时间
-	TimeEnd             string // 完成时间
-	ShopNo              string // 门店编号
-	ShopName            string // 门店名称
-	Operator            string // 操作员
-	TerminalNo          string // 终端号
-	BuyerEmail          string // 对方账户
-	TotalAmount         string // 订单金额（元）
-	ReceiptAmount       string // 商家实收（元）
-	Coupon              string // 支付宝红包（元）
-	Jf                  string // 集分宝（元）
-	AlipayOff           string // 支付宝优惠（元）
-	SellerOff           string // 商家优惠（元）
-	CouponChargeOff     string // 券核销金额（元）
-	CouponName          string // 券名称
-	SellerCouponConsume string // 商家红包消费金额（元）
-	HandlingCharge      string // 卡消费金额（元）
-	OutRequestNo        string // 退款批次号/请求号
-	Service             string // 服务费（元）
-	Fr                  string // 分润（元）
-	Body                string // 备注
+	TradeNo             string `bill:"支付宝交易号,required"`  // 支付宝交易号
+	OutTradeNo          string `bill:"商户订单号,required"`   // 商户订单号
+	BusinessType        string `bill:"业务类型,required"`    // 业务类型
+	Subject             string `bill:"商品名称"`             // 商品名称
+	TimeStart           string `bill:"创建时间"`             // 创建时间
+	TimeEnd             string `bill:"完成时间"`             // 完成时间
+	ShopNo              string `bill:"门店编号"`             // 门店编号
+	ShopName            string `bill:"门店名称"`             // 门店名称
+	Operator            string `bill:"操作员"`              // 操作员
+	TerminalNo          string `bill:"终端号"`              // 终端号
+	BuyerEmail          string `bill:"对方账户"`             // 对方账户
+	TotalAmount         string `bill:"订单金额（元）,required"` // 订单金额（元）
+	ReceiptAmount       string `bill:"商家实收（元）,required"` // 商家实收（元）
+	Coupon              string `bill:"支付宝红包（元）"`         // 支付宝红包（元）
+	Jf                  string `bill:"集分宝（元）"`           // 集分宝（元）
+	AlipayOff           string `bill:"支付宝优惠（元）"`         // 支付宝优惠（元）
+	SellerOff           string `bill:"商家优惠（元）"`          // 商家优惠（元）
+	CouponChargeOff     string `bill:"券核销金额（元）"`         // 券核销金额（元）
+	CouponName          string `bill:"券名称"`              // 券名称
+	SellerCouponConsume string `bill:"商家红包消费金额（元）"`      // 商家红包消费金额（元）
+	HandlingCharge      string `bill:"卡消费金额（元）"`         // 卡消费金额（元）
+	OutRequestNo        string `bill:"退款批次号/请求号"`        // 退款批次号/请求号
+	Service             string `bill:"服务费（元）"`           // 服务费（元）
+	Fr                  string `bill:"分润（元）"`            // 分润（元）
+	Body                string `bill:"备注"`               // 备注
+
+	Extras map[string]string // 未知的列，key为表头
 }
 
 // BillSigncustomerEntry ...
 type BillSigncustomerEntry struct {
-	FundFlowID     string // 账务流水号
-	TransactionID  string // 业务流水号
-	BusinessID     string // 商户订单号
-	ProductName    string // 商品名称
-	TimeStart      string // 发生时间
-	OtherAccount   string // 对方账号
-	IncomeAmount   string // 收入金额（+元）
-	ExpensesAmount string // 支出金额（-元）
-	Balance        string // 账户余额（元）
-	TradingChannel string // 交易渠道
-	BusinessType   string // 业务类型
-	Remark         string // 备注
+	FundFlowID     string `bill:"账务流水号,required"`    // 账务流水号
+	TransactionID  string `bill:"业务流水号,required"`    // 业务流水号
+	BusinessID     string `bill:"商户订单号"`             // 商户订单号
+	ProductName    string `bill:"商品名称"`              // 商品名称
+	TimeStart      string `bill:"发生时间"`              // 发生时间
+	OtherAccount   string `bill:"对方账号"`              // 对方账号
+	IncomeAmount   string `bill:"收入金额（+元）,required"` // 收入金额（+元）
+	ExpensesAmount string `bill:"支出金额（-元）,required"` // 支出金额（-元）
+	Balance        string `bill:"账户余额（元）"`           // 账户余额（元）
+	TradingChannel string `bill:"交易渠道"`              // 交易渠道
+	BusinessType   string `bill:"业务类型"`              // 业务类型
+	Remark         string `bill:"备注"`                // 备注
+
+	Extras map[string]string // 未知的列，key为表头
 }
 
 // BillDownloadURLQueryParam ...
 type BillDownloadURLQueryParam struct {
 	BillType string `json:"bill_type,omitempty"` // 账单类型，商户通过接口或商户经开放平台授权后其所属服务商通过接口可以获取以下账单类型：trade、signcustomer；trade指商户基于支付宝交易收单的业务账单；signcustomer是指基于商户支付宝余额收入及支出等资金变动的帐务账单；
 	BillDate string `json:"bill_date,omitempty"` // 账单时间：日账单格式为yyyy-MM-dd，月账单格式为yyyy-MM。
+	Smid     string `json:"smid,omitempty"`      // 二级商户smid，直付通平台商查询二级商户账单时使用
 }
 
 // BillDownloadURLQueryResponse ...
@@ -88,10 +127,11 @@ func (resp *BillDownloadURLQueryResponse) IsBillNotExist() bool {
 }
 
 // BillDownloadurlQuery ...
-func (alipay *Alipay) BillDownloadurlQuery(param *BillDownloadURLQueryParam) (int, *BillDownloadURLQueryResponse, error) {
+func (alipay *Alipay) BillDownloadurlQuery(param *BillDownloadURLQueryParam, fillList ...Fill) (int, *BillDownloadURLQueryResponse, error) {
 	statusCode, body, err := alipay.OnRequest(
 		param,
 		MethodAlipayDataDataserviceBillDownloadurlQuery,
+		fillList...,
 	)
 	if err != nil {
 		return 0, nil, err
@@ -103,195 +143,82 @@ func (alipay *Alipay) BillDownloadurlQuery(param *BillDownloadURLQueryParam) (in
 	return statusCode, billDownloadURLQueryResponse, nil
 }
 
-// DownloadBill ...
+// DownloadBill 下载账单压缩包，HTTP状态码不是200时返回ErrBillDownloadStatus，内容不是完整的zip时返回ErrBillArchiveCorrupt
 func (alipay *Alipay) DownloadBill(billURL string) ([]byte, error) {
-	resp, err := alipay.HTTPClient().Get(billURL)
+	return alipay.DownloadBillContext(context.Background(), billURL)
+}
+
+// DownloadBillContext ctx结束时取消下载，设置了Tracer时ctx中的Span作为父Span
+func (alipay *Alipay) DownloadBillContext(ctx context.Context, billURL string) ([]byte, error) {
+	ctx, span := alipay.startSpan(ctx, SpanDownloadBill)
+	start := time.Now()
+	statusCode, body, err := alipay.downloadBill(ctx, billURL)
+	outcome := OutcomeSuccess
 	if err != nil {
-		return nil, err
+		outcome = OutcomeError
+		span.RecordError(err)
+	}
+	span.SetAttribute("http.status_code", statusCode)
+	span.SetAttribute("alipay.bill_size", len(body))
+	span.SetAttribute("alipay.outcome", outcome)
+	span.End()
+	labels := map[string]string{"outcome": outcome}
+	alipay.incCounter(MetricBillDownloadsTotal, labels)
+	alipay.observeHistogram(MetricBillDownloadDuration, time.Since(start).Seconds(), labels)
+	return body, err
+}
+
+func (alipay *Alipay) downloadBill(ctx context.Context, billURL string) (int, []byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, billURL, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp, err := alipay.HTTPClient().Do(request)
+	if err != nil {
+		return 0, nil, err
 	}
 	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return resp.StatusCode, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil, fmt.Errorf("%w: %d", ErrBillDownloadStatus, resp.StatusCode)
 	}
-	return body, nil
+	if err := verifyBillArchive(body); err != nil {
+		return resp.StatusCode, nil, err
+	}
+	return resp.StatusCode, body, nil
 }
 
-// BillTradeList ...
-func (alipay *Alipay) BillTradeList(bill []byte) ([]*BillTradeEntry, error) {
+// BillTradeList 非严格模式下有行解析失败时，同时返回解析成功的明细以及BillParseErrors
+func (alipay *Alipay) BillTradeList(bill []byte, optionList ...BillOption) ([]*BillTradeEntry, error) {
 	billTradeEntryList := make([]*BillTradeEntry, 0)
-
-	byteReader := bytes.NewReader(bill)
-
-	zipReader, err := zip.NewReader(byteReader, int64(byteReader.Len()))
+	err := alipay.WalkBillTrade(bytes.NewReader(bill), int64(len(bill)), func(entry *BillTradeEntry) error {
+		billTradeEntryList = append(billTradeEntryList, entry)
+		return nil
+	}, optionList...)
+	if _, ok := err.(BillParseErrors); ok {
+		return billTradeEntryList, err
+	}
 	if err != nil {
 		return nil, err
 	}
-
-	for _, file := range zipReader.File {
-		fileNameBytesInUtf8, _ := GbkToUtf8([]byte(file.Name))
-		fileNameInUtf8 := string(fileNameBytesInUtf8)
-		if strings.HasSuffix(fileNameInUtf8, "业务明细.csv") {
-
-			var buf bytes.Buffer
-
-			fileReaderCloser, err := file.Open()
-			if err != nil {
-				return nil, err
-			}
-			defer fileReaderCloser.Close()
-
-			if _, err := io.Copy(&buf, fileReaderCloser); err != nil {
-				return nil, err
-			}
-
-			contentBytesInUtd8, _ := GbkToUtf8(buf.Bytes())
-
-			content := string(contentBytesInUtd8)
-			lines := strings.Split(content, "\n")
-			var (
-				validContent string
-			)
-			for _, line := range lines {
-				if strings.HasPrefix(line, "#") {
-					continue
-				}
-				if len(validContent) > 0 {
-					validContent += "\n"
-				}
-				validContent += line
-			}
-
-			csvReader := csv.NewReader(strings.NewReader(validContent))
-			records, err := csvReader.ReadAll()
-
-			for _, record := range records {
-				for idx, field := range record {
-					record[idx] = strings.TrimSuffix(field, "\t")
-				}
-			}
-
-			if len(records) > 0 {
-				for _, record := range records[1:] {
-					if len(record) != 25 {
-						continue
-					}
-
-					entry := new(BillTradeEntry)
-					billTradeEntryList = append(billTradeEntryList, entry)
-
-					entry.TradeNo = record[0]
-					entry.OutTradeNo = record[1]
-					entry.BusinessType = record[2]
-					entry.Subject = record[3]
-					entry.TimeStart = record[4]
-					entry.TimeEnd = record[5]
-					entry.ShopNo = record[6]
-					entry.ShopName = record[7]
-					entry.Operator = record[8]
-					entry.TerminalNo = record[9]
-					entry.BuyerEmail = record[10]
-					entry.TotalAmount = record[11]
-					entry.ReceiptAmount = record[12]
-					entry.Coupon = record[13]
-					entry.Jf = record[14]
-					entry.AlipayOff = record[15]
-					entry.SellerOff = record[16]
-					entry.CouponChargeOff = record[17]
-					entry.CouponName = record[18]
-					entry.SellerCouponConsume = record[19]
-					entry.HandlingCharge = record[20]
-					entry.OutRequestNo = record[21]
-					entry.Service = record[22]
-					entry.Fr = record[23]
-					entry.Body = record[24]
-				}
-			}
-			break
-		}
-	}
 	return billTradeEntryList, nil
 }
 
-// BillSigncustomerList ...
-func (alipay *Alipay) BillSigncustomerList(bill []byte) ([]*BillSigncustomerEntry, error) {
+// BillSigncustomerList 非严格模式下有行解析失败时，同时返回解析成功的明细以及BillParseErrors
+func (alipay *Alipay) BillSigncustomerList(bill []byte, optionList ...BillOption) ([]*BillSigncustomerEntry, error) {
 	billSigncustomerEntryList := make([]*BillSigncustomerEntry, 0)
-
-	byteReader := bytes.NewReader(bill)
-
-	zipReader, err := zip.NewReader(byteReader, int64(byteReader.Len()))
+	err := alipay.WalkBillSigncustomer(bytes.NewReader(bill), int64(len(bill)), func(entry *BillSigncustomerEntry) error {
+		billSigncustomerEntryList = append(billSigncustomerEntryList, entry)
+		return nil
+	}, optionList...)
+	if _, ok := err.(BillParseErrors); ok {
+		return billSigncustomerEntryList, err
+	}
 	if err != nil {
 		return nil, err
 	}
-
-	for _, file := range zipReader.File {
-		fileNameBytesInUtf8, _ := GbkToUtf8([]byte(file.Name))
-		fileNameInUtf8 := string(fileNameBytesInUtf8)
-		if strings.HasSuffix(fileNameInUtf8, "账务明细.csv") {
-
-			var buf bytes.Buffer
-
-			fileReaderCloser, err := file.Open()
-			if err != nil {
-				return nil, err
-			}
-			defer fileReaderCloser.Close()
-
-			if _, err := io.Copy(&buf, fileReaderCloser); err != nil {
-				return nil, err
-			}
-
-			contentBytesInUtd8, _ := GbkToUtf8(buf.Bytes())
-
-			content := string(contentBytesInUtd8)
-			lines := strings.Split(content, "\n")
-			var (
-				validContent string
-			)
-			for _, line := range lines {
-				if strings.HasPrefix(line, "#") {
-					continue
-				}
-				if len(validContent) > 0 {
-					validContent += "\n"
-				}
-				validContent += line
-			}
-
-			csvReader := csv.NewReader(strings.NewReader(validContent))
-			records, err := csvReader.ReadAll()
-
-			for _, record := range records {
-				for idx, field := range record {
-					record[idx] = strings.TrimSuffix(field, "\t")
-				}
-			}
-
-			if len(records) > 0 {
-				for _, record := range records[1:] {
-					if len(record) != 12 {
-						continue
-					}
-
-					entry := new(BillSigncustomerEntry)
-					billSigncustomerEntryList = append(billSigncustomerEntryList, entry)
-
-					entry.FundFlowID = record[0]
-					entry.TransactionID = record[1]
-					entry.BusinessID = record[2]
-					entry.ProductName = record[3]
-					entry.TimeStart = record[4]
-					entry.OtherAccount = record[5]
-					entry.IncomeAmount = record[6]
-					entry.ExpensesAmount = record[7]
-					entry.Balance = record[8]
-					entry.TradingChannel = record[9]
-					entry.BusinessType = record[10]
-					entry.Remark = record[11]
-				}
-			}
-			break
-		}
-	}
 	return billSigncustomerEntryList, nil
 }
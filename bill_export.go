@@ -0,0 +1,299 @@
+package alipay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// BillColumnType 导出列的类型
+const (
+	BillColumnTypeString = "string"
+	BillColumnTypeMoney  = "money" // 以元为单位、保留两位小数，例如"-1.50"
+	BillColumnTypeTime   = "time"  // BillExportTimeLayout格式，带北京时间时区
+)
+
+// BillEntryTimeLayout 账单明细中时间的格式，为北京时间
+const BillEntryTimeLayout = "2006-01-02 15:04:05"
+
+// BillExportTimeLayout 导出时间的格式
+const BillExportTimeLayout = time.RFC3339
+
+// billLocation 账单中的时间都是北京时间，不依赖运行环境的时区
+var billLocation = time.FixedZone("CST", 8*60*60)
+
+// BillColumn 导出的列
+type BillColumn struct {
+	Name string // 英文列名
+	Type string // BillColumnType
+}
+
+// BillTable 规范化后的账单明细，金额和时间为空时是NULL，其他列不区分空字符串和NULL
+type BillTable struct {
+	Columns []BillColumn
+	Rows    [][]string
+}
+
+type billExportColumn struct {
+	BillColumn
+	value func(entry interface{}) string
+}
+
+var billTradeExportColumns = []billExportColumn{
+	{BillColumn{"trade_no", BillColumnTypeString}, func(e interface{}) string { return e.(*BillTradeEntry).TradeNo }},
+	{BillColumn{"out_trade_no", BillColumnTypeString}, func(e interface{}) string { return e.(*BillTradeEntry).OutTradeNo }},
+	{BillColumn{"business_type", BillColumnTypeString}, func(e interface{}) string { return e.(*BillTradeEntry).BusinessType }},
+	{BillColumn{"subject", BillColumnTypeString}, func(e interface{}) string { return e.(*BillTradeEntry).Subject }},
+	{BillColumn{"created_at", BillColumnTypeTime}, func(e interface{}) string { return e.(*BillTradeEntry).TimeStart }},
+	{BillColumn{"finished_at", BillColumnTypeTime}, func(e interface{}) string { return e.(*BillTradeEntry).TimeEnd }},
+	{BillColumn{"shop_no", BillColumnTypeString}, func(e interface{}) string { return e.(*BillTradeEntry).ShopNo }},
+	{BillColumn{"shop_name", BillColumnTypeString}, func(e interface{}) string { return e.(*BillTradeEntry).ShopName }},
+	{BillColumn{"operator", BillColumnTypeString}, func(e interface{}) string { return e.(*BillTradeEntry).Operator }},
+	{BillColumn{"terminal_no", BillColumnTypeString}, func(e interface{}) string { return e.(*BillTradeEntry).TerminalNo }},
+	{BillColumn{"buyer_account", BillColumnTypeString}, func(e interface{}) string { return e.(*BillTradeEntry).BuyerEmail }},
+	{BillColumn{"total_amount", BillColumnTypeMoney}, func(e interface{}) string { return e.(*BillTradeEntry).TotalAmount }},
+	{BillColumn{"receipt_amount", BillColumnTypeMoney}, func(e interface{}) string { return e.(*BillTradeEntry).ReceiptAmount }},
+	{BillColumn{"alipay_red_packet_amount", BillColumnTypeMoney}, func(e interface{}) string { return e.(*BillTradeEntry).Coupon }},
+	{BillColumn{"point_amount", BillColumnTypeMoney}, func(e interface{}) string { return e.(*BillTradeEntry).Jf }},
+	{BillColumn{"alipay_discount_amount", BillColumnTypeMoney}, func(e interface{}) string { return e.(*BillTradeEntry).AlipayOff }},
+	{BillColumn{"merchant_discount_amount", BillColumnTypeMoney}, func(e interface{}) string { return e.(*BillTradeEntry).SellerOff }},
+	{BillColumn{"coupon_charge_off_amount", BillColumnTypeMoney}, func(e interface{}) string { return e.(*BillTradeEntry).CouponChargeOff }},
+	{BillColumn{"coupon_name", BillColumnTypeString}, func(e interface{}) string { return e.(*BillTradeEntry).CouponName }},
+	{BillColumn{"merchant_red_packet_amount", BillColumnTypeMoney}, func(e interface{}) string { return e.(*BillTradeEntry).SellerCouponConsume }},
+	{BillColumn{"card_amount", BillColumnTypeMoney}, func(e interface{}) string { return e.(*BillTradeEntry).HandlingCharge }},
+	{BillColumn{"out_request_no", BillColumnTypeString}, func(e interface{}) string { return e.(*BillTradeEntry).OutRequestNo }},
+	{BillColumn{"service_fee", BillColumnTypeMoney}, func(e interface{}) string { return e.(*BillTradeEntry).Service }},
+	{BillColumn{"royalty_amount", BillColumnTypeMoney}, func(e interface{}) string { return e.(*BillTradeEntry).Fr }},
+	{BillColumn{"remark", BillColumnTypeString}, func(e interface{}) string { return e.(*BillTradeEntry).Body }},
+}
+
+var billSigncustomerExportColumns = []billExportColumn{
+	{BillColumn{"fund_flow_id", BillColumnTypeString}, func(e interface{}) string { return e.(*BillSigncustomerEntry).FundFlowID }},
+	{BillColumn{"transaction_id", BillColumnTypeString}, func(e interface{}) string { return e.(*BillSigncustomerEntry).TransactionID }},
+	{BillColumn{"out_trade_no", BillColumnTypeString}, func(e interface{}) string { return e.(*BillSigncustomerEntry).BusinessID }},
+	{BillColumn{"product_name", BillColumnTypeString}, func(e interface{}) string { return e.(*BillSigncustomerEntry).ProductName }},
+	{BillColumn{"occurred_at", BillColumnTypeTime}, func(e interface{}) string { return e.(*BillSigncustomerEntry).TimeStart }},
+	{BillColumn{"other_account", BillColumnTypeString}, func(e interface{}) string { return e.(*BillSigncustomerEntry).OtherAccount }},
+	{BillColumn{"income_amount", BillColumnTypeMoney}, func(e interface{}) string { return e.(*BillSigncustomerEntry).IncomeAmount }},
+	{BillColumn{"expense_amount", BillColumnTypeMoney}, func(e interface{}) string { return e.(*BillSigncustomerEntry).ExpensesAmount }},
+	{BillColumn{"balance", BillColumnTypeMoney}, func(e interface{}) string { return e.(*BillSigncustomerEntry).Balance }},
+	{BillColumn{"trading_channel", BillColumnTypeString}, func(e interface{}) string { return e.(*BillSigncustomerEntry).TradingChannel }},
+	{BillColumn{"business_type", BillColumnTypeString}, func(e interface{}) string { return e.(*BillSigncustomerEntry).BusinessType }},
+	{BillColumn{"remark", BillColumnTypeString}, func(e interface{}) string { return e.(*BillSigncustomerEntry).Remark }},
+}
+
+// normBillValue 金额统一为两位小数，时间统一为BillExportTimeLayout，其他去掉首尾空白
+func normBillValue(columnType, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return "", nil
+	}
+	switch columnType {
+	case BillColumnTypeMoney:
+		cent, err := Int64ifyCent(value)
+		if err != nil {
+			return "", err
+		}
+		return StringifyCent(cent), nil
+	case BillColumnTypeTime:
+		t, err := time.ParseInLocation(BillEntryTimeLayout, value, billLocation)
+		if err != nil {
+			return "", err
+		}
+		return t.Format(BillExportTimeLayout), nil
+	}
+	return value, nil
+}
+
+func newBillTable(columns []billExportColumn, count int, entry func(idx int) interface{}) (*BillTable, error) {
+	table := &BillTable{
+		Columns: make([]BillColumn, 0, len(columns)),
+		Rows:    make([][]string, 0, count),
+	}
+	for _, column := range columns {
+		table.Columns = append(table.Columns, column.BillColumn)
+	}
+	for idx := 0; idx < count; idx++ {
+		row := make([]string, 0, len(columns))
+		for _, column := range columns {
+			value, err := normBillValue(column.Type, column.value(entry(idx)))
+			if err != nil {
+				return nil, fmt.Errorf("第%d条明细%s格式不正确: %w", idx+1, column.Name, err)
+			}
+			row = append(row, value)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+// NewBillTradeTable 规范化业务明细，未知的列（Extras）不导出
+func NewBillTradeTable(list []*BillTradeEntry) (*BillTable, error) {
+	return newBillTable(billTradeExportColumns, len(list), func(idx int) interface{} {
+		return list[idx]
+	})
+}
+
+// NewBillSigncustomerTable 规范化账务明细，未知的列（Extras）不导出
+func NewBillSigncustomerTable(list []*BillSigncustomerEntry) (*BillTable, error) {
+	return newBillTable(billSigncustomerExportColumns, len(list), func(idx int) interface{} {
+		return list[idx]
+	})
+}
+
+func (table *BillTable) isNull(columnIdx int, value string) bool {
+	return len(value) == 0 && table.Columns[columnIdx].Type != BillColumnTypeString
+}
+
+// WriteCSV 写入UTF-8编码、英文表头的CSV
+func (table *BillTable) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		header = append(header, column.Name)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(table.Rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// WriteJSONLines 每行一个按列顺序输出的JSON对象，金额为数字，NULL为null
+func (table *BillTable) WriteJSONLines(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	for _, row := range table.Rows {
+		writer.WriteByte('{')
+		for idx, value := range row {
+			column := table.Columns[idx]
+			if idx > 0 {
+				writer.WriteByte(',')
+			}
+			name, _ := json.Marshal(column.Name)
+			writer.Write(name)
+			writer.WriteByte(':')
+			switch {
+			case table.isNull(idx, value):
+				writer.WriteString("null")
+			case column.Type == BillColumnTypeMoney:
+				writer.WriteString(value)
+			default:
+				writer.Write(marshalBillString(value))
+			}
+		}
+		writer.WriteString("}\n")
+	}
+	return writer.Flush()
+}
+
+// marshalBillString 与json.Marshal相同，但不转义<>&
+func marshalBillString(value string) []byte {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+	return bytes.TrimRight(buf.Bytes(), "\n")
+}
+
+// ErrBillSQLName ...
+var (
+	ErrBillSQLName = errors.New("表名或列名只能包含字母、数字、下划线和点，并且不能以数字开头")
+)
+
+var billSQLNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// sqlNames 校验表名和列名，直接拼接到SQL语句中，不能包含需要引用的字符
+func (table *BillTable) sqlNames(tableName string) ([]string, error) {
+	if !billSQLNamePattern.MatchString(tableName) {
+		return nil, fmt.Errorf("%w: %q", ErrBillSQLName, tableName)
+	}
+	names := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		if !billSQLNamePattern.MatchString(column.Name) {
+			return nil, fmt.Errorf("%w: %q", ErrBillSQLName, column.Name)
+		}
+		names = append(names, column.Name)
+	}
+	return names, nil
+}
+
+var billSQLEscaper = strings.NewReplacer(`\`, `\\`, "\x00", `\0`, "'", "''")
+
+// quoteSQLString 按MySQL的规则转义反斜杠和NUL，否则以反斜杠结尾的值会吞掉右引号
+func quoteSQLString(value string) string {
+	return "'" + billSQLEscaper.Replace(value) + "'"
+}
+
+// WriteSQLInsert 写入INSERT语句，每batchSize行一条，batchSize不大于0时所有行一条。
+// tableName可以带schema，例如billing.alipay_trade，不符合ErrBillSQLName的规则时返回错误。
+// 字符串按MySQL的规则转义，PostgreSQL请使用WriteSQLCopy
+func (table *BillTable) WriteSQLInsert(w io.Writer, tableName string, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = len(table.Rows)
+	}
+	names, err := table.sqlNames(tableName)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(w)
+	for start := 0; start < len(table.Rows); start += batchSize {
+		end := start + batchSize
+		if end > len(table.Rows) {
+			end = len(table.Rows)
+		}
+		fmt.Fprintf(writer, "INSERT INTO %s (%s) VALUES\n", tableName, strings.Join(names, ", "))
+		for rowIdx, row := range table.Rows[start:end] {
+			values := make([]string, 0, len(row))
+			for idx, value := range row {
+				switch {
+				case table.isNull(idx, value):
+					values = append(values, "NULL")
+				case table.Columns[idx].Type == BillColumnTypeMoney:
+					values = append(values, value)
+				default:
+					values = append(values, quoteSQLString(value))
+				}
+			}
+			separator := ",\n"
+			if start+rowIdx == end-1 {
+				separator = ";\n"
+			}
+			writer.WriteString("(" + strings.Join(values, ", ") + ")" + separator)
+		}
+	}
+	return writer.Flush()
+}
+
+var billCopyEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// WriteSQLCopy 写入PostgreSQL COPY ... FROM STDIN的文本格式，包含COPY语句以及结束标记，tableName的规则同WriteSQLInsert
+func (table *BillTable) WriteSQLCopy(w io.Writer, tableName string) error {
+	names, err := table.sqlNames(tableName)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(w)
+	fmt.Fprintf(writer, "COPY %s (%s) FROM STDIN;\n", tableName, strings.Join(names, ", "))
+	for _, row := range table.Rows {
+		values := make([]string, 0, len(row))
+		for idx, value := range row {
+			if table.isNull(idx, value) {
+				values = append(values, `\N`)
+				continue
+			}
+			values = append(values, billCopyEscaper.Replace(value))
+		}
+		writer.WriteString(strings.Join(values, "\t") + "\n")
+	}
+	writer.WriteString("\\.\n")
+	return writer.Flush()
+}
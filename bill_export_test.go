@@ -0,0 +1,149 @@
+package alipay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testBillTable() *BillTable {
+	return &BillTable{
+		Columns: []BillColumn{
+			{Name: "out_trade_no", Type: BillColumnTypeString},
+			{Name: "amount", Type: BillColumnTypeMoney},
+			{Name: "finished_at", Type: BillColumnTypeTime},
+			{Name: "remark", Type: BillColumnTypeString},
+		},
+		Rows: [][]string{
+			{"T001", "10.00", "2020-01-02T10:00:05+08:00", "it's <ok>"},
+			{"T002", "", "", "tab\there"},
+		},
+	}
+}
+
+func TestNewBillTradeTable(t *testing.T) {
+	table, err := NewBillTradeTable([]*BillTradeEntry{
+		{OutTradeNo: " T001 ", TimeEnd: "2020-01-02 10:00:05", TotalAmount: "1", ReceiptAmount: "-3.5"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := make(map[string]string)
+	for idx, column := range table.Columns {
+		row[column.Name] = table.Rows[0][idx]
+	}
+	want := map[string]string{
+		"out_trade_no":   "T001",
+		"finished_at":    "2020-01-02T10:00:05+08:00",
+		"total_amount":   "1.00",
+		"receipt_amount": "-3.50",
+		"service_fee":    "",
+	}
+	for name, value := range want {
+		if row[name] != value {
+			t.Errorf("%s = %q, want %q", name, row[name], value)
+		}
+	}
+
+	if _, err := NewBillTradeTable([]*BillTradeEntry{{TotalAmount: "1.001"}}); err == nil {
+		t.Fatal("sub-cent amount should fail")
+	}
+}
+
+func TestBillTableWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testBillTable().WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "out_trade_no,amount,finished_at,remark\n" +
+		"T001,10.00,2020-01-02T10:00:05+08:00,it's <ok>\n" +
+		"T002,,,tab\there\n"
+	if buf.String() != want {
+		t.Fatalf("csv:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestBillTableWriteJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testBillTable().WriteJSONLines(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %d, want 2", len(lines))
+	}
+	if lines[0] != `{"out_trade_no":"T001","amount":10.00,"finished_at":"2020-01-02T10:00:05+08:00","remark":"it's <ok>"}` {
+		t.Errorf("line 1 = %s", lines[0])
+	}
+	row := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(lines[1]), &row); err != nil {
+		t.Fatal(err)
+	}
+	if row["amount"] != nil || row["finished_at"] != nil || row["remark"] != "tab\there" {
+		t.Errorf("line 2 = %v", row)
+	}
+}
+
+func TestBillTableWriteSQLInsert(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testBillTable().WriteSQLInsert(&buf, "billing.alipay_trade", 1); err != nil {
+		t.Fatal(err)
+	}
+	want := "INSERT INTO billing.alipay_trade (out_trade_no, amount, finished_at, remark) VALUES\n" +
+		"('T001', 10.00, '2020-01-02T10:00:05+08:00', 'it''s <ok>');\n" +
+		"INSERT INTO billing.alipay_trade (out_trade_no, amount, finished_at, remark) VALUES\n" +
+		"('T002', NULL, NULL, 'tab\there');\n"
+	if buf.String() != want {
+		t.Fatalf("sql:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	table := testBillTable()
+	table.Rows = [][]string{{"T003", "", "", `C:\`}, {"T004", "", "", "a\x00b', 1); --"}}
+	buf.Reset()
+	if err := table.WriteSQLInsert(&buf, "alipay_trade", 0); err != nil {
+		t.Fatal(err)
+	}
+	want = "INSERT INTO alipay_trade (out_trade_no, amount, finished_at, remark) VALUES\n" +
+		"('T003', NULL, NULL, 'C:\\\\'),\n" +
+		"('T004', NULL, NULL, 'a\\0b'', 1); --');\n"
+	if buf.String() != want {
+		t.Fatalf("sql:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestBillTableWriteSQLCopy(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testBillTable().WriteSQLCopy(&buf, "alipay_trade"); err != nil {
+		t.Fatal(err)
+	}
+	want := "COPY alipay_trade (out_trade_no, amount, finished_at, remark) FROM STDIN;\n" +
+		"T001\t10.00\t2020-01-02T10:00:05+08:00\tit's <ok>\n" +
+		"T002\t\\N\t\\N\ttab\\there\n" +
+		"\\.\n"
+	if buf.String() != want {
+		t.Fatalf("copy:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestBillTableSQLName(t *testing.T) {
+	for _, tableName := range []string{"", "1trade", "trade; DROP TABLE users", `"trade"`, "trade (id)"} {
+		var buf bytes.Buffer
+		if err := testBillTable().WriteSQLInsert(&buf, tableName, 0); !errors.Is(err, ErrBillSQLName) {
+			t.Errorf("insert %q: err = %v, want ErrBillSQLName", tableName, err)
+		}
+		if err := testBillTable().WriteSQLCopy(&buf, tableName); !errors.Is(err, ErrBillSQLName) {
+			t.Errorf("copy %q: err = %v, want ErrBillSQLName", tableName, err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%q: nothing should be written, got %q", tableName, buf.String())
+		}
+	}
+
+	table := testBillTable()
+	table.Columns[3].Name = "remark) VALUES (1); --"
+	if err := table.WriteSQLInsert(&bytes.Buffer{}, "alipay_trade", 0); !errors.Is(err, ErrBillSQLName) {
+		t.Errorf("column name: err = %v, want ErrBillSQLName", err)
+	}
+}
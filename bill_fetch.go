@@ -0,0 +1,129 @@
+package alipay
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// ErrBillNotExist ...
+var (
+	ErrBillNotExist       = errors.New("账单不存在")
+	ErrBillDownloadStatus = errors.New("账单下载失败，HTTP状态码不正确")
+	ErrBillArchiveCorrupt = errors.New("账单压缩包不完整")
+)
+
+// billFetchAttempts 下载地址30秒后失效，下载失败时重新查询地址的次数
+const billFetchAttempts = 3
+
+// Bill 下载并解析后的账单，trade与signcustomer之外的账单类型只返回压缩包，可以用WalkBillRecord解析
+type Bill struct {
+	BillType         string
+	BillDate         string
+	Data             []byte // 账单压缩包原始内容
+	Meta             *BillMeta
+	TradeList        []*BillTradeEntry        // BillTypeTrade的业务明细
+	SigncustomerList []*BillSigncustomerEntry // BillTypeSigncustomer的账务明细
+}
+
+// verifyBillArchive 检查zip目录以及每个文件的CRC
+func verifyBillArchive(data []byte) error {
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBillArchiveCorrupt, err)
+	}
+	for _, file := range zipReader.File {
+		reader, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrBillArchiveCorrupt, billFileName(file), err)
+		}
+		_, err = io.Copy(ioutil.Discard, reader)
+		reader.Close()
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrBillArchiveCorrupt, billFileName(file), err)
+		}
+	}
+	return nil
+}
+
+// fillContext 取fillList中WithContext设置的ctx
+func fillContext(fillList []Fill) context.Context {
+	cp := &CommonParam{}
+	for _, fill := range fillList {
+		fill(cp)
+	}
+	return cp.ctx()
+}
+
+// downloadBillData 查询下载地址并下载，地址过期或者内容不完整时重新查询
+func (alipay *Alipay) downloadBillData(param *BillDownloadURLQueryParam, fillList ...Fill) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; attempt < billFetchAttempts; attempt++ {
+		_, resp, err := alipay.BillDownloadurlQuery(param, fillList...)
+		if err != nil {
+			return nil, err
+		}
+		if resp.IsBillNotExist() {
+			return nil, fmt.Errorf("%w: %s %s", ErrBillNotExist, param.BillType, param.BillDate)
+		}
+		if !resp.Success() {
+			return nil, fmt.Errorf("查询账单下载地址失败: %s %s %s %s", resp.Code, resp.Msg, resp.SubCode, resp.SubMsg)
+		}
+		data, err := alipay.DownloadBillContext(fillContext(fillList), resp.BillDownloadURL)
+		if err == nil {
+			return data, nil
+		}
+		if !errors.Is(err, ErrBillDownloadStatus) && !errors.Is(err, ErrBillArchiveCorrupt) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// FetchBill 查询下载地址、下载并解析账单，账单不存在时返回ErrBillNotExist。
+// 非严格模式下有行解析失败时，同时返回账单以及BillParseErrors
+func (alipay *Alipay) FetchBill(billDate string, billType string, optionList ...BillOption) (*Bill, error) {
+	if _, err := time.Parse(BillDateDayLayout, billDate); err != nil && !IsMonthlyBillDate(billDate) {
+		return nil, fmt.Errorf("账单时间格式应为yyyy-MM-dd或yyyy-MM: %s", billDate)
+	}
+
+	opts := newBillOptions(optionList)
+	param := &BillDownloadURLQueryParam{
+		BillType: billType,
+		BillDate: billDate,
+		Smid:     opts.smid,
+	}
+	data, err := alipay.downloadBillData(param, opts.fillList...)
+	if err != nil {
+		return nil, err
+	}
+
+	bill := &Bill{
+		BillType: billType,
+		BillDate: billDate,
+		Data:     data,
+		Meta:     opts.meta,
+	}
+	optionList = append(optionList, WithBillMeta(bill.Meta))
+	switch billType {
+	case BillTypeTrade:
+		bill.TradeList, err = alipay.BillTradeList(data, optionList...)
+	case BillTypeSigncustomer:
+		bill.SigncustomerList, err = alipay.BillSigncustomerList(data, optionList...)
+	default:
+		return bill, nil
+	}
+	if _, ok := err.(BillParseErrors); ok {
+		return bill, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bill, nil
+}
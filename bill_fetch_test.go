@@ -0,0 +1,80 @@
+package alipay
+
+import (
+	"errors"
+	"testing"
+)
+
+// handleBill 注册账单下载地址，bill_date不在files中时返回账单不存在
+func handleBill(gateway *testGateway, files map[string][]byte) {
+	gateway.handle(MethodAlipayDataDataserviceBillDownloadurlQuery, func(req *testRequest) map[string]interface{} {
+		if _, ok := files[req.biz("bill_date")]; !ok {
+			return map[string]interface{}{"code": "40004", "msg": "Business Failed", "sub_code": "isp.bill_not_exist"}
+		}
+		return map[string]interface{}{"bill_download_url": "https://dwbillcenter.alipay.com/downloadBillFile.resource?bill_date=" + req.biz("bill_date")}
+	})
+	for billDate, data := range files {
+		gateway.file("https://dwbillcenter.alipay.com/downloadBillFile.resource?bill_date="+billDate, data)
+	}
+}
+
+func TestFetchBill(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	handleBill(gateway, map[string][]byte{"2020-01-02": testTradeBill(t)})
+
+	bill, err := alipay.FetchBill("2020-01-02", BillTypeTrade, WithBillStrict(), WithBillVerifyTotals(), WithBillSmid("2088000000000002"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bill.TradeList) != 3 || bill.Meta.Totals[BillTotalNameRefund].Count != 1 {
+		t.Fatalf("bill: %+v", bill)
+	}
+	if req := gateway.sent(MethodAlipayDataDataserviceBillDownloadurlQuery)[0]; req.biz("smid") != "2088000000000002" {
+		t.Fatalf("biz_content: %v", req.Biz)
+	}
+
+	if _, err := alipay.FetchBill("2020-01-03", BillTypeTrade); !errors.Is(err, ErrBillNotExist) {
+		t.Fatalf("err = %v, want ErrBillNotExist", err)
+	}
+}
+
+func TestFetchBillDate(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	handleBill(gateway, map[string][]byte{})
+
+	if _, err := alipay.FetchBill("20200102", BillTypeTrade); err == nil || errors.Is(err, ErrBillNotExist) {
+		t.Fatalf("err = %v, want a bill date format error", err)
+	}
+	if count := len(gateway.sent(MethodAlipayDataDataserviceBillDownloadurlQuery)); count != 0 {
+		t.Fatalf("invalid bill date should not be sent, sent %d requests", count)
+	}
+
+	// 直接调用接口时由支付宝校验账单时间
+	_, resp, err := alipay.BillDownloadurlQuery(&BillDownloadURLQueryParam{BillType: BillTypeTrade, BillDate: "20200102"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsBillNotExist() {
+		t.Fatalf("resp: %+v", resp)
+	}
+}
+
+func TestFetchBillCorrupt(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	data := testTradeBill(t)
+	handleBill(gateway, map[string][]byte{"2020-01-02": data[:len(data)/2]})
+
+	if _, err := alipay.FetchBill("2020-01-02", BillTypeTrade); !errors.Is(err, ErrBillArchiveCorrupt) {
+		t.Fatalf("err = %v, want ErrBillArchiveCorrupt", err)
+	}
+	if count := len(gateway.sent(MethodAlipayDataDataserviceBillDownloadurlQuery)); count != billFetchAttempts {
+		t.Fatalf("download url queried %d times, want %d", count, billFetchAttempts)
+	}
+}
+
+func TestDownloadBillStatus(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	if _, err := alipay.DownloadBill("https://dwbillcenter.alipay.com/downloadBillFile.resource?bill_date=2020-01-02"); !errors.Is(err, ErrBillDownloadStatus) {
+		t.Fatalf("err = %v, want ErrBillDownloadStatus", err)
+	}
+}
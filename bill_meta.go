@@ -0,0 +1,198 @@
+package alipay
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// BillTotalName 账单尾部合计行的名称
+const (
+	BillTotalNameTrade   = "交易"
+	BillTotalNameRefund  = "退款"
+	BillTotalNameIncome  = "收入"
+	BillTotalNameExpense = "支出"
+)
+
+// BillTotalAmountName 合计行中的金额名称，"共0.03元"这种没有名称的金额使用BillTotalAmountNameDefault
+const (
+	BillTotalAmountNameReceipt   = "商家实收"
+	BillTotalAmountNameSellerOff = "商家优惠"
+	BillTotalAmountNameDefault   = "金额"
+)
+
+// BillTimeLayout 账单头尾中时间的格式
+const (
+	BillTimeLayout = "2006年01月02日 15:04:05"
+)
+
+// ErrBillTotalsMismatch ...
+var (
+	ErrBillTotalsMismatch = errors.New("账单明细与合计不一致，账单可能不完整")
+)
+
+// BillTotal 账单尾部的合计行，例如"#交易合计：3笔，商家实收共：3.00元，商家优惠共：0.00元"
+type BillTotal struct {
+	Name    string            // 交易、退款、收入、支出
+	Count   int               // 笔数
+	Amounts map[string]string // 金额名称到金额（元）
+}
+
+// BillMeta 账单头尾中的信息，月账单等包含多个文件时，合计行会累加，起始日期取第一个文件，终止日期取最后一个文件
+type BillMeta struct {
+	Files      []string              // 解析过的文件名
+	Account    string                // 账号
+	StartDate  string                // 起始日期，格式见BillTimeLayout
+	EndDate    string                // 终止日期，格式见BillTimeLayout
+	ExportTime string                // 导出时间，格式见BillTimeLayout
+	Totals     map[string]*BillTotal // 合计行，key为BillTotal.Name
+	Comments   []string              // 所有#开头的原始行
+}
+
+// StartTime ...
+func (meta *BillMeta) StartTime() (time.Time, error) {
+	return time.ParseInLocation(BillTimeLayout, meta.StartDate, time.Local)
+}
+
+// EndTime ...
+func (meta *BillMeta) EndTime() (time.Time, error) {
+	return time.ParseInLocation(BillTimeLayout, meta.EndDate, time.Local)
+}
+
+var (
+	billMetaFieldRegexp   = regexp.MustCompile(`(账号|起始日期|终止日期|导出时间)：\[([^\]]*)\]`)
+	billTotalRegexp       = regexp.MustCompile(`^#?\s*(\S+?)合计：\s*(\d+)笔`)
+	billTotalAmountRegexp = regexp.MustCompile(`([^，,：:\s]*)共：?\s*([-+]?[\d.]+)元`)
+)
+
+func (meta *BillMeta) parseComment(row int, line string) {
+	meta.Comments = append(meta.Comments, line)
+
+	for _, match := range billMetaFieldRegexp.FindAllStringSubmatch(line, -1) {
+		switch match[1] {
+		case "账号":
+			meta.Account = match[2]
+		case "起始日期":
+			if len(meta.StartDate) == 0 {
+				meta.StartDate = match[2]
+			}
+		case "终止日期":
+			meta.EndDate = match[2]
+		case "导出时间":
+			meta.ExportTime = match[2]
+		}
+	}
+
+	match := billTotalRegexp.FindStringSubmatch(line)
+	if match == nil {
+		return
+	}
+	if meta.Totals == nil {
+		meta.Totals = make(map[string]*BillTotal)
+	}
+	total, ok := meta.Totals[match[1]]
+	if !ok {
+		total = &BillTotal{
+			Name:    match[1],
+			Amounts: make(map[string]string),
+		}
+		meta.Totals[total.Name] = total
+	}
+	count, _ := strconv.Atoi(match[2])
+	total.Count += count
+	for _, amountMatch := range billTotalAmountRegexp.FindAllStringSubmatch(line, -1) {
+		name := amountMatch[1]
+		if len(name) == 0 {
+			name = BillTotalAmountNameDefault
+		}
+		total.Amounts[name] = addBillAmount(total.Amounts[name], amountMatch[2])
+	}
+}
+
+// addBillAmount 累加以元为单位的金额，无法解析时保留后一个
+func addBillAmount(a, b string) string {
+	if len(a) == 0 {
+		return b
+	}
+	centA, errA := Int64ifyCent(a)
+	centB, errB := Int64ifyCent(b)
+	if errA != nil || errB != nil {
+		return b
+	}
+	return StringifyCent(centA + centB)
+}
+
+// billTotalCounter 累加解析出来的明细，用于和合计行核对
+type billTotalCounter struct {
+	counts  map[string]int
+	amounts map[string]map[string]int64
+}
+
+func newBillTotalCounter() *billTotalCounter {
+	return &billTotalCounter{
+		counts:  make(map[string]int),
+		amounts: make(map[string]map[string]int64),
+	}
+}
+
+// add 记录一笔明细，amounts为需要核对的金额
+func (c *billTotalCounter) add(name string, amounts map[string]string) error {
+	c.counts[name]++
+	if c.amounts[name] == nil {
+		c.amounts[name] = make(map[string]int64)
+	}
+	for amountName, amount := range amounts {
+		cent, err := Int64ifyCent(amount)
+		if err != nil {
+			return &BillParseError{Err: err}
+		}
+		c.amounts[name][amountName] += cent
+	}
+	return nil
+}
+
+// addSigncustomer 一笔账务明细只会有收入或支出其中一项
+func (c *billTotalCounter) addSigncustomer(entry *BillSigncustomerEntry) error {
+	income, err := Int64ifyCent(entry.IncomeAmount)
+	if err != nil {
+		return &BillParseError{Err: err}
+	}
+	if income != 0 {
+		return c.add(BillTotalNameIncome, map[string]string{BillTotalAmountNameDefault: entry.IncomeAmount})
+	}
+	return c.add(BillTotalNameExpense, map[string]string{BillTotalAmountNameDefault: entry.ExpensesAmount})
+}
+
+// verify 核对合计行中的笔数和金额，names为必须存在的合计行，amountNames为需要核对的金额
+func (c *billTotalCounter) verify(meta *BillMeta, names []string, amountNames []string) error {
+	for _, name := range names {
+		total, ok := meta.Totals[name]
+		if !ok {
+			return fmt.Errorf("%w: 缺少%s合计行", ErrBillTotalsMismatch, name)
+		}
+		if total.Count != c.counts[name] {
+			return fmt.Errorf("%w: %s合计%d笔，明细%d笔", ErrBillTotalsMismatch, name, total.Count, c.counts[name])
+		}
+		for _, amountName := range amountNames {
+			amount, ok := total.Amounts[amountName]
+			if !ok {
+				// 退款合计行中的金额名称为"商家实收退款"
+				amount, ok = total.Amounts[amountName+name]
+			}
+			if !ok {
+				continue
+			}
+			declared, err := Int64ifyCent(amount)
+			if err != nil {
+				return fmt.Errorf("%w: %s%s合计金额格式错误: %v", ErrBillTotalsMismatch, name, amountName, err)
+			}
+			counted := c.amounts[name][amountName]
+			if counted != declared {
+				return fmt.Errorf("%w: %s%s合计%s元，明细%s元", ErrBillTotalsMismatch, name, amountName, StringifyCent(declared), StringifyCent(counted))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,389 @@
+package alipay
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// ErrStopBillWalk 回调返回该错误时停止遍历，Walk返回nil
+var (
+	ErrStopBillWalk     = errors.New("停止遍历账单")
+	ErrBillFileNotFound = errors.New("账单压缩包中没有找到明细文件")
+	ErrBillFieldCount   = errors.New("账单列数不正确")
+	ErrBillInvalidGbk   = errors.New("账单包含无法GBK解码的内容")
+)
+
+// BillParseError 账单中某一行解析失败，Row为该行在文件中的行号，从1开始
+type BillParseError struct {
+	File string
+	Row  int
+	Line string
+	Err  error
+}
+
+func (e *BillParseError) Error() string {
+	return fmt.Sprintf("%s 第%d行: %v: %q", e.File, e.Row, e.Err, e.Line)
+}
+
+// Unwrap ...
+func (e *BillParseError) Unwrap() error {
+	return e.Err
+}
+
+// BillParseErrors 非严格模式下被跳过的行
+type BillParseErrors []*BillParseError
+
+func (errs BillParseErrors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	return fmt.Sprintf("账单有%d行解析失败，第一个错误: %v", len(errs), errs[0])
+}
+
+// BillOption ...
+type BillOption func(*billOptions)
+
+type billOptions struct {
+	strict       bool
+	meta         *BillMeta
+	verifyTotals bool
+	smid         string
+	fillList     []Fill
+	file         int // 已开始遍历的文件数，billColumnMapper据此为每个文件重建表头映射
+}
+
+// WithBillStrict 严格模式，遇到解析失败的行立即返回*BillParseError；非严格模式跳过这些行，遍历结束后返回BillParseErrors。表头解析失败时总是返回*BillParseError
+func WithBillStrict() BillOption {
+	return func(opts *billOptions) {
+		opts.strict = true
+	}
+}
+
+// WithBillMeta 解析账单头尾中的账号、起止日期、合计等信息到meta中
+func WithBillMeta(meta *BillMeta) BillOption {
+	return func(opts *billOptions) {
+		opts.meta = meta
+	}
+}
+
+// WithBillVerifyTotals 遍历结束后核对明细与尾部合计行的笔数和金额，不一致时返回ErrBillTotalsMismatch
+func WithBillVerifyTotals() BillOption {
+	return func(opts *billOptions) {
+		opts.verifyTotals = true
+	}
+}
+
+// WithBillSmid FetchBill时查询该二级商户的账单
+func WithBillSmid(smid string) BillOption {
+	return func(opts *billOptions) {
+		opts.smid = smid
+	}
+}
+
+// WithBillFill FetchBill查询下载地址时使用的公共参数，例如WithAppAuthToken
+func WithBillFill(fillList ...Fill) BillOption {
+	return func(opts *billOptions) {
+		opts.fillList = append(opts.fillList, fillList...)
+	}
+}
+
+func newBillOptions(optionList []BillOption) *billOptions {
+	opts := new(billOptions)
+	for _, option := range optionList {
+		option(opts)
+	}
+	if opts.meta == nil {
+		opts.meta = new(BillMeta)
+	}
+	return opts
+}
+
+// billScanner 逐行读取账单内容，跳过#开头的行以及空行
+type billScanner struct {
+	reader    *bufio.Reader
+	row       int
+	onComment func(row int, line string)
+}
+
+func newBillScanner(r io.Reader, onComment func(row int, line string)) *billScanner {
+	return &billScanner{
+		reader:    bufio.NewReader(r),
+		onComment: onComment,
+	}
+}
+
+func (s *billScanner) readLine() (string, error) {
+	line, err := s.reader.ReadString('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	s.row++
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// next 返回下一条记录的起始行号以及原始内容，字段中含有换行时会合并多行
+func (s *billScanner) next() (int, string, error) {
+	for {
+		line, err := s.readLine()
+		if err != nil {
+			return 0, "", err
+		}
+		if strings.HasPrefix(line, "#") {
+			if s.onComment != nil {
+				s.onComment(s.row, line)
+			}
+			continue
+		}
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		row := s.row
+		for strings.Count(line, `"`)%2 == 1 {
+			more, err := s.readLine()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return 0, "", err
+			}
+			line += "\n" + more
+		}
+		return row, line, nil
+	}
+}
+
+func parseBillLine(line string) ([]string, error) {
+	csvReader := csv.NewReader(strings.NewReader(line))
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	record, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+	for idx, field := range record {
+		record[idx] = strings.TrimSuffix(field, "\t")
+	}
+	return record, nil
+}
+
+// billFileName 账单压缩包中的文件名一般是GBK编码
+func billFileName(file *zip.File) string {
+	if !file.NonUTF8 && strings.HasSuffix(file.Name, ".csv") {
+		return file.Name
+	}
+	name, err := GbkToUtf8([]byte(file.Name))
+	if err != nil {
+		return file.Name
+	}
+	return string(name)
+}
+
+// findBillFiles 查找指定种类的账单文件，例如kind为"业务明细"时匹配"2088xxx_20200101_业务明细.csv"，
+// 月账单或者拆分的大账单可能有多个文件，按文件名排序返回
+func findBillFiles(zipReader *zip.Reader, kind string) []*zip.File {
+	pattern := regexp.MustCompile(`(^|_)` + regexp.QuoteMeta(kind) + `(_\d+)?\.csv$`)
+	files := make([]*zip.File, 0)
+	for _, file := range zipReader.File {
+		if pattern.MatchString(path.Base(billFileName(file))) {
+			files = append(files, file)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return billFileName(files[i]) < billFileName(files[j])
+	})
+	return files
+}
+
+// billRowFunc 处理一行数据，返回*BillParseError表示该行数据有问题，只需要设置Err，其他错误会中止遍历
+type billRowFunc func(header, record []string) error
+
+// walkBillFile 流式解析账单文件，第一行非注释内容为表头，fn返回ErrStopBillWalk时原样返回
+func walkBillFile(file *zip.File, opts *billOptions, fn billRowFunc) error {
+	fileName := billFileName(file)
+	opts.meta.Files = append(opts.meta.Files, fileName)
+	opts.file++
+
+	fileReaderCloser, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer fileReaderCloser.Close()
+
+	decoder := transform.NewReader(fileReaderCloser, simplifiedchinese.GBK.NewDecoder())
+	scanner := newBillScanner(decoder, opts.meta.parseComment)
+
+	var (
+		header  []string
+		skipped BillParseErrors
+	)
+
+	for {
+		row, line, err := scanner.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("读取账单%s失败: %w", fileName, err)
+		}
+
+		record, err := parseBillLine(line)
+		if err == nil && strings.ContainsRune(line, utf8.RuneError) {
+			err = ErrBillInvalidGbk
+		}
+
+		if err != nil && header == nil {
+			// 表头解析失败时后面的行都无法对应到列，非严格模式也直接返回
+			return &BillParseError{File: fileName, Row: row, Line: line, Err: err}
+		}
+		if header == nil {
+			header = make([]string, len(record))
+			for idx, field := range record {
+				header[idx] = strings.TrimSpace(field)
+			}
+			continue
+		}
+
+		if err == nil {
+			err = fn(header, record)
+			if err == ErrStopBillWalk {
+				return err
+			}
+			if rowErr, ok := err.(*BillParseError); ok {
+				err = rowErr.Err
+			} else if err != nil {
+				return err
+			}
+		}
+
+		if err != nil {
+			rowErr := &BillParseError{File: fileName, Row: row, Line: line, Err: err}
+			if opts.strict {
+				return rowErr
+			}
+			skipped = append(skipped, rowErr)
+		}
+	}
+
+	if len(skipped) > 0 {
+		return skipped
+	}
+	return nil
+}
+
+// walkBill 在账单压缩包中找到指定种类的文件并依次流式解析
+func walkBill(r io.ReaderAt, size int64, kind string, opts *billOptions, fn billRowFunc) error {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	files := findBillFiles(zipReader, kind)
+	if len(files) == 0 {
+		return fmt.Errorf("%w: %s", ErrBillFileNotFound, kind)
+	}
+
+	var skipped BillParseErrors
+	for _, file := range files {
+		err := walkBillFile(file, opts, fn)
+		if err == ErrStopBillWalk {
+			break
+		}
+		if fileSkipped, ok := err.(BillParseErrors); ok {
+			skipped = append(skipped, fileSkipped...)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(skipped) > 0 {
+		return skipped
+	}
+	return nil
+}
+
+// WalkBillTrade 流式解析业务明细，不会把整个账单读入内存，r可以是*os.File或*bytes.Reader
+func (alipay *Alipay) WalkBillTrade(r io.ReaderAt, size int64, fn func(entry *BillTradeEntry) error, optionList ...BillOption) error {
+	opts := newBillOptions(optionList)
+	counter := newBillTotalCounter()
+	stopped := false
+
+	mapper := newBillColumnMapper(new(BillTradeEntry), opts)
+
+	err := walkBill(r, size, BillFileKindTrade, opts, func(header, record []string) error {
+		entry := new(BillTradeEntry)
+		if err := mapper.fill(header, record, entry); err != nil {
+			return err
+		}
+		if opts.verifyTotals {
+			if err := counter.add(entry.BusinessType, map[string]string{
+				BillTotalAmountNameReceipt:   entry.ReceiptAmount,
+				BillTotalAmountNameSellerOff: entry.SellerOff,
+			}); err != nil {
+				return err
+			}
+		}
+		if err := fn(entry); err != nil {
+			stopped = err == ErrStopBillWalk
+			return err
+		}
+		return nil
+	})
+	if err != nil || !opts.verifyTotals || stopped {
+		return err
+	}
+	return counter.verify(
+		opts.meta,
+		[]string{BillTotalNameTrade, BillTotalNameRefund},
+		[]string{BillTotalAmountNameReceipt, BillTotalAmountNameSellerOff},
+	)
+}
+
+// WalkBillSigncustomer 流式解析账务明细，不会把整个账单读入内存，r可以是*os.File或*bytes.Reader
+func (alipay *Alipay) WalkBillSigncustomer(r io.ReaderAt, size int64, fn func(entry *BillSigncustomerEntry) error, optionList ...BillOption) error {
+	opts := newBillOptions(optionList)
+	counter := newBillTotalCounter()
+	stopped := false
+
+	mapper := newBillColumnMapper(new(BillSigncustomerEntry), opts)
+
+	err := walkBill(r, size, BillFileKindSigncustomer, opts, func(header, record []string) error {
+		entry := new(BillSigncustomerEntry)
+		if err := mapper.fill(header, record, entry); err != nil {
+			return err
+		}
+		if opts.verifyTotals {
+			if err := counter.addSigncustomer(entry); err != nil {
+				return err
+			}
+		}
+		if err := fn(entry); err != nil {
+			stopped = err == ErrStopBillWalk
+			return err
+		}
+		return nil
+	})
+	if err != nil || !opts.verifyTotals || stopped {
+		return err
+	}
+	return counter.verify(
+		opts.meta,
+		[]string{BillTotalNameIncome, BillTotalNameExpense},
+		[]string{BillTotalAmountNameDefault},
+	)
+}
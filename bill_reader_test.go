@@ -0,0 +1,337 @@
+package alipay
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+const (
+	testBillTradeHeader        = "支付宝交易号,商户订单号,业务类型,商品名称,创建时间,完成时间,门店编号,门店名称,操作员,终端号,对方账户,订单金额（元）,商家实收（元）,支付宝红包（元）,集分宝（元）,支付宝优惠（元）,商家优惠（元）,券核销金额（元）,券名称,商家红包消费金额（元）,卡消费金额（元）,退款批次号/请求号,服务费（元）,分润（元）,备注"
+	testBillTradeSummaryHeader = "门店编号,门店名称,交易订单总笔数,退款订单总笔数,订单金额（元）,商家实收（元）,支付宝优惠（元）,商家优惠（元）,卡消费金额（元）,服务费（元）,分润（元）,实收净额（元）"
+	testBillSigncustomerHeader = "账务流水号,业务流水号,商户订单号,商品名称,发生时间,对方账号,收入金额（+元）,支出金额（-元）,账户余额（元）,交易渠道,业务类型,备注"
+)
+
+// testBillFile 账单压缩包中的一个文件
+type testBillFile struct {
+	name  string
+	lines []string
+}
+
+// testBillRow 按支付宝的格式在每个字段后面加制表符
+func testBillRow(fields ...string) string {
+	for idx, field := range fields {
+		field += "\t"
+		if strings.ContainsAny(field, ",\"") {
+			field = `"` + strings.Replace(field, `"`, `""`, -1) + `"`
+		}
+		fields[idx] = field
+	}
+	return strings.Join(fields, ",")
+}
+
+func testBillTradeRow(tradeNo, outTradeNo, businessType, amount string) string {
+	return testBillRow(
+		tradeNo, outTradeNo, businessType, "商品", "2020-01-02 10:00:00", "2020-01-02 10:00:05",
+		"", "", "", "", "buyer@example.com", amount, amount, "0.00", "0.00", "0.00", "0.00", "0.00", "",
+		"0.00", "0.00", "", "0.00", "0.00", "",
+	)
+}
+
+func testBillTradeLines() []string {
+	return []string{
+		"#支付宝业务明细查询",
+		"#账号：[20880000000000000156]",
+		"#起始日期：[2020年01月02日 00:00:00]   终止日期：[2020年01月03日 00:00:00]",
+		"#-----------------------------------------业务明细列表----------------------------------------",
+		testBillTradeHeader,
+		testBillTradeRow("2020010222001", "T001", CsvBusinessTypeTrade, "10.00"),
+		testBillTradeRow("2020010222002", "T002", CsvBusinessTypeTrade, "5.00"),
+		testBillTradeRow("2020010222001", "T001", CsvBusinessTypeRefund, "-5.00"),
+		"#-----------------------------------------业务明细列表结束------------------------------------",
+		"#交易合计：2笔，商家实收共：15.00元，商家优惠共：0.00元",
+		"#退款合计：1笔，商家实收退款共：-5.00元，商家优惠退款共：0.00元",
+		"#导出时间：[2020年01月03日 09:00:00]",
+	}
+}
+
+func testBillTradeSummaryLines() []string {
+	return []string{
+		"#支付宝业务汇总查询",
+		"#账号：[20880000000000000156]",
+		"#起始日期：[2020年01月02日 00:00:00]   终止日期：[2020年01月03日 00:00:00]",
+		"#-----------------------------------------业务汇总列表----------------------------------------",
+		testBillTradeSummaryHeader,
+		testBillRow("", "", "2", "1", "10.00", "10.00", "0.00", "0.00", "0.00", "0.00", "0.00", "10.00"),
+		testBillRow(BillSummaryTotalRow, "", "2", "1", "10.00", "10.00", "0.00", "0.00", "0.00", "0.00", "0.00", "10.00"),
+		"#-----------------------------------------业务汇总列表结束------------------------------------",
+		"#导出时间：[2020年01月03日 09:00:00]",
+	}
+}
+
+func testBillSigncustomerLines() []string {
+	return []string{
+		"#支付宝账务明细查询",
+		"#账号：[20880000000000000156]",
+		"#起始日期：[2020年01月02日 00:00:00]   终止日期：[2020年01月03日 00:00:00]",
+		"#-----------------------------------------账务明细列表----------------------------------------",
+		testBillSigncustomerHeader,
+		testBillRow("F001", "2020010222001", "T001", "商品", "2020-01-02 10:00:05", "buyer@example.com", "10.00", "0.00", "10.00", "支付宝", "在线支付", ""),
+		testBillRow("F002", "2020010222001", "T001", "商品", "2020-01-02 11:00:00", "buyer@example.com", "0.00", "-5.00", "5.00", "支付宝", "交易退款", "退款,备注"),
+		"#-----------------------------------------账务明细列表结束------------------------------------",
+		"#支出合计：1笔，共-5.00元",
+		"#收入合计：1笔，共10.00元",
+		"#导出时间：[2020年01月03日 09:00:00]",
+	}
+}
+
+// testBillZip 生成账单压缩包，文件名和内容都是GBK编码
+func testBillZip(t *testing.T, files ...testBillFile) []byte {
+	t.Helper()
+	encoder := simplifiedchinese.GBK.NewEncoder()
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for _, file := range files {
+		name, err := encoder.String(file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := encoder.String(strings.Join(file.lines, "\r\n") + "\r\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+		writer, err := zipWriter.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, NonUTF8: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testTradeBill(t *testing.T) []byte {
+	return testBillZip(t,
+		testBillFile{name: "20880000000000000156_20200102_业务明细.csv", lines: testBillTradeLines()},
+		testBillFile{name: "20880000000000000156_20200102_业务明细(汇总).csv", lines: testBillTradeSummaryLines()},
+	)
+}
+
+func TestWalkBillTrade(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	bill := testTradeBill(t)
+
+	entryList := make([]*BillTradeEntry, 0)
+	err := alipay.WalkBillTrade(bytes.NewReader(bill), int64(len(bill)), func(entry *BillTradeEntry) error {
+		entryList = append(entryList, entry)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entryList) != 3 {
+		t.Fatalf("entries = %d, want 3", len(entryList))
+	}
+	entry := entryList[2]
+	if entry.TradeNo != "2020010222001" || entry.OutTradeNo != "T001" || entry.BusinessType != CsvBusinessTypeRefund ||
+		entry.BuyerEmail != "buyer@example.com" || entry.TotalAmount != "-5.00" {
+		t.Fatalf("entry: %+v", entry)
+	}
+
+	count := 0
+	err = alipay.WalkBillTrade(bytes.NewReader(bill), int64(len(bill)), func(entry *BillTradeEntry) error {
+		count++
+		return ErrStopBillWalk
+	})
+	if err != nil || count != 1 {
+		t.Fatalf("count = %d, err = %v, want to stop after the first entry", count, err)
+	}
+}
+
+func TestBillTradeList(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	entryList, err := alipay.BillTradeList(testTradeBill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entryList) != 3 || entryList[0].OutTradeNo != "T001" || entryList[1].ReceiptAmount != "5.00" {
+		t.Fatalf("entries: %+v", entryList)
+	}
+
+	if _, err := alipay.BillSigncustomerList(testTradeBill(t)); !errors.Is(err, ErrBillFileNotFound) {
+		t.Fatalf("err = %v, want ErrBillFileNotFound", err)
+	}
+}
+
+func TestBillSigncustomerList(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	bill := testBillZip(t, testBillFile{name: "20880000000000000156_20200102_账务明细.csv", lines: testBillSigncustomerLines()})
+	entryList, err := alipay.BillSigncustomerList(bill)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entryList) != 2 {
+		t.Fatalf("entries = %d, want 2", len(entryList))
+	}
+	entry := entryList[1]
+	if entry.FundFlowID != "F002" || entry.ExpensesAmount != "-5.00" || entry.BusinessType != "交易退款" || entry.Remark != "退款,备注" {
+		t.Fatalf("entry: %+v", entry)
+	}
+}
+
+func TestBillStrict(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	lines := testBillTradeLines()
+	lines[6] = testBillRow("2020010222002", "T002", CsvBusinessTypeTrade, "5.00")
+	bill := testBillZip(t, testBillFile{name: "20880000000000000156_20200102_业务明细.csv", lines: lines})
+
+	entryList, err := alipay.BillTradeList(bill)
+	errs, ok := err.(BillParseErrors)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("err = %v, want one BillParseError", err)
+	}
+	if errs[0].Row != 7 || !errors.Is(errs[0], ErrBillFieldCount) || !strings.HasSuffix(errs[0].File, "业务明细.csv") {
+		t.Fatalf("err = %+v", errs[0])
+	}
+	if len(entryList) != 2 || entryList[1].BusinessType != CsvBusinessTypeRefund {
+		t.Fatalf("entries: %+v", entryList)
+	}
+
+	_, err = alipay.BillTradeList(bill, WithBillStrict())
+	parseErr, ok := err.(*BillParseError)
+	if !ok || parseErr.Row != 7 {
+		t.Fatalf("err = %v, want *BillParseError at row 7", err)
+	}
+}
+
+func TestBillInvalidGbk(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	writer, err := zipWriter.Create("20880000000000000156_20200102_业务明细.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := simplifiedchinese.GBK.NewEncoder().String(strings.Join(testBillTradeLines(), "\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 把第6行的第一个字节换成GBK中不合法的0xFF
+	lines := strings.Split(content, "\r\n")
+	lines[5] = "\xff" + lines[5][1:]
+	writer.Write([]byte(strings.Join(lines, "\r\n")))
+	zipWriter.Close()
+
+	entryList, err := alipay.BillTradeList(buf.Bytes())
+	errs, ok := err.(BillParseErrors)
+	if !ok || len(errs) != 1 || errs[0].Row != 6 || !errors.Is(errs[0], ErrBillInvalidGbk) {
+		t.Fatalf("err = %v, want ErrBillInvalidGbk at row 6", err)
+	}
+	if len(entryList) != 2 {
+		t.Fatalf("entries = %d, want 2", len(entryList))
+	}
+}
+
+func TestBillInvalidHeader(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	writer, err := zipWriter.Create("20880000000000000156_20200102_业务明细.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := simplifiedchinese.GBK.NewEncoder().String(strings.Join(testBillTradeLines(), "\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 第5行是表头
+	lines := strings.Split(content, "\r\n")
+	lines[4] = "\xff" + lines[4][1:]
+	writer.Write([]byte(strings.Join(lines, "\r\n")))
+	zipWriter.Close()
+
+	entryList, err := alipay.BillTradeList(buf.Bytes())
+	parseErr, ok := err.(*BillParseError)
+	if !ok || parseErr.Row != 5 || !errors.Is(parseErr, ErrBillInvalidGbk) {
+		t.Fatalf("err = %v, want *BillParseError at row 5 even without strict", err)
+	}
+	if len(entryList) != 0 {
+		t.Fatalf("entries = %d, want 0", len(entryList))
+	}
+}
+
+func TestBillMeta(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	meta := new(BillMeta)
+	if _, err := alipay.BillTradeList(testTradeBill(t), WithBillMeta(meta)); err != nil {
+		t.Fatal(err)
+	}
+	if meta.Account != "20880000000000000156" || meta.ExportTime != "2020年01月03日 09:00:00" {
+		t.Fatalf("meta: %+v", meta)
+	}
+	start, err := meta.StartTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	end, err := meta.EndTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if end.Sub(start) != 24*time.Hour {
+		t.Fatalf("start = %s, end = %s", start, end)
+	}
+	trade, refund := meta.Totals[BillTotalNameTrade], meta.Totals[BillTotalNameRefund]
+	if trade == nil || trade.Count != 2 || trade.Amounts[BillTotalAmountNameReceipt] != "15.00" {
+		t.Fatalf("trade total: %+v", trade)
+	}
+	if refund == nil || refund.Count != 1 || refund.Amounts["商家实收退款"] != "-5.00" {
+		t.Fatalf("refund total: %+v", refund)
+	}
+	if len(meta.Comments) != 8 {
+		t.Fatalf("comments = %d, want 8", len(meta.Comments))
+	}
+}
+
+func TestBillVerifyTotals(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	if _, err := alipay.BillTradeList(testTradeBill(t), WithBillVerifyTotals()); err != nil {
+		t.Fatal(err)
+	}
+	signcustomerBill := testBillZip(t, testBillFile{name: "20880000000000000156_20200102_账务明细.csv", lines: testBillSigncustomerLines()})
+	if _, err := alipay.BillSigncustomerList(signcustomerBill, WithBillVerifyTotals()); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]func(lines []string) []string{
+		"count": func(lines []string) []string {
+			return append(lines[:6], lines[7:]...)
+		},
+		"amount": func(lines []string) []string {
+			lines[9] = "#交易合计：2笔，商家实收共：16.00元，商家优惠共：0.00元"
+			return lines
+		},
+		"missing": func(lines []string) []string {
+			return append(lines[:10], lines[11:]...)
+		},
+	}
+	for name, rewrite := range cases {
+		t.Run(name, func(t *testing.T) {
+			bill := testBillZip(t, testBillFile{name: "20880000000000000156_20200102_业务明细.csv", lines: rewrite(testBillTradeLines())})
+			if _, err := alipay.BillTradeList(bill); err != nil {
+				t.Fatalf("totals should only be verified with WithBillVerifyTotals: %v", err)
+			}
+			if _, err := alipay.BillTradeList(bill, WithBillVerifyTotals()); !errors.Is(err, ErrBillTotalsMismatch) {
+				t.Fatalf("err = %v, want ErrBillTotalsMismatch", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package alipay
+
+import (
+	"fmt"
+	"io"
+)
+
+// LedgerRecord 我方账本中的一笔交易或退款
+type LedgerRecord struct {
+	BusinessType string // CsvBusinessTypeTrade或CsvBusinessTypeRefund
+	OutTradeNo   string // 商户订单号
+	OutRequestNo string // 退款请求号，交易为空
+	Amount       int64  // 交易为订单金额，退款为退款金额，单位分
+}
+
+// Ledger 我方账本，逐条返回需要对账的记录，没有更多记录时返回io.EOF
+type Ledger interface {
+	Next() (*LedgerRecord, error)
+}
+
+// LedgerRecordList 内存中的账本
+type LedgerRecordList struct {
+	list []*LedgerRecord
+}
+
+// NewLedgerRecordList ...
+func NewLedgerRecordList(list []*LedgerRecord) *LedgerRecordList {
+	return &LedgerRecordList{list: list}
+}
+
+// Next ...
+func (ledger *LedgerRecordList) Next() (*LedgerRecord, error) {
+	if len(ledger.list) == 0 {
+		return nil, io.EOF
+	}
+	record := ledger.list[0]
+	ledger.list = ledger.list[1:]
+	return record, nil
+}
+
+// BillReconcileItem 对账结果中的一笔，同一订单号（退款为订单号+退款请求号）的多条记录金额会合并
+type BillReconcileItem struct {
+	BusinessType string            `json:"business_type"`            // 交易、退款
+	OutTradeNo   string            `json:"out_trade_no"`             // 商户订单号
+	OutRequestNo string            `json:"out_request_no,omitempty"` // 退款请求号
+	LedgerAmount int64             `json:"ledger_amount"`            // 我方金额，单位分
+	BillAmount   int64             `json:"bill_amount"`              // 账单金额，单位分
+	Ledger       []*LedgerRecord   `json:"-"`
+	Bill         []*BillTradeEntry `json:"-"`
+}
+
+// BillReconcileSummary 对账合计，金额单位为分
+type BillReconcileSummary struct {
+	LedgerTradeCount   int   `json:"ledger_trade_count"`
+	LedgerTradeAmount  int64 `json:"ledger_trade_amount"`
+	LedgerRefundCount  int   `json:"ledger_refund_count"`
+	LedgerRefundAmount int64 `json:"ledger_refund_amount"`
+	BillTradeCount     int   `json:"bill_trade_count"`
+	BillTradeAmount    int64 `json:"bill_trade_amount"`
+	BillRefundCount    int   `json:"bill_refund_count"`
+	BillRefundAmount   int64 `json:"bill_refund_amount"`
+	BillReceiptAmount  int64 `json:"bill_receipt_amount"` // 商家实收，退款为负数
+	BillServiceFee     int64 `json:"bill_service_fee"`    // 服务费
+	BillRoyalty        int64 `json:"bill_royalty"`        // 分润
+}
+
+// BillReconcileReport 对账报告，可以直接json.Marshal
+type BillReconcileReport struct {
+	Matched          []*BillReconcileItem `json:"matched"`           // 两边一致
+	MissingInLedger  []*BillReconcileItem `json:"missing_in_ledger"` // 账单中有，我方没有
+	MissingInBill    []*BillReconcileItem `json:"missing_in_bill"`   // 我方有，账单中没有
+	AmountMismatches []*BillReconcileItem `json:"amount_mismatches"` // 交易金额不一致
+	RefundMismatches []*BillReconcileItem `json:"refund_mismatches"` // 退款金额不一致
+	Summary          BillReconcileSummary `json:"summary"`
+}
+
+// Balanced 除了两边一致的记录外没有其他差异
+func (report *BillReconcileReport) Balanced() bool {
+	return len(report.MissingInLedger) == 0 &&
+		len(report.MissingInBill) == 0 &&
+		len(report.AmountMismatches) == 0 &&
+		len(report.RefundMismatches) == 0
+}
+
+type billReconcileIndex struct {
+	keys  []string
+	items map[string]*BillReconcileItem
+}
+
+func newBillReconcileIndex() *billReconcileIndex {
+	return &billReconcileIndex{
+		items: make(map[string]*BillReconcileItem),
+	}
+}
+
+func (index *billReconcileIndex) get(businessType, outTradeNo, outRequestNo string) *BillReconcileItem {
+	if businessType != CsvBusinessTypeRefund {
+		outRequestNo = ""
+	}
+	key := businessType + "\x00" + outTradeNo + "\x00" + outRequestNo
+	item, ok := index.items[key]
+	if !ok {
+		item = &BillReconcileItem{
+			BusinessType: businessType,
+			OutTradeNo:   outTradeNo,
+			OutRequestNo: outRequestNo,
+		}
+		index.items[key] = item
+		index.keys = append(index.keys, key)
+	}
+	return item
+}
+
+func absCent(cent int64) int64 {
+	if cent < 0 {
+		return -cent
+	}
+	return cent
+}
+
+// parseBillCent 账单中空的金额按0处理
+func parseBillCent(price string) (int64, error) {
+	if len(price) == 0 {
+		return 0, nil
+	}
+	return Int64ifyCent(price)
+}
+
+// ReconcileBill 用业务明细核对我方账本。交易比较订单金额，退款比较订单金额的绝对值，
+// 账单中的退款行订单金额为负数
+func ReconcileBill(ledger Ledger, entryList []*BillTradeEntry) (*BillReconcileReport, error) {
+	report := new(BillReconcileReport)
+	summary := &report.Summary
+	index := newBillReconcileIndex()
+
+	for _, entry := range entryList {
+		if entry.BusinessType != CsvBusinessTypeTrade && entry.BusinessType != CsvBusinessTypeRefund {
+			continue
+		}
+		var amounts [4]int64
+		for idx, price := range []string{entry.TotalAmount, entry.ReceiptAmount, entry.Service, entry.Fr} {
+			cent, err := parseBillCent(price)
+			if err != nil {
+				return nil, fmt.Errorf("账单金额格式不正确 %s: %w", entry.OutTradeNo, err)
+			}
+			amounts[idx] = cent
+		}
+		amount := absCent(amounts[0])
+		summary.BillReceiptAmount += amounts[1]
+		summary.BillServiceFee += amounts[2]
+		summary.BillRoyalty += amounts[3]
+		if entry.BusinessType == CsvBusinessTypeTrade {
+			summary.BillTradeCount++
+			summary.BillTradeAmount += amount
+		} else {
+			summary.BillRefundCount++
+			summary.BillRefundAmount += amount
+		}
+
+		item := index.get(entry.BusinessType, entry.OutTradeNo, entry.OutRequestNo)
+		item.BillAmount += amount
+		item.Bill = append(item.Bill, entry)
+	}
+
+	for {
+		record, err := ledger.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch record.BusinessType {
+		case CsvBusinessTypeTrade:
+			summary.LedgerTradeCount++
+			summary.LedgerTradeAmount += record.Amount
+		case CsvBusinessTypeRefund:
+			summary.LedgerRefundCount++
+			summary.LedgerRefundAmount += record.Amount
+		default:
+			return nil, fmt.Errorf("账本记录的业务类型不正确 %s: %s", record.OutTradeNo, record.BusinessType)
+		}
+
+		item := index.get(record.BusinessType, record.OutTradeNo, record.OutRequestNo)
+		item.LedgerAmount += record.Amount
+		item.Ledger = append(item.Ledger, record)
+	}
+
+	for _, key := range index.keys {
+		item := index.items[key]
+		switch {
+		case len(item.Ledger) == 0:
+			report.MissingInLedger = append(report.MissingInLedger, item)
+		case len(item.Bill) == 0:
+			report.MissingInBill = append(report.MissingInBill, item)
+		case item.LedgerAmount == item.BillAmount:
+			report.Matched = append(report.Matched, item)
+		case item.BusinessType == CsvBusinessTypeRefund:
+			report.RefundMismatches = append(report.RefundMismatches, item)
+		default:
+			report.AmountMismatches = append(report.AmountMismatches, item)
+		}
+	}
+	return report, nil
+}
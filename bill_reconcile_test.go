@@ -0,0 +1,75 @@
+package alipay
+
+import "testing"
+
+func TestReconcileBill(t *testing.T) {
+	entryList := []*BillTradeEntry{
+		{OutTradeNo: "T001", BusinessType: CsvBusinessTypeTrade, TotalAmount: "10.00", ReceiptAmount: "10.00"},
+		{OutTradeNo: "T002", BusinessType: CsvBusinessTypeTrade, TotalAmount: "5.00", ReceiptAmount: "5.00"},
+		{OutTradeNo: "T003", BusinessType: CsvBusinessTypeTrade, TotalAmount: "1.00", ReceiptAmount: "1.00"},
+		{OutTradeNo: "T001", BusinessType: CsvBusinessTypeRefund, OutRequestNo: "R1", TotalAmount: "-2.00", ReceiptAmount: "-2.00"},
+		{OutTradeNo: "T001", BusinessType: CsvBusinessTypeRefund, OutRequestNo: "R2", TotalAmount: "-1.00", ReceiptAmount: "-1.00"},
+		{OutTradeNo: "T009", BusinessType: "其他", TotalAmount: "99.00"},
+	}
+	ledger := NewLedgerRecordList([]*LedgerRecord{
+		{BusinessType: CsvBusinessTypeTrade, OutTradeNo: "T001", Amount: 1000},
+		{BusinessType: CsvBusinessTypeTrade, OutTradeNo: "T002", Amount: 501},
+		{BusinessType: CsvBusinessTypeTrade, OutTradeNo: "T004", Amount: 100},
+		{BusinessType: CsvBusinessTypeRefund, OutTradeNo: "T001", OutRequestNo: "R1", Amount: 200},
+		{BusinessType: CsvBusinessTypeRefund, OutTradeNo: "T001", OutRequestNo: "R2", Amount: 200},
+	})
+
+	report, err := ReconcileBill(ledger, entryList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Balanced() {
+		t.Fatal("report should not be balanced")
+	}
+
+	keys := func(items []*BillReconcileItem) []string {
+		list := make([]string, 0, len(items))
+		for _, item := range items {
+			list = append(list, item.OutTradeNo+"/"+item.OutRequestNo)
+		}
+		return list
+	}
+	check := func(name string, items []*BillReconcileItem, want ...string) {
+		got := keys(items)
+		if len(got) != len(want) {
+			t.Errorf("%s = %v, want %v", name, got, want)
+			return
+		}
+		for idx := range want {
+			if got[idx] != want[idx] {
+				t.Errorf("%s = %v, want %v", name, got, want)
+				return
+			}
+		}
+	}
+	check("matched", report.Matched, "T001/", "T001/R1")
+	check("amount mismatches", report.AmountMismatches, "T002/")
+	check("refund mismatches", report.RefundMismatches, "T001/R2")
+	check("missing in ledger", report.MissingInLedger, "T003/")
+	check("missing in bill", report.MissingInBill, "T004/")
+
+	summary := report.Summary
+	if summary.BillTradeCount != 3 || summary.BillTradeAmount != 1600 || summary.BillRefundCount != 2 || summary.BillRefundAmount != 300 {
+		t.Errorf("bill summary: %+v", summary)
+	}
+	if summary.LedgerTradeCount != 3 || summary.LedgerTradeAmount != 1601 || summary.LedgerRefundAmount != 400 {
+		t.Errorf("ledger summary: %+v", summary)
+	}
+	if summary.BillReceiptAmount != 1300 {
+		t.Errorf("receipt = %d, want 1300", summary.BillReceiptAmount)
+	}
+}
+
+func TestReconcileBillInvalidLedger(t *testing.T) {
+	_, err := ReconcileBill(NewLedgerRecordList([]*LedgerRecord{
+		{BusinessType: "其他", OutTradeNo: "T001", Amount: 100},
+	}), nil)
+	if err == nil {
+		t.Fatal("unknown ledger business type should fail")
+	}
+}
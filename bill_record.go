@@ -0,0 +1,53 @@
+package alipay
+
+import (
+	"io"
+)
+
+// BillFileKind 账单压缩包中的文件种类
+const (
+	BillFileKindTrade               = "业务明细"
+	BillFileKindTradeSummary        = "业务明细(汇总)"
+	BillFileKindSigncustomer        = "账务明细"
+	BillFileKindSigncustomerSummary = "账务明细(汇总)"
+)
+
+// BillRecord 按表头解析的一行账单，用于没有专门结构体的账单类型，例如服务商账单、营销活动账单
+type BillRecord struct {
+	Header []string          // 表头
+	Values []string          // 按表头顺序排列的值
+	Fields map[string]string // 表头到值
+}
+
+// Get 按表头取值，表头中的全角括号、空白会被忽略
+func (record *BillRecord) Get(name string) string {
+	if value, ok := record.Fields[name]; ok {
+		return value
+	}
+	name = normBillHeader(name)
+	for idx, header := range record.Header {
+		if normBillHeader(header) == name {
+			return record.Values[idx]
+		}
+	}
+	return ""
+}
+
+// WalkBillRecord 流式解析任意种类的账单文件，kind为文件名中日期之后的部分，例如"业务明细"、"账务明细(汇总)"
+func (alipay *Alipay) WalkBillRecord(r io.ReaderAt, size int64, kind string, fn func(record *BillRecord) error, optionList ...BillOption) error {
+	return walkBill(r, size, kind, newBillOptions(optionList), func(header, record []string) error {
+		if len(record) != len(header) {
+			return &BillParseError{Err: ErrBillFieldCount}
+		}
+		billRecord := &BillRecord{
+			Header: header,
+			Values: make([]string, len(record)),
+			Fields: make(map[string]string, len(record)),
+		}
+		copy(billRecord.Values, record)
+		for idx, name := range header {
+			billRecord.Fields[name] = record[idx]
+		}
+		return fn(billRecord)
+	})
+}
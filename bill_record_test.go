@@ -0,0 +1,70 @@
+package alipay
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWalkBillTradeMonthly(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	second := testBillTradeLines()
+	for idx, line := range second {
+		switch line {
+		case "#起始日期：[2020年01月02日 00:00:00]   终止日期：[2020年01月03日 00:00:00]":
+			second[idx] = "#起始日期：[2020年01月03日 00:00:00]   终止日期：[2020年01月04日 00:00:00]"
+		}
+	}
+	bill := testBillZip(t,
+		testBillFile{name: "20880000000000000156_202001_业务明细_2.csv", lines: second},
+		testBillFile{name: "20880000000000000156_202001_业务明细_1.csv", lines: testBillTradeLines()},
+		testBillFile{name: "20880000000000000156_202001_业务明细(汇总).csv", lines: testBillTradeSummaryLines()},
+	)
+	meta := new(BillMeta)
+	entryList, err := alipay.BillTradeList(bill, WithBillMeta(meta), WithBillVerifyTotals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entryList) != 6 {
+		t.Fatalf("entries = %d, want 6", len(entryList))
+	}
+	if len(meta.Files) != 2 || meta.Files[0] != "20880000000000000156_202001_业务明细_1.csv" {
+		t.Fatalf("files: %v", meta.Files)
+	}
+	if meta.StartDate != "2020年01月02日 00:00:00" || meta.EndDate != "2020年01月04日 00:00:00" {
+		t.Fatalf("start = %s, end = %s", meta.StartDate, meta.EndDate)
+	}
+	if trade := meta.Totals[BillTotalNameTrade]; trade.Count != 4 || trade.Amounts[BillTotalAmountNameReceipt] != "30.00" {
+		t.Fatalf("trade total: %+v", trade)
+	}
+}
+
+func TestWalkBillRecord(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	bill := testTradeBill(t)
+	recordList := make([]*BillRecord, 0)
+	err := alipay.WalkBillRecord(bytes.NewReader(bill), int64(len(bill)), BillFileKindTradeSummary, func(record *BillRecord) error {
+		recordList = append(recordList, record)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recordList) != 2 {
+		t.Fatalf("records = %d, want 2", len(recordList))
+	}
+	if recordList[1].Get("门店编号") != BillSummaryTotalRow || recordList[1].Get("实收净额(元)") != "10.00" {
+		t.Fatalf("record: %+v", recordList[1])
+	}
+}
+
+func TestIsMonthlyBillDate(t *testing.T) {
+	for billDate, monthly := range map[string]bool{
+		"2020-01":    true,
+		"2020-01-02": false,
+		"202001":     false,
+	} {
+		if IsMonthlyBillDate(billDate) != monthly {
+			t.Errorf("IsMonthlyBillDate(%q) = %v", billDate, !monthly)
+		}
+	}
+}
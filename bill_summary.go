@@ -0,0 +1,109 @@
+package alipay
+
+import (
+	"bytes"
+	"io"
+)
+
+// BillSummaryTotalRow 汇总文件最后一行的门店编号或业务类型
+const (
+	BillSummaryTotalRow = "合计"
+)
+
+// BillTradeSummaryEntry 业务明细(汇总)中的一行
+type BillTradeSummaryEntry struct {
+	ShopNo         string `bill:"门店编号,required"`    // 门店编号
+	ShopName       string `bill:"门店名称"`             // 门店名称
+	TradeCount     string `bill:"交易订单总笔数,required"` // 交易订单总笔数
+	RefundCount    string `bill:"退款订单总笔数,required"` // 退款订单总笔数
+	TotalAmount    string `bill:"订单金额（元）"`          // 订单金额（元）
+	ReceiptAmount  string `bill:"商家实收（元）"`          // 商家实收（元）
+	AlipayOff      string `bill:"支付宝优惠（元）"`         // 支付宝优惠（元）
+	SellerOff      string `bill:"商家优惠（元）"`          // 商家优惠（元）
+	HandlingCharge string `bill:"卡消费金额（元）"`         // 卡消费金额（元）
+	Service        string `bill:"服务费（元）"`           // 服务费（元）
+	Fr             string `bill:"分润（元）"`            // 分润（元）
+	NetAmount      string `bill:"实收净额（元）"`          // 实收净额（元）
+
+	Extras map[string]string // 未知的列，key为表头
+}
+
+// IsTotal 是否为合计行
+func (entry *BillTradeSummaryEntry) IsTotal() bool {
+	return entry.ShopNo == BillSummaryTotalRow
+}
+
+// BillSigncustomerSummaryEntry 账务明细(汇总)中的一行
+type BillSigncustomerSummaryEntry struct {
+	BusinessType  string `bill:"业务类型,required"` // 业务类型
+	IncomeCount   string `bill:"收入笔数"`          // 收入笔数
+	IncomeAmount  string `bill:"收入金额（+元）"`      // 收入金额（+元）
+	ExpenseCount  string `bill:"支出笔数"`          // 支出笔数
+	ExpenseAmount string `bill:"支出金额（-元）"`      // 支出金额（-元）
+	TotalAmount   string `bill:"合计（元）"`         // 合计（元）
+
+	Extras map[string]string // 未知的列，key为表头
+}
+
+// IsTotal 是否为合计行
+func (entry *BillSigncustomerSummaryEntry) IsTotal() bool {
+	return entry.BusinessType == BillSummaryTotalRow
+}
+
+// WalkBillTradeSummary 解析业务明细(汇总).csv
+func (alipay *Alipay) WalkBillTradeSummary(r io.ReaderAt, size int64, fn func(entry *BillTradeSummaryEntry) error, optionList ...BillOption) error {
+	opts := newBillOptions(optionList)
+	mapper := newBillColumnMapper(new(BillTradeSummaryEntry), opts)
+	return walkBill(r, size, BillFileKindTradeSummary, opts, func(header, record []string) error {
+		entry := new(BillTradeSummaryEntry)
+		if err := mapper.fill(header, record, entry); err != nil {
+			return err
+		}
+		return fn(entry)
+	})
+}
+
+// WalkBillSigncustomerSummary 解析账务明细(汇总).csv
+func (alipay *Alipay) WalkBillSigncustomerSummary(r io.ReaderAt, size int64, fn func(entry *BillSigncustomerSummaryEntry) error, optionList ...BillOption) error {
+	opts := newBillOptions(optionList)
+	mapper := newBillColumnMapper(new(BillSigncustomerSummaryEntry), opts)
+	return walkBill(r, size, BillFileKindSigncustomerSummary, opts, func(header, record []string) error {
+		entry := new(BillSigncustomerSummaryEntry)
+		if err := mapper.fill(header, record, entry); err != nil {
+			return err
+		}
+		return fn(entry)
+	})
+}
+
+// BillTradeSummaryList 非严格模式下有行解析失败时，同时返回解析成功的汇总以及BillParseErrors
+func (alipay *Alipay) BillTradeSummaryList(bill []byte, optionList ...BillOption) ([]*BillTradeSummaryEntry, error) {
+	billTradeSummaryEntryList := make([]*BillTradeSummaryEntry, 0)
+	err := alipay.WalkBillTradeSummary(bytes.NewReader(bill), int64(len(bill)), func(entry *BillTradeSummaryEntry) error {
+		billTradeSummaryEntryList = append(billTradeSummaryEntryList, entry)
+		return nil
+	}, optionList...)
+	if _, ok := err.(BillParseErrors); ok {
+		return billTradeSummaryEntryList, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return billTradeSummaryEntryList, nil
+}
+
+// BillSigncustomerSummaryList 非严格模式下有行解析失败时，同时返回解析成功的汇总以及BillParseErrors
+func (alipay *Alipay) BillSigncustomerSummaryList(bill []byte, optionList ...BillOption) ([]*BillSigncustomerSummaryEntry, error) {
+	billSigncustomerSummaryEntryList := make([]*BillSigncustomerSummaryEntry, 0)
+	err := alipay.WalkBillSigncustomerSummary(bytes.NewReader(bill), int64(len(bill)), func(entry *BillSigncustomerSummaryEntry) error {
+		billSigncustomerSummaryEntryList = append(billSigncustomerSummaryEntryList, entry)
+		return nil
+	}, optionList...)
+	if _, ok := err.(BillParseErrors); ok {
+		return billSigncustomerSummaryEntryList, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return billSigncustomerSummaryEntryList, nil
+}
@@ -0,0 +1,40 @@
+package alipay
+
+import "testing"
+
+func TestBillTradeSummaryList(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	entryList, err := alipay.BillTradeSummaryList(testTradeBill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entryList) != 2 {
+		t.Fatalf("entries = %d, want 2", len(entryList))
+	}
+	if entryList[0].IsTotal() || !entryList[1].IsTotal() || entryList[1].TradeCount != "2" || entryList[1].NetAmount != "10.00" {
+		t.Fatalf("entries: %+v %+v", entryList[0], entryList[1])
+	}
+}
+
+func TestBillSigncustomerSummaryList(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	bill := testBillZip(t, testBillFile{name: "20880000000000000156_20200102_账务明细(汇总).csv", lines: []string{
+		"#支付宝账务汇总查询",
+		"#-----------------------------------------账务汇总列表----------------------------------------",
+		"业务类型,收入笔数,收入金额（+元）,支出笔数,支出金额（-元）,合计（元）",
+		testBillRow("在线支付", "1", "10.00", "0", "0.00", "10.00"),
+		testBillRow("交易退款", "0", "0.00", "1", "-5.00", "-5.00"),
+		testBillRow(BillSummaryTotalRow, "1", "10.00", "1", "-5.00", "5.00"),
+		"#-----------------------------------------账务汇总列表结束------------------------------------",
+	}})
+	entryList, err := alipay.BillSigncustomerSummaryList(bill)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entryList) != 3 || !entryList[2].IsTotal() || entryList[1].ExpenseAmount != "-5.00" {
+		t.Fatalf("entries: %+v", entryList)
+	}
+	if _, err := alipay.BillSigncustomerList(bill); err == nil {
+		t.Fatal("summary file should not be parsed as detail")
+	}
+}
@@ -21,10 +21,11 @@ type CancelResponse struct {
 }
 
 // Cancel ...
-func (alipay *Alipay) Cancel(param CancelParam) (int, *CancelResponse, error) {
+func (alipay *Alipay) Cancel(param CancelParam, fillList ...Fill) (int, *CancelResponse, error) {
 	statusCode, body, err := alipay.OnRequest(
 		param,
 		MethodAlipayTradeCancel,
+		fillList...,
 	)
 	if err != nil {
 		return 0, nil, err
@@ -0,0 +1,92 @@
+// Package alipay https://opendocs.alipay.com/common/02kdnc
+package alipay
+
+import (
+	"crypto/md5"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NewWithCert 公钥证书模式，appCertBytes为应用公钥证书，alipayCertBytes为支付宝公钥证书，alipayRootCertBytes为支付宝根证书
+func NewWithCert(appID string, privateBytes, appCertBytes, alipayCertBytes, alipayRootCertBytes []byte) (*Alipay, error) {
+	alipay := Alipay{
+		appID:  appID,
+		client: client,
+	}
+
+	var err error
+
+	alipay.PrivateKey, err = NewPrivateKey(privateBytes)
+	if err != nil {
+		return nil, fmt.Errorf("商户私钥构建失败: %w", err)
+	}
+
+	appCert, err := ParseCert(appCertBytes)
+	if err != nil {
+		return nil, fmt.Errorf("应用公钥证书解析失败: %w", err)
+	}
+	alipay.appCertSN = CertSN(appCert)
+
+	alipayCert, err := ParseCert(alipayCertBytes)
+	if err != nil {
+		return nil, fmt.Errorf("支付宝公钥证书解析失败: %w", err)
+	}
+	publicKey, ok := alipayCert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("支付宝公钥证书不是RSA公钥")
+	}
+	alipay.PublicKey = publicKey
+
+	alipay.rootCertSN, err = RootCertSN(alipayRootCertBytes)
+	if err != nil {
+		return nil, fmt.Errorf("支付宝根证书解析失败: %w", err)
+	}
+
+	return &alipay, nil
+}
+
+// ParseCert 解析PEM格式的证书
+func ParseCert(certBytes []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certBytes)
+	if block == nil {
+		return nil, errors.New("cert format error")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// CertSN 证书SN为md5(签发机构DN + 证书序列号)
+func CertSN(cert *x509.Certificate) string {
+	sum := md5.Sum([]byte(cert.Issuer.String() + cert.SerialNumber.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// RootCertSN 根证书包含多张证书，只取RSA签名的证书，SN用下划线连接。
+// 支付宝根证书中还有Go无法解析的SM2证书，解析失败的证书与其他算法的证书一样跳过
+func RootCertSN(rootCertBytes []byte) (string, error) {
+	snList := make([]string, 0)
+	rest := rootCertBytes
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		if cert.SignatureAlgorithm != x509.SHA1WithRSA && cert.SignatureAlgorithm != x509.SHA256WithRSA {
+			continue
+		}
+		snList = append(snList, CertSN(cert))
+	}
+	if len(snList) == 0 {
+		return "", errors.New("root cert format error: 没有可以解析的RSA证书")
+	}
+	return strings.Join(snList, "_"), nil
+}
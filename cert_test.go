@@ -0,0 +1,97 @@
+package alipay
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func testCertPEM(t *testing.T, serial int64, publicKey, privateKey crypto.PublicKey) []byte {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "Alipay Test Root"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, publicKey, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestRootCertSN(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaCertPEM := testCertPEM(t, 1, &rsaKey.PublicKey, rsaKey)
+	ecCertPEM := testCertPEM(t, 2, &ecKey.PublicKey, ecKey)
+
+	rsaCert, err := ParseCert(rsaCertPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 模拟Go无法解析的SM2证书
+	unparseablePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("sm2 certificate")})
+	rootCertPEM := append(append(append([]byte{}, unparseablePEM...), rsaCertPEM...), ecCertPEM...)
+	sn, err := RootCertSN(rootCertPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sn != CertSN(rsaCert) {
+		t.Fatalf("sn = %s, want %s", sn, CertSN(rsaCert))
+	}
+
+	if _, err := RootCertSN(append(append([]byte{}, unparseablePEM...), ecCertPEM...)); err == nil {
+		t.Fatal("root cert without RSA certificates should fail")
+	}
+}
+
+func TestNewWithCert(t *testing.T) {
+	_, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayFundAccountQuery, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{"available_amount": "100.00", "freeze_amount": "0.00"}
+	})
+	appCertPEM := testCertPEM(t, 1, &testAppKey.PublicKey, testAppKey)
+	alipayCertPEM := testCertPEM(t, 2, &testAlipayKey.PublicKey, testAlipayKey)
+	rootCertPEM := testCertPEM(t, 3, &testAlipayKey.PublicKey, testAlipayKey)
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(testAppKey)})
+
+	alipay, err := NewWithCert("2021000000000001", privatePEM, appCertPEM, alipayCertPEM, rootCertPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !alipay.IsCertMode() {
+		t.Fatal("client should be in cert mode")
+	}
+	alipay.client = &http.Client{Transport: gateway}
+
+	_, resp, err := alipay.FundAccountQuery(&FundAccountQueryParam{AlipayUserID: "2088000000000001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.AvailableAmount != "100.00" {
+		t.Fatalf("resp: %+v", resp)
+	}
+	appCert, _ := ParseCert(appCertPEM)
+	rootCert, _ := ParseCert(rootCertPEM)
+	req := gateway.sent(MethodAlipayFundAccountQuery)[0]
+	if req.Values.Get("app_cert_sn") != CertSN(appCert) || req.Values.Get("alipay_root_cert_sn") != CertSN(rootCert) {
+		t.Fatalf("cert sn were not sent: %v", req.Values)
+	}
+}
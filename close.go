@@ -19,10 +19,11 @@ type CloseResponse struct {
 }
 
 // Close ...
-func (alipay *Alipay) Close(param *CloseParam) (int, *CloseResponse, error) {
+func (alipay *Alipay) Close(param *CloseParam, fillList ...Fill) (int, *CloseResponse, error) {
 	statusCode, body, err := alipay.OnRequest(
 		param,
 		MethodAlipayTradeClose,
+		fillList...,
 	)
 	if err != nil {
 		return 0, nil, err
@@ -23,20 +23,20 @@ type CreateParamExtendParams struct {
 
 // CreateParam ...
 type CreateParam struct {
-	OutTradeNo         string                  `json:"out_trade_no"`        // 商户订单号
-	SellerID           string                  `json:"seller_id"`           // 卖家支付宝用户ID
-	TotalAmount        string                  `json:"total_amount"`        // 订单总金额
-	DiscountableAmount string                  `json:"discountable_amount"` // 参与优惠计算的金额
-	Subject            string                  `json:"subject"`             // 订单标题
-	Body               string                  `json:"body"`                // 订单描述
-	BuyerID            string                  `json:"buyer_id"`            // 买家的支付宝用户id
-	GoodsDetailList    []*CreateParamGoods     `json:" goods_detail"`       // 订单包含的商品列表信息
-	OperatorID         string                  `json:"operator_id"`         // 商户操作员编号
-	StoreID            string                  `json:"store_id"`            // 商户门店编号
-	TerminalID         string                  `json:"terminal_id"`         // 商户机具终端编号
-	ExtendParams       CreateParamExtendParams `json:"extend_params"`       // 业务扩展参数
-	TimeoutExpress     string                  `json:"timeout_express"`     // 该笔订单允许的最晚付款时间
-	BusinessParams     string                  `json:"business_params"`     // 商户传入业务信息
+	OutTradeNo         string                  `json:"out_trade_no"`           // 商户订单号
+	SellerID           string                  `json:"seller_id"`              // 卖家支付宝用户ID
+	TotalAmount        string                  `json:"total_amount"`           // 订单总金额
+	DiscountableAmount string                  `json:"discountable_amount"`    // 参与优惠计算的金额
+	Subject            string                  `json:"subject"`                // 订单标题
+	Body               string                  `json:"body"`                   // 订单描述
+	BuyerID            string                  `json:"buyer_id"`               // 买家的支付宝用户id
+	GoodsDetailList    []*CreateParamGoods     `json:"goods_detail,omitempty"` // 订单包含的商品列表信息
+	OperatorID         string                  `json:"operator_id"`            // 商户操作员编号
+	StoreID            string                  `json:"store_id"`               // 商户门店编号
+	TerminalID         string                  `json:"terminal_id"`            // 商户机具终端编号
+	ExtendParams       CreateParamExtendParams `json:"extend_params"`          // 业务扩展参数
+	TimeoutExpress     string                  `json:"timeout_express"`        // 该笔订单允许的最晚付款时间
+	BusinessParams     string                  `json:"business_params"`        // 商户传入业务信息
 }
 
 // CreateResponse ...
@@ -47,10 +47,11 @@ type CreateResponse struct {
 }
 
 // Create ...
-func (alipay *Alipay) Create(param *CreateParam) (int, *CreateResponse, error) {
+func (alipay *Alipay) Create(param *CreateParam, fillList ...Fill) (int, *CreateResponse, error) {
 	statusCode, body, err := alipay.OnRequest(
 		param,
 		MethodAlipayTradeCreate,
+		fillList...,
 	)
 	if err != nil {
 		return 0, nil, err
@@ -0,0 +1,40 @@
+package alipay
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DataBillTimeLayout alipay.data.bill.*接口中起止时间的格式
+const DataBillTimeLayout = "2006-01-02 15:04:05"
+
+// DataBillTime ...
+func DataBillTime(t time.Time) string {
+	return t.Format(DataBillTimeLayout)
+}
+
+// DataBillPage alipay.data.bill.*接口的分页信息，支付宝以字符串返回
+type DataBillPage struct {
+	PageNo    string `json:"page_no"`    // 分页号，从1开始
+	PageSize  string `json:"page_size"`  // 分页大小
+	TotalSize string `json:"total_size"` // 账单明细总数
+}
+
+// HasNextPage ...
+func (page *DataBillPage) HasNextPage() bool {
+	pageNo, _ := strconv.Atoi(page.PageNo)
+	pageSize, _ := strconv.Atoi(page.PageSize)
+	totalSize, _ := strconv.Atoi(page.TotalSize)
+	return pageNo > 0 && pageSize > 0 && pageNo*pageSize < totalSize
+}
+
+// NextPageNo ...
+func (page *DataBillPage) NextPageNo() string {
+	pageNo, _ := strconv.Atoi(page.PageNo)
+	return strconv.Itoa(pageNo + 1)
+}
+
+func dataBillResponseError(method string, resp ResponseError) error {
+	return fmt.Errorf("%s请求失败: %s %s %s %s", method, resp.Code, resp.Msg, resp.SubCode, resp.SubMsg)
+}
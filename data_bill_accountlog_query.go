@@ -0,0 +1,95 @@
+// Package alipay https://opendocs.alipay.com/apis/api_15/alipay.data.bill.accountlog.query
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// DataBillAccountlogQueryParam ...
+type DataBillAccountlogQueryParam struct {
+	StartTime       string `json:"start_time"`                  // 账务流水创建时间的起始范围，格式为yyyy-MM-dd HH:mm:ss
+	EndTime         string `json:"end_time"`                    // 账务流水创建时间的结束范围，与起始时间间隔不超过31天
+	AlipayOrderNo   string `json:"alipay_order_no,omitempty"`   // 支付宝订单号
+	MerchantOrderNo string `json:"merchant_order_no,omitempty"` // 商户订单号
+	PageNo          string `json:"page_no,omitempty"`           // 分页号，从1开始
+	PageSize        string `json:"page_size,omitempty"`         // 分页大小1000-2000，默认2000
+	TransCode       string `json:"trans_code,omitempty"`        // 账务的类型代码，多个用英文逗号分隔
+	BillUserID      string `json:"bill_user_id,omitempty"`      // 目标查询账户，默认为当前商户
+}
+
+// DataBillAccountlogItem 账务明细
+type DataBillAccountlogItem struct {
+	TransDt         string `json:"trans_dt"`          // 入账时间
+	AccountLogID    string `json:"account_log_id"`    // 支付宝账务流水号
+	AlipayOrderNo   string `json:"alipay_order_no"`   // 支付宝订单号
+	MerchantOrderNo string `json:"merchant_order_no"` // 商户订单号
+	TransAmount     string `json:"trans_amount"`      // 金额，单位元
+	Balance         string `json:"balance"`           // 余额，单位元
+	Type            string `json:"type"`              // 账务记录的类型
+	OtherAccount    string `json:"other_account"`     // 对方账户
+	TransMemo       string `json:"trans_memo"`        // 收入/支出备注
+	Direction       string `json:"direction"`         // 收入/支出
+	BillSource      string `json:"bill_source"`       // 业务账单来源
+	BizNos          string `json:"biz_nos"`           // 业务订单号，多个用|分隔
+	BizOrigNo       string `json:"biz_orig_no"`       // 业务基础订单号
+	BizDesc         string `json:"biz_desc"`          // 业务描述
+	StoreName       string `json:"store_name"`        // 门店名称
+}
+
+// DataBillAccountlogQueryResponse ...
+type DataBillAccountlogQueryResponse struct {
+	ResponseError
+	DataBillPage
+	DetailList []*DataBillAccountlogItem `json:"detail_list"` // 账务明细列表
+}
+
+// DataBillAccountlogQuery ...
+func (alipay *Alipay) DataBillAccountlogQuery(param *DataBillAccountlogQueryParam, fillList ...Fill) (int, *DataBillAccountlogQueryResponse, error) {
+	if len(param.StartTime) == 0 || len(param.EndTime) == 0 {
+		return 0, nil, errors.New("起止时间不能为空")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayDataBillAccountlogQuery,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	dataBillAccountlogQueryResponse := new(DataBillAccountlogQueryResponse)
+	if err := json.Unmarshal(body, dataBillAccountlogQueryResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, dataBillAccountlogQueryResponse, nil
+}
+
+// WalkDataBillAccountlog 从param.PageNo开始逐页查询账务明细，fn返回ErrStopBillWalk时停止
+func (alipay *Alipay) WalkDataBillAccountlog(param *DataBillAccountlogQueryParam, fn func(item *DataBillAccountlogItem) error, fillList ...Fill) error {
+	pageParam := *param
+	if len(pageParam.PageNo) == 0 {
+		pageParam.PageNo = "1"
+	}
+	for {
+		_, resp, err := alipay.DataBillAccountlogQuery(&pageParam, fillList...)
+		if err != nil {
+			return err
+		}
+		if !resp.Success() {
+			return dataBillResponseError(MethodAlipayDataBillAccountlogQuery, resp.ResponseError)
+		}
+		for _, item := range resp.DetailList {
+			if err := fn(item); err != nil {
+				if errors.Is(err, ErrStopBillWalk) {
+					return nil
+				}
+				return err
+			}
+		}
+		if len(resp.DetailList) == 0 || !resp.HasNextPage() {
+			return nil
+		}
+		pageParam.PageNo = resp.NextPageNo()
+	}
+}
@@ -0,0 +1,41 @@
+// Package alipay https://opendocs.alipay.com/apis/api_15/alipay.data.bill.balance.query
+package alipay
+
+import (
+	"encoding/json"
+)
+
+// DataBillBalanceQueryParam ...
+type DataBillBalanceQueryParam struct {
+	BillUserID string `json:"bill_user_id,omitempty"` // 目标查询账户，默认为当前商户
+}
+
+// DataBillBalanceQueryResponse ...
+type DataBillBalanceQueryResponse struct {
+	ResponseError
+	TotalAmount     string `json:"total_amount"`     // 支付宝账户余额，单位元
+	AvailableAmount string `json:"available_amount"` // 账户可用余额，单位元
+	FreezeAmount    string `json:"freeze_amount"`    // 冻结金额，单位元
+	SettleAmount    string `json:"settle_amount"`    // 待结算金额，单位元
+}
+
+// DataBillBalanceQuery ...
+func (alipay *Alipay) DataBillBalanceQuery(param *DataBillBalanceQueryParam, fillList ...Fill) (int, *DataBillBalanceQueryResponse, error) {
+	if param == nil {
+		param = new(DataBillBalanceQueryParam)
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayDataBillBalanceQuery,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	dataBillBalanceQueryResponse := new(DataBillBalanceQueryResponse)
+	if err := json.Unmarshal(body, dataBillBalanceQueryResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, dataBillBalanceQueryResponse, nil
+}
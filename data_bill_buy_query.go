@@ -0,0 +1,74 @@
+// Package alipay https://opendocs.alipay.com/apis/api_15/alipay.data.bill.buy.query
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// DataBillBuyQueryParam ...
+type DataBillBuyQueryParam struct {
+	StartTime       string `json:"start_time"`                  // 订单创建时间的起始范围，格式为yyyy-MM-dd HH:mm:ss
+	EndTime         string `json:"end_time"`                    // 订单创建时间的结束范围，与起始时间间隔不超过31天
+	AlipayOrderNo   string `json:"alipay_order_no,omitempty"`   // 支付宝订单号
+	MerchantOrderNo string `json:"merchant_order_no,omitempty"` // 商户订单号
+	PageNo          string `json:"page_no,omitempty"`           // 分页号，从1开始
+	PageSize        string `json:"page_size,omitempty"`         // 分页大小1000-2000，默认2000
+}
+
+// DataBillBuyQueryResponse ...
+type DataBillBuyQueryResponse struct {
+	ResponseError
+	DataBillPage
+	DetailList []*DataBillTradeItem `json:"detail_list"` // 交易流水详情
+}
+
+// DataBillBuyQuery ...
+func (alipay *Alipay) DataBillBuyQuery(param *DataBillBuyQueryParam, fillList ...Fill) (int, *DataBillBuyQueryResponse, error) {
+	if len(param.StartTime) == 0 || len(param.EndTime) == 0 {
+		return 0, nil, errors.New("起止时间不能为空")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayDataBillBuyQuery,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	dataBillBuyQueryResponse := new(DataBillBuyQueryResponse)
+	if err := json.Unmarshal(body, dataBillBuyQueryResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, dataBillBuyQueryResponse, nil
+}
+
+// WalkDataBillBuy 从param.PageNo开始逐页查询支出交易明细，fn返回ErrStopBillWalk时停止
+func (alipay *Alipay) WalkDataBillBuy(param *DataBillBuyQueryParam, fn func(item *DataBillTradeItem) error, fillList ...Fill) error {
+	pageParam := *param
+	if len(pageParam.PageNo) == 0 {
+		pageParam.PageNo = "1"
+	}
+	for {
+		_, resp, err := alipay.DataBillBuyQuery(&pageParam, fillList...)
+		if err != nil {
+			return err
+		}
+		if !resp.Success() {
+			return dataBillResponseError(MethodAlipayDataBillBuyQuery, resp.ResponseError)
+		}
+		for _, item := range resp.DetailList {
+			if err := fn(item); err != nil {
+				if errors.Is(err, ErrStopBillWalk) {
+					return nil
+				}
+				return err
+			}
+		}
+		if len(resp.DetailList) == 0 || !resp.HasNextPage() {
+			return nil
+		}
+		pageParam.PageNo = resp.NextPageNo()
+	}
+}
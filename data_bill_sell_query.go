@@ -0,0 +1,94 @@
+// Package alipay https://opendocs.alipay.com/apis/api_15/alipay.data.bill.sell.query
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// DataBillSellQueryParam ...
+type DataBillSellQueryParam struct {
+	StartTime       string `json:"start_time"`                  // 订单创建时间的起始范围，格式为yyyy-MM-dd HH:mm:ss
+	EndTime         string `json:"end_time"`                    // 订单创建时间的结束范围，与起始时间间隔不超过31天
+	AlipayOrderNo   string `json:"alipay_order_no,omitempty"`   // 支付宝订单号
+	MerchantOrderNo string `json:"merchant_order_no,omitempty"` // 商户订单号
+	StoreNo         string `json:"store_no,omitempty"`          // 门店编号
+	PageNo          string `json:"page_no,omitempty"`           // 分页号，从1开始
+	PageSize        string `json:"page_size,omitempty"`         // 分页大小1000-2000，默认2000
+}
+
+// DataBillTradeItem 交易明细，收入与支出查询共用
+type DataBillTradeItem struct {
+	GmtCreate       string `json:"gmt_create"`        // 交易创建时间
+	GmtPay          string `json:"gmt_pay"`           // 交易支付时间
+	GmtRefund       string `json:"gmt_refund"`        // 交易退款时间
+	AlipayOrderNo   string `json:"alipay_order_no"`   // 支付宝订单号
+	MerchantOrderNo string `json:"merchant_order_no"` // 商户订单号
+	GoodsTitle      string `json:"goods_title"`       // 商品名称
+	TotalAmount     string `json:"total_amount"`      // 订单金额，单位元
+	RefundAmount    string `json:"refund_amount"`     // 累计退款金额，单位元
+	ServiceFee      string `json:"service_fee"`       // 服务费，单位元
+	TradeStatus     string `json:"trade_status"`      // 订单状态
+	TradeType       string `json:"trade_type"`        // 订单类型
+	StoreNo         string `json:"store_no"`          // 门店编号
+	StoreName       string `json:"store_name"`        // 门店名称
+	OtherAccount    string `json:"other_account"`     // 对方账户
+	GoodsMemo       string `json:"goods_memo"`        // 商品备注信息
+}
+
+// DataBillSellQueryResponse ...
+type DataBillSellQueryResponse struct {
+	ResponseError
+	DataBillPage
+	DetailList []*DataBillTradeItem `json:"detail_list"` // 交易流水详情
+}
+
+// DataBillSellQuery ...
+func (alipay *Alipay) DataBillSellQuery(param *DataBillSellQueryParam, fillList ...Fill) (int, *DataBillSellQueryResponse, error) {
+	if len(param.StartTime) == 0 || len(param.EndTime) == 0 {
+		return 0, nil, errors.New("起止时间不能为空")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayDataBillSellQuery,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	dataBillSellQueryResponse := new(DataBillSellQueryResponse)
+	if err := json.Unmarshal(body, dataBillSellQueryResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, dataBillSellQueryResponse, nil
+}
+
+// WalkDataBillSell 从param.PageNo开始逐页查询收入交易明细，fn返回ErrStopBillWalk时停止
+func (alipay *Alipay) WalkDataBillSell(param *DataBillSellQueryParam, fn func(item *DataBillTradeItem) error, fillList ...Fill) error {
+	pageParam := *param
+	if len(pageParam.PageNo) == 0 {
+		pageParam.PageNo = "1"
+	}
+	for {
+		_, resp, err := alipay.DataBillSellQuery(&pageParam, fillList...)
+		if err != nil {
+			return err
+		}
+		if !resp.Success() {
+			return dataBillResponseError(MethodAlipayDataBillSellQuery, resp.ResponseError)
+		}
+		for _, item := range resp.DetailList {
+			if err := fn(item); err != nil {
+				if errors.Is(err, ErrStopBillWalk) {
+					return nil
+				}
+				return err
+			}
+		}
+		if len(resp.DetailList) == 0 || !resp.HasNextPage() {
+			return nil
+		}
+		pageParam.PageNo = resp.NextPageNo()
+	}
+}
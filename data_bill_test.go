@@ -0,0 +1,76 @@
+package alipay
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestWalkDataBillAccountlog(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayDataBillAccountlogQuery, func(req *testRequest) map[string]interface{} {
+		pageNo, _ := strconv.Atoi(req.biz("page_no"))
+		detailList := make([]map[string]interface{}, 0)
+		for i := 0; i < 2 && (pageNo-1)*2+i < 3; i++ {
+			detailList = append(detailList, map[string]interface{}{"account_log_id": strconv.Itoa((pageNo-1)*2 + i)})
+		}
+		return map[string]interface{}{"page_no": req.biz("page_no"), "page_size": "2", "total_size": "3", "detail_list": detailList}
+	})
+
+	param := &DataBillAccountlogQueryParam{StartTime: "2020-01-01 00:00:00", EndTime: "2020-01-02 00:00:00"}
+	idList := make([]string, 0)
+	err := alipay.WalkDataBillAccountlog(param, func(item *DataBillAccountlogItem) error {
+		idList = append(idList, item.AccountLogID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idList) != 3 || idList[2] != "2" {
+		t.Fatalf("ids: %v", idList)
+	}
+	if len(param.PageNo) != 0 {
+		t.Fatal("param should not be modified")
+	}
+
+	err = alipay.WalkDataBillAccountlog(param, func(item *DataBillAccountlogItem) error {
+		return ErrStopBillWalk
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := len(gateway.sent(MethodAlipayDataBillAccountlogQuery)); count != 3 {
+		t.Fatalf("sent %d requests, want 3", count)
+	}
+
+	if _, _, err := alipay.DataBillAccountlogQuery(&DataBillAccountlogQueryParam{}); err == nil {
+		t.Fatal("query without time range should be rejected")
+	}
+}
+
+func TestWalkDataBillSellError(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayDataBillSellQuery, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{"code": "40004", "msg": "Business Failed", "sub_code": "isp.bill_not_exist"}
+	})
+	err := alipay.WalkDataBillSell(&DataBillSellQueryParam{StartTime: "2020-01-01 00:00:00", EndTime: "2020-01-02 00:00:00"}, func(item *DataBillTradeItem) error {
+		t.Fatal("no item expected")
+		return nil
+	})
+	if err == nil {
+		t.Fatal("business error should be returned")
+	}
+}
+
+func TestDataBillBalanceQuery(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayDataBillBalanceQuery, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{"total_amount": "100.00", "available_amount": "90.00", "freeze_amount": "10.00"}
+	})
+	_, resp, err := alipay.DataBillBalanceQuery(&DataBillBalanceQueryParam{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success() || resp.AvailableAmount != "90.00" {
+		t.Fatalf("resp: %+v", resp)
+	}
+}
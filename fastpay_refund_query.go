@@ -39,10 +39,11 @@ func (resp *FastpayRefundQueryResponse) IsTradeNotExist() bool {
 }
 
 // FastpayRefundQuery ...
-func (alipay *Alipay) FastpayRefundQuery(param *FastpayRefundQueryParam) (int, *FastpayRefundQueryResponse, error) {
+func (alipay *Alipay) FastpayRefundQuery(param *FastpayRefundQueryParam, fillList ...Fill) (int, *FastpayRefundQueryResponse, error) {
 	statusCode, body, err := alipay.OnRequest(
 		param,
 		MethodAlipayTradeFastpayRefundQuery,
+		fillList...,
 	)
 	if err != nil {
 		return 0, nil, err
@@ -0,0 +1,50 @@
+// Package alipay https://opendocs.alipay.com/apis/api_28/alipay.fund.account.query
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// FundAccountTypeAcctrans 基本户
+const (
+	FundAccountTypeAcctrans = "ACCTRANS_ACCOUNT"
+)
+
+// FundAccountQueryParam ...
+type FundAccountQueryParam struct {
+	AlipayUserID string `json:"alipay_user_id"`         // 支付宝会员id
+	AccountType  string `json:"account_type,omitempty"` // 查询的账户类型，默认为ACCTRANS_ACCOUNT
+}
+
+// FundAccountQueryResponse ...
+type FundAccountQueryResponse struct {
+	ResponseError
+	AvailableAmount string `json:"available_amount"` // 账户可用余额，单位元
+	FreezeAmount    string `json:"freeze_amount"`    // 冻结金额，单位元
+}
+
+// FundAccountQuery ...
+func (alipay *Alipay) FundAccountQuery(param *FundAccountQueryParam, fillList ...Fill) (int, *FundAccountQueryResponse, error) {
+	if len(param.AlipayUserID) == 0 {
+		return 0, nil, errors.New("支付宝会员id不能为空")
+	}
+
+	if len(param.AccountType) == 0 {
+		param.AccountType = FundAccountTypeAcctrans
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayFundAccountQuery,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	fundAccountQueryResponse := new(FundAccountQueryResponse)
+	if err := json.Unmarshal(body, fundAccountQueryResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, fundAccountQueryResponse, nil
+}
@@ -0,0 +1,90 @@
+package alipay
+
+import (
+	"net/url"
+)
+
+// NotifyTypeFundAuthFreeze ...
+const (
+	NotifyTypeFundAuthFreeze   = "fund_auth_freeze"
+	NotifyTypeFundAuthUnfreeze = "fund_auth_unfreeze"
+)
+
+// ParseFundAuthAsyncResponse 解析资金授权冻结、解冻的异步通知
+func (alipay *Alipay) ParseFundAuthAsyncResponse(values url.Values) (*FundAuthAsyncResponse, error) {
+	if err := alipay.asyncVerifyRequest(values); err != nil {
+		return nil, ErrAsyncVerify
+	}
+
+	fundAuthAsyncResponse := new(FundAuthAsyncResponse)
+
+	fundAuthAsyncResponse.NotifyTime = values.Get("notify_time")
+	fundAuthAsyncResponse.NotifyType = values.Get("notify_type")
+	fundAuthAsyncResponse.NotifyID = values.Get("notify_id")
+	fundAuthAsyncResponse.AppID = values.Get("app_id")
+	fundAuthAsyncResponse.Charset = values.Get("charset")
+	fundAuthAsyncResponse.Version = values.Get("version")
+	fundAuthAsyncResponse.SignType = values.Get("sign_type")
+	fundAuthAsyncResponse.Sign = values.Get("sign")
+	fundAuthAsyncResponse.AuthNo = values.Get("auth_no")
+	fundAuthAsyncResponse.OutOrderNo = values.Get("out_order_no")
+	fundAuthAsyncResponse.OperationID = values.Get("operation_id")
+	fundAuthAsyncResponse.OutRequestNo = values.Get("out_request_no")
+	fundAuthAsyncResponse.OperationType = values.Get("operation_type")
+	fundAuthAsyncResponse.Amount = values.Get("amount")
+	fundAuthAsyncResponse.Status = values.Get("status")
+	fundAuthAsyncResponse.GmtCreate = values.Get("gmt_create")
+	fundAuthAsyncResponse.GmtTrans = values.Get("gmt_trans")
+	fundAuthAsyncResponse.PayerLogonID = values.Get("payer_logon_id")
+	fundAuthAsyncResponse.PayerUserID = values.Get("payer_user_id")
+	fundAuthAsyncResponse.PayeeLogonID = values.Get("payee_logon_id")
+	fundAuthAsyncResponse.PayeeUserID = values.Get("payee_user_id")
+	fundAuthAsyncResponse.TotalFreezeAmount = values.Get("total_freeze_amount")
+	fundAuthAsyncResponse.TotalUnfreezeAmount = values.Get("total_unfreeze_amount")
+	fundAuthAsyncResponse.TotalPayAmount = values.Get("total_pay_amount")
+	fundAuthAsyncResponse.RestAmount = values.Get("rest_amount")
+	fundAuthAsyncResponse.CreditAmount = values.Get("credit_amount")
+	fundAuthAsyncResponse.FundAmount = values.Get("fund_amount")
+	fundAuthAsyncResponse.PreAuthType = values.Get("pre_auth_type")
+	fundAuthAsyncResponse.TransCurrency = values.Get("trans_currency")
+
+	return fundAuthAsyncResponse, nil
+}
+
+// FundAuthAsyncResponse ...
+type FundAuthAsyncResponse struct {
+	NotifyTime          string
+	NotifyType          string
+	NotifyID            string
+	AppID               string
+	Charset             string
+	Version             string
+	SignType            string
+	Sign                string
+	AuthNo              string
+	OutOrderNo          string
+	OperationID         string
+	OutRequestNo        string
+	OperationType       string
+	Amount              string
+	Status              string
+	GmtCreate           string
+	GmtTrans            string
+	PayerLogonID        string
+	PayerUserID         string
+	PayeeLogonID        string
+	PayeeUserID         string
+	TotalFreezeAmount   string
+	TotalUnfreezeAmount string
+	TotalPayAmount      string
+	RestAmount          string
+	CreditAmount        string
+	FundAmount          string
+	PreAuthType         string
+	TransCurrency       string
+}
+
+// IsFreezeSuccess ...
+func (resp *FundAuthAsyncResponse) IsFreezeSuccess() bool {
+	return resp.OperationType == FundAuthOperationTypeFreeze && resp.Status == FundAuthOperationStatusSuccess
+}
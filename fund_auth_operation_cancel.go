@@ -0,0 +1,51 @@
+// Package alipay https://opendocs.alipay.com/apis/api_28/alipay.fund.auth.operation.cancel
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// FundAuthOperationCancelParam auth_no和out_order_no二选一，operation_id和out_request_no二选一
+type FundAuthOperationCancelParam struct {
+	AuthNo       string `json:"auth_no,omitempty"`        // 支付宝授权资金订单号
+	OutOrderNo   string `json:"out_order_no,omitempty"`   // 商户的授权资金订单号
+	OperationID  string `json:"operation_id,omitempty"`   // 支付宝的授权资金操作流水号
+	OutRequestNo string `json:"out_request_no,omitempty"` // 商户的授权资金操作流水号
+	Remark       string `json:"remark"`                   // 商户对本次撤销操作的附言描述
+}
+
+// FundAuthOperationCancelResponse ...
+type FundAuthOperationCancelResponse struct {
+	ResponseError
+	AuthNo       string `json:"auth_no"`        // 支付宝资金授权订单号
+	OutOrderNo   string `json:"out_order_no"`   // 商户的授权资金订单号
+	OperationID  string `json:"operation_id"`   // 支付宝资金操作流水号
+	OutRequestNo string `json:"out_request_no"` // 商户本次资金操作的请求流水号
+	Action       string `json:"action"`         // 本次撤销触发的资金动作，close表示关闭授权单，unfreeze表示解冻
+}
+
+// FundAuthOperationCancel 只能撤销冻结操作，一般用于冻结超时后结果不确定时
+func (alipay *Alipay) FundAuthOperationCancel(param *FundAuthOperationCancelParam, fillList ...Fill) (int, *FundAuthOperationCancelResponse, error) {
+	if len(param.AuthNo) == 0 && len(param.OutOrderNo) == 0 {
+		return 0, nil, errors.New("支付宝资金授权订单号和商户授权资金订单号不能同时为空")
+	}
+
+	if len(param.OperationID) == 0 && len(param.OutRequestNo) == 0 {
+		return 0, nil, errors.New("支付宝资金操作流水号和商户资金操作流水号不能同时为空")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayFundAuthOperationCancel,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	fundAuthOperationCancelResponse := new(FundAuthOperationCancelResponse)
+	if err := json.Unmarshal(body, fundAuthOperationCancelResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, fundAuthOperationCancelResponse, nil
+}
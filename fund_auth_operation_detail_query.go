@@ -0,0 +1,84 @@
+// Package alipay https://opendocs.alipay.com/apis/api_28/alipay.fund.auth.operation.detail.query
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// FundAuthOperationDetailQueryParam auth_no和out_order_no二选一，operation_id和out_request_no二选一
+type FundAuthOperationDetailQueryParam struct {
+	AuthNo       string `json:"auth_no,omitempty"`        // 支付宝授权资金订单号
+	OutOrderNo   string `json:"out_order_no,omitempty"`   // 商户的授权资金订单号
+	OperationID  string `json:"operation_id,omitempty"`   // 支付宝的授权资金操作流水号
+	OutRequestNo string `json:"out_request_no,omitempty"` // 商户的授权资金操作流水号
+}
+
+// FundAuthOperationDetailQueryResponse ...
+type FundAuthOperationDetailQueryResponse struct {
+	ResponseError
+	AuthNo                  string `json:"auth_no"`                    // 支付宝资金授权订单号
+	OutOrderNo              string `json:"out_order_no"`               // 商户的授权资金订单号
+	OrderStatus             string `json:"order_status"`               // 授权单状态，INIT、AUTHORIZED、FINISH、CLOSED
+	TotalFreezeAmount       string `json:"total_freeze_amount"`        // 订单累计的冻结金额，单位为：元（人民币）
+	RestAmount              string `json:"rest_amount"`                // 订单总共剩余的冻结金额，单位为：元（人民币）
+	TotalPayAmount          string `json:"total_pay_amount"`           // 订单累计用于支付的金额，单位为：元（人民币）
+	OrderTitle              string `json:"order_title"`                // 业务订单的简单描述
+	PayerLogonID            string `json:"payer_logon_id"`             // 付款方支付宝账号登录号
+	PayerUserID             string `json:"payer_user_id"`              // 付款方支付宝账号UID
+	ExtraParam              string `json:"extra_param"`                // 商户请求创建预授权订单时传入的扩展参数
+	OperationID             string `json:"operation_id"`               // 支付宝资金操作流水号
+	OutRequestNo            string `json:"out_request_no"`             // 商户资金操作的请求流水号
+	Amount                  string `json:"amount"`                     // 该笔资金操作流水opertion_id对应的操作金额
+	OperationType           string `json:"operation_type"`             // 支付宝资金操作类型，FREEZE、UNFREEZE、PAY
+	Status                  string `json:"status"`                     // 资金操作流水的状态，INIT、SUCCESS、CLOSED
+	Remark                  string `json:"remark"`                     // 商户对本次操作的附言描述
+	GmtCreate               string `json:"gmt_create"`                 // 资金授权单据操作流水创建时间
+	GmtTrans                string `json:"gmt_trans"`                  // 支付宝账务处理成功时间
+	PreAuthType             string `json:"pre_auth_type"`              // 预授权类型，CREDIT_AUTH表示信用预授权
+	TransCurrency           string `json:"trans_currency"`             // 标价币种
+	TotalFreezeCreditAmount string `json:"total_freeze_credit_amount"` // 累计冻结信用金额
+	TotalFreezeFundAmount   string `json:"total_freeze_fund_amount"`   // 累计冻结自有资金金额
+	TotalPayCreditAmount    string `json:"total_pay_credit_amount"`    // 累计支付信用金额
+	TotalPayFundAmount      string `json:"total_pay_fund_amount"`      // 累计支付自有资金金额
+	RestCreditAmount        string `json:"rest_credit_amount"`         // 剩余冻结信用金额
+	RestFundAmount          string `json:"rest_fund_amount"`           // 剩余冻结自有资金金额
+	CreditAmount            string `json:"credit_amount"`              // 该笔资金操作流水中信用金额
+	FundAmount              string `json:"fund_amount"`                // 该笔资金操作流水中自有资金金额
+}
+
+// IsAuthorized 资金已冻结，可以转支付或解冻
+func (resp *FundAuthOperationDetailQueryResponse) IsAuthorized() bool {
+	return resp.Success() && resp.OrderStatus == FundAuthOrderStatusAuthorized
+}
+
+// IsOperationSuccess ...
+func (resp *FundAuthOperationDetailQueryResponse) IsOperationSuccess() bool {
+	return resp.Success() && resp.Status == FundAuthOperationStatusSuccess
+}
+
+// IsAuthOrderNotExist ...
+func (resp *FundAuthOperationDetailQueryResponse) IsAuthOrderNotExist() bool {
+	return resp.SubCode == "AUTH_ORDER_NOT_EXIST" || resp.SubCode == "AUTH_OPERATION_NOT_EXIST"
+}
+
+// FundAuthOperationDetailQuery ...
+func (alipay *Alipay) FundAuthOperationDetailQuery(param *FundAuthOperationDetailQueryParam, fillList ...Fill) (int, *FundAuthOperationDetailQueryResponse, error) {
+	if len(param.AuthNo) == 0 && len(param.OutOrderNo) == 0 {
+		return 0, nil, errors.New("支付宝资金授权订单号和商户授权资金订单号不能同时为空")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayFundAuthOperationDetailQuery,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	fundAuthOperationDetailQueryResponse := new(FundAuthOperationDetailQueryResponse)
+	if err := json.Unmarshal(body, fundAuthOperationDetailQueryResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, fundAuthOperationDetailQueryResponse, nil
+}
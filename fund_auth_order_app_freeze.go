@@ -0,0 +1,63 @@
+// Package alipay https://opendocs.alipay.com/apis/api_28/alipay.fund.auth.order.app.freeze
+package alipay
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// FundAuthOrderAppFreezeParam ...
+type FundAuthOrderAppFreezeParam struct {
+	OutOrderNo         string `json:"out_order_no"`                   // 商户授权资金订单号，不能包含除中文、英文、数字以外的字符，创建后不能修改，需要保证在商户端不重复
+	OutRequestNo       string `json:"out_request_no"`                 // 商户本次资金操作的请求流水号，用于标识请求流水的唯一性
+	OrderTitle         string `json:"order_title"`                    // 业务订单的简单描述，如商品名称等
+	Amount             string `json:"amount"`                         // 需要冻结的金额，单位为：元（人民币），精确到小数点后两位
+	ProductCode        string `json:"product_code"`                   // 销售产品码，线上预授权固定为PRE_AUTH_ONLINE
+	PayeeLogonID       string `json:"payee_logon_id,omitempty"`       // 收款方支付宝账号（Email或手机号）
+	PayeeUserID        string `json:"payee_user_id,omitempty"`        // 收款方的支付宝唯一用户号，以2088开头的16位纯数字组成
+	PayTimeout         string `json:"pay_timeout,omitempty"`          // 该笔订单允许的最晚付款时间，逾期将关闭该笔订单，取值范围：1m～15d
+	TimeExpress        string `json:"time_express,omitempty"`         // 预授权订单相对超时时间，从商户请求时间开始计算
+	ExtraParam         string `json:"extra_param,omitempty"`          // 业务扩展参数，信用服务需传入category和serviceId，Json格式
+	EnablePayChannels  string `json:"enable_pay_channels,omitempty"`  // 商户可用该参数指定用户可使用的支付渠道，Json格式
+	DepositProductMode string `json:"deposit_product_mode,omitempty"` // 免押受理台模式，POSTPAY表示后付金额已知，POSTPAY_UNCERTAIN表示后付金额未知，DEPOSIT_ONLY表示纯免押
+	BusinessParams     string `json:"business_params,omitempty"`      // 商户传入业务信息，Json格式
+}
+
+// FundAuthOrderAppFreeze 返回给客户端唤起支付宝的参数，资金冻结结果以异步通知为准，见ParseFundAuthAsyncResponse
+func (alipay *Alipay) FundAuthOrderAppFreeze(param *FundAuthOrderAppFreezeParam, notifyURL string, fillList ...Fill) (string, error) {
+	if len(param.ProductCode) == 0 {
+		param.ProductCode = FundAuthProductCodePreAuthOnline
+	}
+
+	if len(param.OutOrderNo) == 0 {
+		return "", errors.New("商户授权资金订单号不能为空")
+	}
+
+	if len(param.OutRequestNo) == 0 {
+		return "", errors.New("商户资金操作流水号不能为空")
+	}
+
+	if len(param.OrderTitle) == 0 {
+		return "", errors.New("订单标题不能为空")
+	}
+
+	if len(param.Amount) == 0 {
+		return "", errors.New("冻结金额不能为空")
+	}
+
+	paramStr, err := alipay.MakeParam(
+		param,
+		MethodAlipayFundAuthOrderAppFreeze,
+		append([]Fill{WithNotifyURL(notifyURL)}, fillList...)...,
+	)
+	if err != nil {
+		return "", fmt.Errorf("支付宝资金授权冻结构造参数失败: %w", err)
+	}
+	url, err := url.Parse(alipay.Gateway())
+	if err != nil {
+		return "", fmt.Errorf("解析支付宝网关失败: %w", err)
+	}
+	url.RawQuery = paramStr
+	return url.String(), nil
+}
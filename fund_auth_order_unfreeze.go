@@ -0,0 +1,64 @@
+// Package alipay https://opendocs.alipay.com/apis/api_28/alipay.fund.auth.order.unfreeze
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// FundAuthOrderUnfreezeParam ...
+type FundAuthOrderUnfreezeParam struct {
+	AuthNo       string `json:"auth_no"`               // 支付宝资金授权订单号
+	OutRequestNo string `json:"out_request_no"`        // 商户本次资金操作的请求流水号，同一商户每次不同的资金操作请求，商户请求流水号不要重复
+	Amount       string `json:"amount"`                // 本次操作解冻的金额，单位为：元（人民币），精确到小数点后两位
+	Remark       string `json:"remark"`                // 商户对本次解冻操作的附言描述
+	ExtraParam   string `json:"extra_param,omitempty"` // 解冻扩展信息，信用服务需传入unfreezeBizInfo，Json格式
+}
+
+// FundAuthOrderUnfreezeResponse ...
+type FundAuthOrderUnfreezeResponse struct {
+	ResponseError
+	AuthNo       string `json:"auth_no"`        // 支付宝资金授权订单号
+	OutOrderNo   string `json:"out_order_no"`   // 商户的授权资金订单号
+	OperationID  string `json:"operation_id"`   // 支付宝资金操作流水号
+	OutRequestNo string `json:"out_request_no"` // 商户本次资金操作的请求流水号
+	Amount       string `json:"amount"`         // 本次解冻操作中信用解冻金额与自有资金解冻金额之和
+	Status       string `json:"status"`         // 资金操作流水的状态，INIT、SUCCESS、CLOSED
+	GmtTrans     string `json:"gmt_trans"`      // 授权资金解冻成功时间
+	CreditAmount string `json:"credit_amount"`  // 本次解冻操作中信用解冻金额
+	FundAmount   string `json:"fund_amount"`    // 本次解冻操作中自有资金解冻金额
+}
+
+// IsSuccess ...
+func (resp *FundAuthOrderUnfreezeResponse) IsSuccess() bool {
+	return resp.Success() && resp.Status == FundAuthOperationStatusSuccess
+}
+
+// FundAuthOrderUnfreeze ...
+func (alipay *Alipay) FundAuthOrderUnfreeze(param *FundAuthOrderUnfreezeParam, fillList ...Fill) (int, *FundAuthOrderUnfreezeResponse, error) {
+	if len(param.AuthNo) == 0 {
+		return 0, nil, errors.New("支付宝资金授权订单号不能为空")
+	}
+
+	if len(param.OutRequestNo) == 0 {
+		return 0, nil, errors.New("商户资金操作流水号不能为空")
+	}
+
+	if len(param.Amount) == 0 {
+		return 0, nil, errors.New("解冻金额不能为空")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayFundAuthOrderUnfreeze,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	fundAuthOrderUnfreezeResponse := new(FundAuthOrderUnfreezeResponse)
+	if err := json.Unmarshal(body, fundAuthOrderUnfreezeResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, fundAuthOrderUnfreezeResponse, nil
+}
@@ -0,0 +1,50 @@
+package alipay
+
+// FundAuthOrderStatus 授权单状态
+const (
+	// FundAuthOrderStatusInit 初始
+	FundAuthOrderStatusInit = "INIT"
+
+	// FundAuthOrderStatusAuthorized 已授权，资金已冻结
+	FundAuthOrderStatusAuthorized = "AUTHORIZED"
+
+	// FundAuthOrderStatusFinish 完成，冻结资金已全部解冻或转支付
+	FundAuthOrderStatusFinish = "FINISH"
+
+	// FundAuthOrderStatusClosed 关闭
+	FundAuthOrderStatusClosed = "CLOSED"
+)
+
+// FundAuthOperationStatus 资金操作流水状态
+const (
+	// FundAuthOperationStatusInit 初始
+	FundAuthOperationStatusInit = "INIT"
+
+	// FundAuthOperationStatusSuccess 成功
+	FundAuthOperationStatusSuccess = "SUCCESS"
+
+	// FundAuthOperationStatusClosed 关闭
+	FundAuthOperationStatusClosed = "CLOSED"
+)
+
+// FundAuthOperationType 资金操作类型
+const (
+	FundAuthOperationTypeFreeze   = "FREEZE"
+	FundAuthOperationTypeUnfreeze = "UNFREEZE"
+	FundAuthOperationTypePay      = "PAY"
+)
+
+// FundAuth ...
+const (
+	// FundAuthProductCodePreAuthOnline 线上预授权产品码
+	FundAuthProductCodePreAuthOnline = "PRE_AUTH_ONLINE"
+
+	// FundAuthProductCodePreAuth 线下预授权产品码
+	FundAuthProductCodePreAuth = "PRE_AUTH"
+
+	// AuthConfirmModeComplete 转交易支付完成后自动解冻剩余冻结金额
+	AuthConfirmModeComplete = "COMPLETE"
+
+	// AuthConfirmModeNotComplete 转交易支付完成后不解冻剩余冻结金额
+	AuthConfirmModeNotComplete = "NOT_COMPLETE"
+)
@@ -0,0 +1,201 @@
+package alipay
+
+import (
+	"encoding/base64"
+	"net/url"
+	"testing"
+)
+
+func TestFundAuthOrderAppFreeze(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	param := &FundAuthOrderAppFreezeParam{
+		OutOrderNo:   "O001",
+		OutRequestNo: "R001",
+		OrderTitle:   "押金",
+		Amount:       "100.00",
+	}
+	rawURL, err := alipay.FundAuthOrderAppFreeze(param, "https://example.com/notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := u.Query()
+	if values.Get("method") != MethodAlipayFundAuthOrderAppFreeze || values.Get("notify_url") != "https://example.com/notify" {
+		t.Fatalf("url = %s", rawURL)
+	}
+	if param.ProductCode != FundAuthProductCodePreAuthOnline {
+		t.Fatalf("product_code = %q, want %q", param.ProductCode, FundAuthProductCodePreAuthOnline)
+	}
+	toVerifyValues := url.Values{}
+	for key, list := range values {
+		if key != "sign" {
+			toVerifyValues[key] = list
+		}
+	}
+	sign, _ := base64.StdEncoding.DecodeString(values.Get("sign"))
+	if err := Verify(&testAppKey.PublicKey, []byte(NormValues(toVerifyValues)), sign); err != nil {
+		t.Fatalf("freeze url sign: %v", err)
+	}
+
+	for _, param := range []*FundAuthOrderAppFreezeParam{
+		{OutRequestNo: "R001", OrderTitle: "押金", Amount: "100.00"},
+		{OutOrderNo: "O001", OrderTitle: "押金", Amount: "100.00"},
+		{OutOrderNo: "O001", OutRequestNo: "R001", Amount: "100.00"},
+		{OutOrderNo: "O001", OutRequestNo: "R001", OrderTitle: "押金"},
+	} {
+		if _, err := alipay.FundAuthOrderAppFreeze(param, ""); err == nil {
+			t.Errorf("param %+v should be rejected", param)
+		}
+	}
+}
+
+func TestFundAuthOrderUnfreeze(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayFundAuthOrderUnfreeze, func(req *testRequest) map[string]interface{} {
+		if req.biz("auth_no") != "A001" {
+			return map[string]interface{}{"code": "40004", "msg": "Business Failed", "sub_code": "AUTH_ORDER_NOT_EXIST"}
+		}
+		return map[string]interface{}{
+			"auth_no":        req.biz("auth_no"),
+			"out_request_no": req.biz("out_request_no"),
+			"amount":         req.biz("amount"),
+			"status":         FundAuthOperationStatusSuccess,
+		}
+	})
+
+	_, resp, err := alipay.FundAuthOrderUnfreeze(&FundAuthOrderUnfreezeParam{AuthNo: "A001", OutRequestNo: "R002", Amount: "100.00", Remark: "归还押金"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsSuccess() || resp.Amount != "100.00" {
+		t.Fatalf("resp: %+v", resp)
+	}
+
+	_, resp, err = alipay.FundAuthOrderUnfreeze(&FundAuthOrderUnfreezeParam{AuthNo: "A002", OutRequestNo: "R002", Amount: "100.00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.IsSuccess() || resp.SubCode != "AUTH_ORDER_NOT_EXIST" {
+		t.Fatalf("resp: %+v", resp)
+	}
+
+	if _, _, err := alipay.FundAuthOrderUnfreeze(&FundAuthOrderUnfreezeParam{AuthNo: "A001", OutRequestNo: "R002"}); err == nil {
+		t.Fatal("unfreeze without amount should be rejected")
+	}
+}
+
+func TestFundAuthOperationDetailQuery(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayFundAuthOperationDetailQuery, func(req *testRequest) map[string]interface{} {
+		if req.biz("out_order_no") != "O001" {
+			return map[string]interface{}{"code": "40004", "msg": "Business Failed", "sub_code": "AUTH_ORDER_NOT_EXIST"}
+		}
+		return map[string]interface{}{
+			"auth_no":        "A001",
+			"out_order_no":   "O001",
+			"order_status":   FundAuthOrderStatusAuthorized,
+			"operation_type": FundAuthOperationTypeFreeze,
+			"status":         FundAuthOperationStatusSuccess,
+			"rest_amount":    "100.00",
+		}
+	})
+
+	_, resp, err := alipay.FundAuthOperationDetailQuery(&FundAuthOperationDetailQueryParam{OutOrderNo: "O001", OutRequestNo: "R001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsAuthorized() || !resp.IsOperationSuccess() || resp.IsAuthOrderNotExist() {
+		t.Fatalf("resp: %+v", resp)
+	}
+
+	_, resp, err = alipay.FundAuthOperationDetailQuery(&FundAuthOperationDetailQueryParam{OutOrderNo: "O002"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.IsAuthorized() || !resp.IsAuthOrderNotExist() {
+		t.Fatalf("resp: %+v", resp)
+	}
+
+	if _, _, err := alipay.FundAuthOperationDetailQuery(&FundAuthOperationDetailQueryParam{OutRequestNo: "R001"}); err == nil {
+		t.Fatal("query without auth_no or out_order_no should be rejected")
+	}
+}
+
+func TestFundAuthOperationCancel(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayFundAuthOperationCancel, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{"out_order_no": req.biz("out_order_no"), "out_request_no": req.biz("out_request_no"), "action": "close"}
+	})
+
+	_, resp, err := alipay.FundAuthOperationCancel(&FundAuthOperationCancelParam{OutOrderNo: "O001", OutRequestNo: "R001", Remark: "冻结超时"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success() || resp.Action != "close" {
+		t.Fatalf("resp: %+v", resp)
+	}
+
+	for _, param := range []*FundAuthOperationCancelParam{
+		{OutRequestNo: "R001"},
+		{OutOrderNo: "O001"},
+	} {
+		if _, _, err := alipay.FundAuthOperationCancel(param); err == nil {
+			t.Errorf("param %+v should be rejected", param)
+		}
+	}
+	if count := len(gateway.sent(MethodAlipayFundAuthOperationCancel)); count != 1 {
+		t.Fatalf("invalid params should not be sent, sent %d requests", count)
+	}
+}
+
+func TestPayFundAuth(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayTradePay, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{"out_trade_no": req.biz("out_trade_no"), "trade_no": "2020010122001", "total_amount": req.biz("total_amount")}
+	})
+
+	_, resp, err := alipay.PayFundAuth(&PayParam{OutTradeNo: "T001", AuthNo: "A001", SellerID: "2088000000000002", Subject: "租金", TotalAmount: "30.00"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success() || resp.TradeNo != "2020010122001" {
+		t.Fatalf("resp: %+v", resp)
+	}
+	req := gateway.sent(MethodAlipayTradePay)[0]
+	if req.biz("auth_no") != "A001" || req.biz("product_code") != FundAuthProductCodePreAuthOnline || req.biz("auth_confirm_mode") != AuthConfirmModeComplete ||
+		req.biz("seller_id") != "2088000000000002" {
+		t.Fatalf("biz_content: %v", req.Biz)
+	}
+
+	if _, _, err := alipay.PayFundAuth(&PayParam{OutTradeNo: "T002", TotalAmount: "30.00"}, ""); err == nil {
+		t.Fatal("pay without auth_no should be rejected")
+	}
+}
+
+func TestParseFundAuthAsyncResponse(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	values := testNotification(t, url.Values{
+		"notify_type":    {NotifyTypeFundAuthFreeze},
+		"auth_no":        {"A001"},
+		"out_order_no":   {"O001"},
+		"operation_type": {FundAuthOperationTypeFreeze},
+		"status":         {FundAuthOperationStatusSuccess},
+		"amount":         {"100.00"},
+	})
+
+	resp, err := alipay.ParseFundAuthAsyncResponse(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsFreezeSuccess() || resp.AuthNo != "A001" || resp.Amount != "100.00" {
+		t.Fatalf("resp: %+v", resp)
+	}
+
+	values.Set("amount", "1000.00")
+	if _, err := alipay.ParseFundAuthAsyncResponse(values); err != ErrAsyncVerify {
+		t.Fatalf("err = %v, want ErrAsyncVerify", err)
+	}
+}
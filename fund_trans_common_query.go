@@ -0,0 +1,75 @@
+// Package alipay https://opendocs.alipay.com/apis/api_28/alipay.fund.trans.common.query
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// FundTransCommonQueryParam ...
+type FundTransCommonQueryParam struct {
+	ProductCode    string `json:"product_code,omitempty"`      // 销售产品码，与转账时传入的一致
+	BizScene       string `json:"biz_scene,omitempty"`         // 描述特定的业务场景，与转账时传入的一致
+	OutBizNo       string `json:"out_biz_no,omitempty"`        // 商户转账唯一订单号，与order_id、pay_fund_order_id不能同时为空
+	OrderID        string `json:"order_id,omitempty"`          // 支付宝转账单据号
+	PayFundOrderID string `json:"pay_fund_order_id,omitempty"` // 支付宝支付资金流水号
+}
+
+// FundTransCommonQueryResponse ...
+type FundTransCommonQueryResponse struct {
+	ResponseError
+	OrderID          string `json:"order_id"`           // 支付宝转账单据号
+	PayFundOrderID   string `json:"pay_fund_order_id"`  // 支付宝支付资金流水号
+	OutBizNo         string `json:"out_biz_no"`         // 商户订单号
+	TransAmount      string `json:"trans_amount"`       // 付款金额，单位为元
+	Status           string `json:"status"`             // 转账单据状态，SUCCESS、DEALING、FAIL、REFUND、CLOSED等
+	PayDate          string `json:"pay_date"`           // 支付时间
+	ArrivalTimeEnd   string `json:"arrival_time_end"`   // 预计到账时间，转账到银行卡时返回
+	OrderFee         string `json:"order_fee"`          // 预计收费金额（元），转账到银行卡时返回
+	ErrorCode        string `json:"error_code"`         // 查询到的订单状态为FAIL时返回错误代码
+	FailReason       string `json:"fail_reason"`        // 查询到的订单状态为FAIL时返回具体的原因
+	DeductBillInfo   string `json:"deduct_bill_info"`   // 商户查询代发订单信息时返回其在代发前的扣款信息
+	TransferBillInfo string `json:"transfer_bill_info"` // 商户在查询代发订单信息时返回其在代发前的转账信息
+	SubStatus        string `json:"sub_status"`         // 转账单据子状态
+}
+
+// IsSuccess ...
+func (resp *FundTransCommonQueryResponse) IsSuccess() bool {
+	return resp.Success() && resp.Status == FundTransStatusSuccess
+}
+
+// IsDealing ...
+func (resp *FundTransCommonQueryResponse) IsDealing() bool {
+	return resp.Success() && (resp.Status == FundTransStatusDealing || resp.Status == FundTransStatusWaitPay)
+}
+
+// IsFail 退票和关闭也视为失败
+func (resp *FundTransCommonQueryResponse) IsFail() bool {
+	return resp.Success() && (resp.Status == FundTransStatusFail || resp.Status == FundTransStatusRefund || resp.Status == FundTransStatusClosed)
+}
+
+// IsOrderNotExist ...
+func (resp *FundTransCommonQueryResponse) IsOrderNotExist() bool {
+	return resp.SubCode == "ORDER_NOT_EXIST"
+}
+
+// FundTransCommonQuery ...
+func (alipay *Alipay) FundTransCommonQuery(param *FundTransCommonQueryParam, fillList ...Fill) (int, *FundTransCommonQueryResponse, error) {
+	if len(param.OutBizNo) == 0 && len(param.OrderID) == 0 && len(param.PayFundOrderID) == 0 {
+		return 0, nil, errors.New("商户转账订单号和支付宝转账单据号不能同时为空")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayFundTransCommonQuery,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	fundTransCommonQueryResponse := new(FundTransCommonQueryResponse)
+	if err := json.Unmarshal(body, fundTransCommonQueryResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, fundTransCommonQueryResponse, nil
+}
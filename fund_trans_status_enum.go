@@ -0,0 +1,39 @@
+package alipay
+
+const (
+	// FundTransStatusSuccess 转账成功
+	FundTransStatusSuccess = "SUCCESS"
+
+	// FundTransStatusDealing 处理中，需要通过alipay.fund.trans.common.query查询最终结果
+	FundTransStatusDealing = "DEALING"
+
+	// FundTransStatusFail 转账失败
+	FundTransStatusFail = "FAIL"
+
+	// FundTransStatusWaitPay 等待支付
+	FundTransStatusWaitPay = "WAIT_PAY"
+
+	// FundTransStatusClosed 订单超时关闭
+	FundTransStatusClosed = "CLOSED"
+
+	// FundTransStatusRefund 退票，转账到银行卡时银行退回
+	FundTransStatusRefund = "REFUND"
+)
+
+// FundTrans ...
+const (
+	// FundTransProductCodeTransAccountNoPwd 单笔无密转账到支付宝账户
+	FundTransProductCodeTransAccountNoPwd = "TRANS_ACCOUNT_NO_PWD"
+
+	// FundTransProductCodeTransBankcardNoPwd 单笔无密转账到银行卡
+	FundTransProductCodeTransBankcardNoPwd = "TRANS_BANKCARD_NO_PWD"
+
+	// FundTransBizSceneDirectTransfer 单笔无密转账到支付宝/银行卡
+	FundTransBizSceneDirectTransfer = "DIRECT_TRANSFER"
+
+	// FundTransIdentityTypeUserID 支付宝的会员ID
+	FundTransIdentityTypeUserID = "ALIPAY_USER_ID"
+
+	// FundTransIdentityTypeLogonID 支付宝登录号，支持邮箱和手机号格式
+	FundTransIdentityTypeLogonID = "ALIPAY_LOGON_ID"
+)
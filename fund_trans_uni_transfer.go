@@ -0,0 +1,123 @@
+// Package alipay https://opendocs.alipay.com/apis/api_28/alipay.fund.trans.uni.transfer
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// FundTransUniTransferParamPayeeInfo ...
+type FundTransUniTransferParamPayeeInfo struct {
+	Identity     string `json:"identity"`       // 参与方的唯一标识
+	IdentityType string `json:"identity_type"`  // 参与方的标识类型，ALIPAY_USER_ID或ALIPAY_LOGON_ID
+	Name         string `json:"name,omitempty"` // 参与方真实姓名，identity_type为ALIPAY_LOGON_ID时必填
+}
+
+// FundTransUniTransferParam ...
+type FundTransUniTransferParam struct {
+	OutBizNo        string                              `json:"out_biz_no"`                  // 商户端的唯一订单号，对于同一笔转账请求，商户需保证该订单号唯一
+	TransAmount     string                              `json:"trans_amount"`                // 订单总金额，单位为元，精确到小数点后两位
+	ProductCode     string                              `json:"product_code"`                // 业务产品码，单笔无密转账到支付宝账户固定为TRANS_ACCOUNT_NO_PWD
+	BizScene        string                              `json:"biz_scene"`                   // 描述特定的业务场景，单笔无密转账固定为DIRECT_TRANSFER
+	OrderTitle      string                              `json:"order_title,omitempty"`       // 转账业务的标题，用于在支付宝用户的账单里显示
+	OriginalOrderID string                              `json:"original_order_id,omitempty"` // 原支付宝业务单号
+	PayeeInfo       *FundTransUniTransferParamPayeeInfo `json:"payee_info"`                  // 收款方信息
+	Remark          string                              `json:"remark,omitempty"`            // 业务备注
+	BusinessParams  string                              `json:"business_params,omitempty"`   // 转账业务请求的扩展参数，Json格式
+}
+
+// FundTransUniTransferResponse ...
+type FundTransUniTransferResponse struct {
+	ResponseError
+	OutBizNo       string `json:"out_biz_no"`        // 商户订单号
+	OrderID        string `json:"order_id"`          // 支付宝转账订单号
+	PayFundOrderID string `json:"pay_fund_order_id"` // 支付宝支付资金流水号
+	Status         string `json:"status"`            // 转账单据状态，SUCCESS成功，FAIL失败，DEALING处理中
+	TransDate      string `json:"trans_date"`        // 订单支付时间
+}
+
+// IsSuccess ...
+func (resp *FundTransUniTransferResponse) IsSuccess() bool {
+	return resp.Success() && resp.Status == FundTransStatusSuccess
+}
+
+// FundTransFailSubCodes 网关返回40004时，确定转账没有发生的sub_code。
+// 不在其中的非10000结果（包括20000、isp.*、SYSTEM_ERROR、ACQ.SYSTEM_ERROR）都视为结果未知
+var FundTransFailSubCodes = map[string]bool{
+	"PAYER_BALANCE_NOT_ENOUGH":         true,
+	"PAYER_STATUS_ERROR":               true,
+	"PAYER_USER_INFO_ERROR":            true,
+	"PAYER_CERTIFY_CHECK_FAIL":         true,
+	"PAYER_PAYEE_CANNOT_SAME":          true,
+	"PAYEE_NOT_EXIST":                  true,
+	"PAYEE_ACCOUNT_NOT_EXSIT":          true,
+	"PAYEE_USERINFO_ERROR":             true,
+	"PAYEE_ACCOUNT_STATUS_ERROR":       true,
+	"PAYEE_USER_TYPE_ERROR":            true,
+	"PAYEE_ACC_OCUPIED":                true,
+	"PAYEE_TRUSTEESHIP_ACC_OVER_LIMIT": true,
+	"NO_ACCOUNT_RECEIVE_PERMISSION":    true,
+	"BLOCK_USER_FORBBIDEN_RECIEVE":     true,
+	"PERMIT_CHECK_PERM_LIMITED":        true,
+	"PRODUCT_NOT_SIGN":                 true,
+	"EXCEED_LIMIT_SM_AMOUNT":           true,
+	"EXCEED_LIMIT_DM_AMOUNT":           true,
+	"EXCEED_LIMIT_SM_MIN_AMOUNT":       true,
+	"INVALID_PARAMETER":                true,
+}
+
+// IsDealing 结果不确定：处理中，或者网关返回了10000以外且不属于FundTransFailSubCodes的结果。
+// 此时不能重新发起一笔新的转账，只能用FundTransCommonQuery查询，或者用相同的out_biz_no重试，直到得到SUCCESS或FAIL
+func (resp *FundTransUniTransferResponse) IsDealing() bool {
+	return !resp.IsSuccess() && !resp.IsFail()
+}
+
+// IsFail 确定失败：状态为FAIL，或者网关返回40004且sub_code属于FundTransFailSubCodes
+func (resp *FundTransUniTransferResponse) IsFail() bool {
+	if resp.Success() {
+		return resp.Status == FundTransStatusFail
+	}
+	return resp.Code == "40004" && FundTransFailSubCodes[resp.SubCode]
+}
+
+// IsNotEnoughBalance ...
+func (resp *FundTransUniTransferResponse) IsNotEnoughBalance() bool {
+	return resp.SubCode == "PAYER_BALANCE_NOT_ENOUGH"
+}
+
+// FundTransUniTransfer 该接口需要使用公钥证书模式，见NewWithCert
+func (alipay *Alipay) FundTransUniTransfer(param *FundTransUniTransferParam, fillList ...Fill) (int, *FundTransUniTransferResponse, error) {
+	if len(param.OutBizNo) == 0 {
+		return 0, nil, errors.New("商户转账订单号不能为空")
+	}
+
+	if len(param.TransAmount) == 0 {
+		return 0, nil, errors.New("转账金额不能为空")
+	}
+
+	if param.PayeeInfo == nil || len(param.PayeeInfo.Identity) == 0 {
+		return 0, nil, errors.New("收款方信息不能为空")
+	}
+
+	if len(param.ProductCode) == 0 {
+		param.ProductCode = FundTransProductCodeTransAccountNoPwd
+	}
+
+	if len(param.BizScene) == 0 {
+		param.BizScene = FundTransBizSceneDirectTransfer
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayFundTransUniTransfer,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	fundTransUniTransferResponse := new(FundTransUniTransferResponse)
+	if err := json.Unmarshal(body, fundTransUniTransferResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, fundTransUniTransferResponse, nil
+}
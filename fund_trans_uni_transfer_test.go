@@ -0,0 +1,64 @@
+package alipay
+
+import "testing"
+
+func TestFundTransUniTransferResult(t *testing.T) {
+	cases := []struct {
+		name    string
+		result  map[string]interface{}
+		success bool
+		dealing bool
+		fail    bool
+	}{
+		{name: "success", result: map[string]interface{}{"status": FundTransStatusSuccess}, success: true},
+		{name: "dealing", result: map[string]interface{}{"status": FundTransStatusDealing}, dealing: true},
+		{name: "status fail", result: map[string]interface{}{"status": FundTransStatusFail}, fail: true},
+		{name: "balance not enough", result: map[string]interface{}{"code": "40004", "msg": "Business Failed", "sub_code": "PAYER_BALANCE_NOT_ENOUGH"}, fail: true},
+		{name: "system error", result: map[string]interface{}{"code": "40004", "msg": "Business Failed", "sub_code": "SYSTEM_ERROR"}, dealing: true},
+		{name: "unknown sub_code", result: map[string]interface{}{"code": "40004", "msg": "Business Failed", "sub_code": "SOMETHING_NEW"}, dealing: true},
+		{name: "service unavailable", result: map[string]interface{}{"code": "20000", "msg": "Service Currently Unavailable", "sub_code": "isp.unknow-error"}, dealing: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			alipay, gateway := newTestAlipay(t)
+			gateway.handle(MethodAlipayFundTransUniTransfer, func(req *testRequest) map[string]interface{} {
+				c.result["out_biz_no"] = req.biz("out_biz_no")
+				return c.result
+			})
+
+			_, resp, err := alipay.FundTransUniTransfer(&FundTransUniTransferParam{
+				OutBizNo:    "B001",
+				TransAmount: "1.00",
+				PayeeInfo:   &FundTransUniTransferParamPayeeInfo{Identity: "2088000000000002", IdentityType: FundTransIdentityTypeUserID},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.IsSuccess() != c.success || resp.IsDealing() != c.dealing || resp.IsFail() != c.fail {
+				t.Fatalf("success=%v dealing=%v fail=%v, want %v %v %v: %+v",
+					resp.IsSuccess(), resp.IsDealing(), resp.IsFail(), c.success, c.dealing, c.fail, resp)
+			}
+			req := gateway.sent(MethodAlipayFundTransUniTransfer)[0]
+			if req.biz("product_code") != FundTransProductCodeTransAccountNoPwd || req.biz("biz_scene") != FundTransBizSceneDirectTransfer {
+				t.Fatalf("default product_code and biz_scene were not sent: %v", req.Biz)
+			}
+		})
+	}
+}
+
+func TestFundTransUniTransferInvalid(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	payeeInfo := &FundTransUniTransferParamPayeeInfo{Identity: "2088000000000002", IdentityType: FundTransIdentityTypeUserID}
+	for _, param := range []*FundTransUniTransferParam{
+		{TransAmount: "1.00", PayeeInfo: payeeInfo},
+		{OutBizNo: "B001", PayeeInfo: payeeInfo},
+		{OutBizNo: "B001", TransAmount: "1.00"},
+	} {
+		if _, _, err := alipay.FundTransUniTransfer(param); err == nil {
+			t.Errorf("param %+v should be rejected", param)
+		}
+	}
+	if len(gateway.sent(MethodAlipayFundTransUniTransfer)) != 0 {
+		t.Fatal("invalid params should not be sent")
+	}
+}
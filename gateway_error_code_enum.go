@@ -5,3 +5,9 @@ package alipay
 const (
 	GatewaySuccess = "10000"
 )
+
+// 可以重试的错误码
+const (
+	GatewayUnknownError = "20000"            // 服务不可用
+	SubCodeSystemError  = "ACQ.SYSTEM_ERROR" // 系统错误
+)
@@ -0,0 +1,199 @@
+package alipay
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var (
+	testKeyOnce   sync.Once
+	testAppKey    *rsa.PrivateKey
+	testAlipayKey *rsa.PrivateKey
+)
+
+// testRequest 假网关收到的请求
+type testRequest struct {
+	Method string
+	Values url.Values
+	Biz    map[string]interface{}
+}
+
+// biz 返回biz_content中的字段
+func (req *testRequest) biz(key string) string {
+	value, ok := req.Biz[key]
+	if !ok || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+// testHandler 返回的字段会覆盖默认的code和msg
+type testHandler func(req *testRequest) map[string]interface{}
+
+// testGateway 替换http.Client的Transport，按接口名称分发请求，响应使用测试支付宝私钥签名
+type testGateway struct {
+	t        *testing.T
+	mu       sync.Mutex
+	handlers map[string]testHandler
+	files    map[string][]byte
+	requests []*testRequest
+}
+
+func newTestAlipay(t *testing.T) (*Alipay, *testGateway) {
+	t.Helper()
+	testKeyOnce.Do(func() {
+		var err error
+		if testAppKey, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+			panic(err)
+		}
+		if testAlipayKey, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+			panic(err)
+		}
+	})
+	gateway := &testGateway{
+		t:        t,
+		handlers: make(map[string]testHandler),
+		files:    make(map[string][]byte),
+	}
+	alipay := &Alipay{
+		appID:      "2021000000000001",
+		PrivateKey: testAppKey,
+		PublicKey:  &testAlipayKey.PublicKey,
+		client:     &http.Client{Transport: gateway},
+	}
+	return alipay, gateway
+}
+
+func (gateway *testGateway) handle(method string, handler testHandler) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	gateway.handlers[method] = handler
+}
+
+// file 注册网关以外的下载地址
+func (gateway *testGateway) file(rawURL string, data []byte) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	gateway.files[rawURL] = data
+}
+
+// sent 返回某个接口收到的请求
+func (gateway *testGateway) sent(method string) []*testRequest {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	requestList := make([]*testRequest, 0)
+	for _, req := range gateway.requests {
+		if req.Method == method {
+			requestList = append(requestList, req)
+		}
+	}
+	return requestList
+}
+
+func (gateway *testGateway) RoundTrip(request *http.Request) (*http.Response, error) {
+	if !strings.HasSuffix(request.URL.Path, "/gateway.do") {
+		gateway.mu.Lock()
+		data, ok := gateway.files[request.URL.String()]
+		gateway.mu.Unlock()
+		if !ok {
+			return testResponse(request, http.StatusNotFound, nil), nil
+		}
+		return testResponse(request, http.StatusOK, data), nil
+	}
+
+	values := request.URL.Query()
+	toVerifyValues := url.Values{}
+	for key, list := range values {
+		if key != "sign" {
+			toVerifyValues[key] = list
+		}
+	}
+	sign, _ := base64.StdEncoding.DecodeString(values.Get("sign"))
+	if err := Verify(&testAppKey.PublicKey, []byte(NormValues(toVerifyValues)), sign); err != nil {
+		gateway.t.Errorf("请求签名验证失败: %v", err)
+	}
+
+	req := &testRequest{
+		Method: values.Get("method"),
+		Values: values,
+		Biz:    make(map[string]interface{}),
+	}
+	if bizContent := values.Get("biz_content"); len(bizContent) > 0 {
+		decoder := json.NewDecoder(strings.NewReader(bizContent))
+		decoder.UseNumber()
+		if err := decoder.Decode(&req.Biz); err != nil {
+			gateway.t.Errorf("biz_content反序列化失败: %v", err)
+		}
+	}
+
+	gateway.mu.Lock()
+	gateway.requests = append(gateway.requests, req)
+	handler, ok := gateway.handlers[req.Method]
+	gateway.mu.Unlock()
+
+	result := map[string]interface{}{"code": "10000", "msg": "Success"}
+	if ok {
+		for key, value := range handler(req) {
+			result[key] = value
+		}
+	} else {
+		result = map[string]interface{}{"code": "40004", "msg": "Business Failed", "sub_code": "isv.invalid-method", "sub_msg": "不存在的方法名"}
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	responseSign, err := RSA2(testAlipayKey, string(data))
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		strings.Replace(req.Method, ".", "_", -1) + "_response": json.RawMessage(data),
+		"sign": responseSign,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return testResponse(request, http.StatusOK, body), nil
+}
+
+func testResponse(request *http.Request, statusCode int, body []byte) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Status:     http.StatusText(statusCode),
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewReader(body)),
+		Request:    request,
+	}
+}
+
+// testNotification 用测试支付宝私钥给异步通知签名
+func testNotification(t *testing.T, values url.Values) url.Values {
+	t.Helper()
+	toSignValues := url.Values{}
+	for key, list := range values {
+		if key != "sign" && key != "sign_type" {
+			toSignValues[key] = list
+		}
+	}
+	sign, err := RSA2(testAlipayKey, NormValues(toSignValues))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values.Set("sign_type", "RSA2")
+	values.Set("sign", sign)
+	return values
+}
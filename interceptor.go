@@ -0,0 +1,36 @@
+package alipay
+
+// Invocation 一次网关请求，拦截器在调用next之前可以修改Param，返回之后可以读取结果
+type Invocation struct {
+	Method     string        // 接口名称
+	Param      *CommonParam  // 已经应用了Fill的公共参数，BizContent为序列化后的业务参数，sign在发出请求时计算
+	Content    interface{}   // 业务参数，只读，修改业务参数需要修改Param.BizContent
+	StatusCode int           // HTTP状态码，没有收到响应时为0
+	Result     []byte        // 验签通过后*_response对应的JSON
+	Response   ResponseError // 从Result中解析的code、msg、sub_code、sub_msg
+}
+
+// Invoker 发出请求，或者调用下一个拦截器
+type Invoker func(inv *Invocation) error
+
+// Interceptor 包裹一次OnRequest，调用next继续处理；可以不调用next直接返回错误（例如限流），也可以多次调用（例如重试）
+type Interceptor func(inv *Invocation, next Invoker) error
+
+// Use 添加拦截器，先添加的在外层，需要在发起请求之前调用
+func (alipay *Alipay) Use(interceptorList ...Interceptor) {
+	interceptors := make([]Interceptor, 0, len(alipay.interceptors)+len(interceptorList))
+	interceptors = append(interceptors, alipay.interceptors...)
+	alipay.interceptors = append(interceptors, interceptorList...)
+}
+
+// invoker 把拦截器串成一个Invoker，最内层为invoke
+func (alipay *Alipay) invoker() Invoker {
+	invoker := Invoker(alipay.invoke)
+	for idx := len(alipay.interceptors) - 1; idx >= 0; idx-- {
+		interceptor, next := alipay.interceptors[idx], invoker
+		invoker = func(inv *Invocation) error {
+			return interceptor(inv, next)
+		}
+	}
+	return invoker
+}
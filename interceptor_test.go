@@ -0,0 +1,80 @@
+package alipay_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/xiaojiaoyu100/alipay"
+)
+
+func TestInterceptorOrder(t *testing.T) {
+	client, gateway := newTestClient(t)
+	trace := make([]string, 0)
+	client.Use(
+		func(inv *alipay.Invocation, next alipay.Invoker) error {
+			trace = append(trace, "outer:"+inv.Method)
+			err := next(inv)
+			trace = append(trace, "outer:"+inv.Response.Code)
+			return err
+		},
+		func(inv *alipay.Invocation, next alipay.Invoker) error {
+			trace = append(trace, "inner")
+			inv.Param.AppAuthToken = "token-A1"
+			return next(inv)
+		},
+	)
+
+	_, resp, err := client.Precreate(&alipay.PrecreateParam{OutTradeNo: "T001", TotalAmount: "10.00", Subject: "测试商品"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success() {
+		t.Fatalf("precreate: %+v", resp)
+	}
+	want := "outer:" + alipay.MethodAlipayTradePrecreate + ",inner,outer:10000"
+	if got := strings.Join(trace, ","); got != want {
+		t.Fatalf("trace = %s, want %s", got, want)
+	}
+	requests := gateway.Requests()
+	if requests[len(requests)-1].Values.Get("app_auth_token") != "token-A1" {
+		t.Fatal("param modified by interceptor should be signed and sent")
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	client, gateway := newTestClient(t)
+	errLimited := errors.New("limited")
+	client.Use(func(inv *alipay.Invocation, next alipay.Invoker) error {
+		return errLimited
+	})
+
+	if _, _, err := client.Query(&alipay.QueryParam{OutTradeNo: "T001"}); !errors.Is(err, errLimited) {
+		t.Fatalf("err = %v, want errLimited", err)
+	}
+	if len(gateway.Requests()) != 0 {
+		t.Fatal("request should not reach the gateway")
+	}
+}
+
+func TestInterceptorCallNextTwice(t *testing.T) {
+	client, gateway := newTestClient(t)
+	statusList := make([]string, 0)
+	client.Use(func(inv *alipay.Invocation, next alipay.Invoker) error {
+		for i := 0; i < 2; i++ {
+			if err := next(inv); err != nil {
+				return err
+			}
+			statusList = append(statusList, inv.Response.SubCode)
+		}
+		return nil
+	})
+
+	_, resp, err := client.Query(&alipay.QueryParam{OutTradeNo: "T001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.SubCode != "ACQ.TRADE_NOT_EXIST" || len(statusList) != 2 || len(gateway.Requests()) != 2 {
+		t.Fatalf("resp: %+v, status: %v, requests: %d", resp, statusList, len(gateway.Requests()))
+	}
+}
@@ -0,0 +1,93 @@
+// Package alipay https://opendocs.alipay.com/apis/api_4/alipay.merchant.order.sync
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// MerchantOrderType ...
+const (
+	MerchantOrderTypeTradeOrder   = "TRADE_ORDER"
+	MerchantOrderTypeServiceOrder = "SERVICE_ORDER"
+)
+
+// MerchantOrderSyncParamExtInfo 扩展信息，例如merchant_biz_type、merchant_order_status、tiny_app_id
+type MerchantOrderSyncParamExtInfo struct {
+	ExtKey   string `json:"ext_key"`   // 键
+	ExtValue string `json:"ext_value"` // 值
+}
+
+// MerchantOrderSyncParamItemOrder ...
+type MerchantOrderSyncParamItemOrder struct {
+	SkuID     string                           `json:"sku_id,omitempty"`     // 商品sku id
+	ItemID    string                           `json:"item_id,omitempty"`    // 商品id
+	ItemName  string                           `json:"item_name"`            // 商品名称
+	UnitPrice string                           `json:"unit_price,omitempty"` // 商品单价，单位为元
+	Quantity  int                              `json:"quantity,omitempty"`   // 商品数量
+	ExtInfo   []*MerchantOrderSyncParamExtInfo `json:"ext_info,omitempty"`   // 商品扩展信息
+}
+
+// MerchantOrderSyncParamLogisticsInfo ...
+type MerchantOrderSyncParamLogisticsInfo struct {
+	TrackingNo    string `json:"tracking_no"`              // 物流单号
+	LogisticsCode string `json:"logistics_code,omitempty"` // 物流公司编号
+}
+
+// MerchantOrderSyncParamShopInfo ...
+type MerchantOrderSyncParamShopInfo struct {
+	MerchantShopID string `json:"merchant_shop_id,omitempty"` // 商户门店id
+	Name           string `json:"name,omitempty"`             // 店铺名称
+	Address        string `json:"address,omitempty"`          // 店铺地址
+	PhoneNum       string `json:"phone_num,omitempty"`        // 联系电话
+}
+
+// MerchantOrderSyncParam ...
+type MerchantOrderSyncParam struct {
+	OutBizNo          string                                 `json:"out_biz_no"`                    // 外部订单号，商户侧唯一
+	BuyerID           string                                 `json:"buyer_id,omitempty"`            // 买家支付宝用户id
+	SellerID          string                                 `json:"seller_id,omitempty"`           // 卖家支付宝用户id
+	PartnerID         string                                 `json:"partner_id,omitempty"`          // 服务商的支付宝用户id
+	Amount            string                                 `json:"amount,omitempty"`              // 订单金额，单位为元
+	PayAmount         string                                 `json:"pay_amount,omitempty"`          // 支付金额，单位为元
+	OrderType         string                                 `json:"order_type,omitempty"`          // 订单类型，TRADE_ORDER或SERVICE_ORDER
+	TradeNo           string                                 `json:"trade_no,omitempty"`            // 订单所对应的支付宝交易号
+	OutTradeNo        string                                 `json:"out_trade_no,omitempty"`        // 订单所对应的商户订单号
+	OrderCreateTime   string                                 `json:"order_create_time,omitempty"`   // 订单创建时间，格式为yyyy-MM-dd HH:mm:ss
+	OrderPayTime      string                                 `json:"order_pay_time,omitempty"`      // 订单支付时间
+	OrderModifiedTime string                                 `json:"order_modified_time,omitempty"` // 订单修改时间，用于订单状态或数据变化较快的顺序控制
+	ItemOrderList     []*MerchantOrderSyncParamItemOrder     `json:"item_order_list,omitempty"`     // 商品信息列表
+	ExtInfo           []*MerchantOrderSyncParamExtInfo       `json:"ext_info,omitempty"`            // 扩展信息
+	LogisticsInfoList []*MerchantOrderSyncParamLogisticsInfo `json:"logistics_info_list,omitempty"` // 物流信息
+	ShopInfo          *MerchantOrderSyncParamShopInfo        `json:"shop_info,omitempty"`           // 门店信息
+}
+
+// MerchantOrderSyncResponse ...
+type MerchantOrderSyncResponse struct {
+	ResponseError
+	RecordID        string   `json:"record_id"`        // 同步订单记录id
+	OrderID         string   `json:"order_id"`         // 支付宝订单id
+	OrderStatus     string   `json:"order_status"`     // 订单状态
+	SyncSuggestions []string `json:"sync_suggestions"` // 订单同步建议
+}
+
+// MerchantOrderSync trade_no、out_trade_no用于关联Pay、Create创建的交易
+func (alipay *Alipay) MerchantOrderSync(param *MerchantOrderSyncParam, fillList ...Fill) (int, *MerchantOrderSyncResponse, error) {
+	if len(param.OutBizNo) == 0 {
+		return 0, nil, errors.New("外部订单号不能为空")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayMerchantOrderSync,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	merchantOrderSyncResponse := new(MerchantOrderSyncResponse)
+	if err := json.Unmarshal(body, merchantOrderSyncResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, merchantOrderSyncResponse, nil
+}
@@ -0,0 +1,26 @@
+package alipay
+
+import "testing"
+
+func TestMerchantOrderSync(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayMerchantOrderSync, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{"record_id": "R001", "order_id": "O001", "order_status": "PAID"}
+	})
+
+	_, resp, err := alipay.MerchantOrderSync(&MerchantOrderSyncParam{
+		OutBizNo:      "B001",
+		OutTradeNo:    "T001",
+		OrderType:     MerchantOrderTypeTradeOrder,
+		ItemOrderList: []*MerchantOrderSyncParamItemOrder{{ItemName: "商品", Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success() || resp.RecordID != "R001" {
+		t.Fatalf("resp: %+v", resp)
+	}
+	if _, _, err := alipay.MerchantOrderSync(&MerchantOrderSyncParam{OutTradeNo: "T001"}); err == nil {
+		t.Fatal("empty out_biz_no should be rejected")
+	}
+}
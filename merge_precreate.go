@@ -0,0 +1,133 @@
+// Package alipay https://opendocs.alipay.com/apis/api_1/alipay.trade.merge.precreate
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MergePrecreateParamSubMerchant ...
+type MergePrecreateParamSubMerchant struct {
+	MerchantID   string `json:"merchant_id"`             // 间连受理商户的支付宝商户编号
+	MerchantType string `json:"merchant_type,omitempty"` // 商户id类型，alipay表示支付宝商户编号
+}
+
+// MergePrecreateParamSettleDetailInfo ...
+type MergePrecreateParamSettleDetailInfo struct {
+	TransInType      string `json:"trans_in_type"`                // 结算收款方的账户类型，cardAliasNo、userId、loginName或defaultSettle
+	TransIn          string `json:"trans_in,omitempty"`           // 结算收款方
+	SummaryDimension string `json:"summary_dimension,omitempty"`  // 结算汇总维度
+	SettleEntityID   string `json:"settle_entity_id,omitempty"`   // 结算主体标识
+	SettleEntityType string `json:"settle_entity_type,omitempty"` // 结算主体类型
+	Amount           string `json:"amount"`                       // 结算的金额，单位为元
+}
+
+// MergePrecreateParamSettleInfo ...
+type MergePrecreateParamSettleInfo struct {
+	SettleDetailInfos []*MergePrecreateParamSettleDetailInfo `json:"settle_detail_infos"`          // 结算详细信息
+	SettlePeriodTime  string                                 `json:"settle_period_time,omitempty"` // 该笔订单的超期自动确认结算时间
+}
+
+// MergePrecreateParamOrderDetail 合并支付中的一笔子订单
+type MergePrecreateParamOrderDetail struct {
+	AppID          string                          `json:"app_id"`                    // 订单明细的应用唯一标识
+	OutTradeNo     string                          `json:"out_trade_no"`              // 商户订单号，需要保证不重复，后续可以用Query、Refund单独处理
+	SellerID       string                          `json:"seller_id,omitempty"`       // 卖家支付宝用户ID，如果该值与seller_logon_id同时为空，则卖家默认为app_id对应的支付宝用户ID
+	SellerLogonID  string                          `json:"seller_logon_id,omitempty"` // 卖家支付宝登录账号
+	ProductCode    string                          `json:"product_code"`              // 销售产品码，与支付宝签约的产品码名称
+	TotalAmount    string                          `json:"total_amount"`              // 订单总金额，单位为元，精确到小数点后两位
+	Subject        string                          `json:"subject"`                   // 订单标题
+	Body           string                          `json:"body,omitempty"`            // 对交易或商品的描述
+	ShowURL        string                          `json:"show_url,omitempty"`        // 商品的展示地址
+	GoodsDetail    []*PayParamGoods                `json:"goods_detail,omitempty"`    // 订单包含的商品列表信息
+	ExtendParams   *PayParamExtendParams           `json:"extend_params,omitempty"`   // 业务扩展参数
+	SubMerchant    *MergePrecreateParamSubMerchant `json:"sub_merchant,omitempty"`    // 二级商户信息
+	SettleInfo     *MergePrecreateParamSettleInfo  `json:"settle_info,omitempty"`     // 描述结算信息
+	PassbackParams string                          `json:"passback_params,omitempty"` // 公用回传参数，异步通知时原样返回
+}
+
+// MergePrecreateParam ...
+type MergePrecreateParam struct {
+	OutMergeNo     string                            `json:"out_merge_no,omitempty"`    // 如果已经和支付宝约定要求子订单明细必须同时支付成功或者同时支付失败则必须传入此参数，且该参数必须在商户端唯一
+	TimeoutExpress string                            `json:"timeout_express,omitempty"` // 订单允许的最晚付款时间，逾期将关闭交易
+	OrderDetails   []*MergePrecreateParamOrderDetail `json:"order_details"`             // 子订单详情，最多10笔
+}
+
+// MergePrecreateResponseOrderDetailResult ...
+type MergePrecreateResponseOrderDetailResult struct {
+	AppID      string `json:"app_id"`       // 应用唯一标识
+	OutTradeNo string `json:"out_trade_no"` // 子订单的商户订单号
+	Success    bool   `json:"success"`      // 子订单是否预下单成功
+	ResultCode string `json:"result_code"`  // 子订单预下单失败时的错误码
+}
+
+// MergePrecreateResponse ...
+type MergePrecreateResponse struct {
+	ResponseError
+	OutMergeNo         string                                     `json:"out_merge_no"`         // 合并订单号
+	PreOrderNo         string                                     `json:"pre_order_no"`         // 预下单号，用于AppMergePay、WapMergePay
+	OrderDetailResults []*MergePrecreateResponseOrderDetailResult `json:"order_detail_results"` // 子订单详情
+}
+
+// FailedOrderDetailResults 预下单失败的子订单
+func (resp *MergePrecreateResponse) FailedOrderDetailResults() []*MergePrecreateResponseOrderDetailResult {
+	failed := make([]*MergePrecreateResponseOrderDetailResult, 0)
+	for _, result := range resp.OrderDetailResults {
+		if !result.Success {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// MergePrecreate 合并支付预下单，子订单支付后可以通过各自的out_trade_no调用Query、Refund
+func (alipay *Alipay) MergePrecreate(param *MergePrecreateParam, fillList ...Fill) (int, *MergePrecreateResponse, error) {
+	if len(param.OrderDetails) < 2 || len(param.OrderDetails) > 10 {
+		return 0, nil, errors.New("合并支付子订单数量应为2到10笔")
+	}
+
+	// app_id的默认值设置在副本上，不修改调用方的子订单
+	request := *param
+	request.OrderDetails = make([]*MergePrecreateParamOrderDetail, len(param.OrderDetails))
+	outTradeNoSet := make(map[string]struct{}, len(param.OrderDetails))
+	for idx, detail := range param.OrderDetails {
+		if len(detail.OutTradeNo) == 0 {
+			return 0, nil, fmt.Errorf("第%d笔子订单商户订单号不能为空", idx+1)
+		}
+		if _, ok := outTradeNoSet[detail.OutTradeNo]; ok {
+			return 0, nil, fmt.Errorf("子订单商户订单号重复: %s", detail.OutTradeNo)
+		}
+		outTradeNoSet[detail.OutTradeNo] = struct{}{}
+		if len(detail.TotalAmount) == 0 {
+			return 0, nil, fmt.Errorf("第%d笔子订单金额不能为空", idx+1)
+		}
+		if len(detail.Subject) == 0 {
+			return 0, nil, fmt.Errorf("第%d笔子订单标题不能为空", idx+1)
+		}
+		requestDetail := *detail
+		if len(requestDetail.AppID) == 0 {
+			requestDetail.AppID = alipay.appID
+		}
+		request.OrderDetails[idx] = &requestDetail
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		&request,
+		MethodAlipayTradeMergePrecreate,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	mergePrecreateResponse := new(MergePrecreateResponse)
+	if err := json.Unmarshal(body, mergePrecreateResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, mergePrecreateResponse, nil
+}
+
+// MergePayParam ...
+type MergePayParam struct {
+	PreOrderNo string `json:"pre_order_no"` // MergePrecreate返回的预下单号
+}
@@ -0,0 +1,110 @@
+package alipay
+
+import (
+	"net/url"
+	"testing"
+)
+
+func testMergePrecreateParam() *MergePrecreateParam {
+	return &MergePrecreateParam{
+		OutMergeNo: "M001",
+		OrderDetails: []*MergePrecreateParamOrderDetail{
+			{OutTradeNo: "T001", ProductCode: "QUICK_MSECURITY_PAY", TotalAmount: "6.00", Subject: "商品一"},
+			{AppID: "2021000000000002", OutTradeNo: "T002", ProductCode: "QUICK_MSECURITY_PAY", TotalAmount: "4.00", Subject: "商品二"},
+		},
+	}
+}
+
+func TestMergePrecreate(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayTradeMergePrecreate, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{
+			"out_merge_no": req.biz("out_merge_no"),
+			"pre_order_no": "P001",
+			"order_detail_results": []map[string]interface{}{
+				{"app_id": alipay.AppID(), "out_trade_no": "T001", "success": true},
+				{"app_id": "2021000000000002", "out_trade_no": "T002", "success": false, "result_code": "SELLER_NOT_EXIST"},
+			},
+		}
+	})
+
+	param := testMergePrecreateParam()
+	_, resp, err := alipay.MergePrecreate(param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success() || resp.PreOrderNo != "P001" {
+		t.Fatalf("resp: %+v", resp)
+	}
+	failed := resp.FailedOrderDetailResults()
+	if len(failed) != 1 || failed[0].OutTradeNo != "T002" || failed[0].ResultCode != "SELLER_NOT_EXIST" {
+		t.Fatalf("failed: %+v", failed)
+	}
+
+	orderDetails, _ := gateway.sent(MethodAlipayTradeMergePrecreate)[0].Biz["order_details"].([]interface{})
+	if len(orderDetails) != 2 {
+		t.Fatalf("order_details: %v", orderDetails)
+	}
+	for i, appID := range []string{alipay.AppID(), "2021000000000002"} {
+		if detail, _ := orderDetails[i].(map[string]interface{}); detail["app_id"] != appID {
+			t.Errorf("order_details[%d].app_id = %v, want %s", i, detail["app_id"], appID)
+		}
+	}
+	if len(param.OrderDetails[0].AppID) != 0 {
+		t.Fatalf("caller's order detail was modified: %+v", param.OrderDetails[0])
+	}
+}
+
+func TestMergePrecreateInvalid(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	cases := map[string]func(param *MergePrecreateParam){
+		"one order":          func(param *MergePrecreateParam) { param.OrderDetails = param.OrderDetails[:1] },
+		"empty out_trade_no": func(param *MergePrecreateParam) { param.OrderDetails[1].OutTradeNo = "" },
+		"duplicate":          func(param *MergePrecreateParam) { param.OrderDetails[1].OutTradeNo = "T001" },
+		"empty amount":       func(param *MergePrecreateParam) { param.OrderDetails[0].TotalAmount = "" },
+		"empty subject":      func(param *MergePrecreateParam) { param.OrderDetails[0].Subject = "" },
+	}
+	for name, modify := range cases {
+		param := testMergePrecreateParam()
+		modify(param)
+		if _, _, err := alipay.MergePrecreate(param); err == nil {
+			t.Errorf("%s should be rejected", name)
+		}
+	}
+	if len(gateway.sent(MethodAlipayTradeMergePrecreate)) != 0 {
+		t.Fatal("invalid params should not be sent")
+	}
+}
+
+func TestMergePay(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+
+	appURL, err := alipay.AppMergePay("P001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wapURL, err := alipay.WapMergePay("P001", "https://example.com/return")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for method, rawURL := range map[string]string{MethodAlipayTradeAppMergePay: appURL, MethodAlipayTradeWapMergePay: wapURL} {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := u.Query()
+		if values.Get("method") != method || values.Get("biz_content") != `{"pre_order_no":"P001"}` {
+			t.Errorf("url = %s", rawURL)
+		}
+	}
+	if u, _ := url.Parse(wapURL); u.Query().Get("return_url") != "https://example.com/return" {
+		t.Errorf("wap url = %s", wapURL)
+	}
+
+	if _, err := alipay.AppMergePay(""); err == nil {
+		t.Error("empty pre_order_no should be rejected")
+	}
+	if _, err := alipay.WapMergePay("", ""); err == nil {
+		t.Error("empty pre_order_no should be rejected")
+	}
+}
@@ -4,6 +4,12 @@ package alipay
 const (
 	MethodAlipayTradeFastpayRefundQuery             = "alipay.trade.fastpay.refund.query"
 	MethodAlipayTradeOrderSettle                    = "alipay.trade.order.settle"
+	MethodAlipayTradeOrderSettleQuery               = "alipay.trade.order.settle.query"
+	MethodAlipayTradeOrderOnsettleQuery             = "alipay.trade.order.onsettle.query"
+	MethodAlipayTradeRoyaltyRelationBind            = "alipay.trade.royalty.relation.bind"
+	MethodAlipayTradeRoyaltyRelationUnbind          = "alipay.trade.royalty.relation.unbind"
+	MethodAlipayTradeRoyaltyRelationBatchquery      = "alipay.trade.royalty.relation.batchquery"
+	MethodAlipayTradeRoyaltyRateQuery               = "alipay.trade.royalty.rate.query"
 	MethodAlipayTradeClose                          = "alipay.trade.close"
 	MethodAlipayTradeCancel                         = "alipay.trade.cancel"
 	MethodAlipayTradeRefund                         = "alipay.trade.refund"
@@ -12,7 +18,31 @@ const (
 	MethodAlipayTradePay                            = "alipay.trade.pay"
 	MethodAlipayTradeQuery                          = "alipay.trade.query"
 	MethodAlipayDataDataserviceBillDownloadurlQuery = "alipay.data.dataservice.bill.downloadurl.query"
+	MethodAlipayDataBillBalanceQuery                = "alipay.data.bill.balance.query"
+	MethodAlipayDataBillAccountlogQuery             = "alipay.data.bill.accountlog.query"
+	MethodAlipayDataBillSellQuery                   = "alipay.data.bill.sell.query"
+	MethodAlipayDataBillBuyQuery                    = "alipay.data.bill.buy.query"
 	MethodAlipayTradePagePay                        = "alipay.trade.page.pay"
 	MethodAlipayTradeAppPay                         = "alipay.trade.app.pay"
 	MethodAlipayTradeWapPay                         = "alipay.trade.wap.pay"
+	MethodAlipayTradeMergePrecreate                 = "alipay.trade.merge.precreate"
+	MethodAlipayTradeAppMergePay                    = "alipay.trade.app.merge.pay"
+	MethodAlipayTradeWapMergePay                    = "alipay.trade.wap.merge.pay"
+	MethodAlipayTradeOrderinfoSync                  = "alipay.trade.orderinfo.sync"
+	MethodAlipayMerchantOrderSync                   = "alipay.merchant.order.sync"
+	MethodAlipayOpenAuthTokenApp                    = "alipay.open.auth.token.app"
+	MethodAlipayOpenAuthTokenAppQuery               = "alipay.open.auth.token.app.query"
+	MethodAlipaySystemOAuthToken                    = "alipay.system.oauth.token"
+	MethodAlipayUserInfoShare                       = "alipay.user.info.share"
+	MethodAlipayFundTransUniTransfer                = "alipay.fund.trans.uni.transfer"
+	MethodAlipayFundTransCommonQuery                = "alipay.fund.trans.common.query"
+	MethodAlipayFundAccountQuery                    = "alipay.fund.account.query"
+	MethodAlipayFundAuthOrderAppFreeze              = "alipay.fund.auth.order.app.freeze"
+	MethodAlipayFundAuthOrderUnfreeze               = "alipay.fund.auth.order.unfreeze"
+	MethodAlipayFundAuthOperationDetailQuery        = "alipay.fund.auth.operation.detail.query"
+	MethodAlipayFundAuthOperationCancel             = "alipay.fund.auth.operation.cancel"
+	MethodAlipayUserAgreementPageSign               = "alipay.user.agreement.page.sign"
+	MethodAlipayUserAgreementQuery                  = "alipay.user.agreement.query"
+	MethodAlipayUserAgreementUnsign                 = "alipay.user.agreement.unsign"
+	MethodAlipayUserAgreementExecutionplanModify    = "alipay.user.agreement.executionplan.modify"
 )
@@ -0,0 +1,378 @@
+// Package alipay https://opendocs.alipay.com/apis/api_9/alipay.open.auth.token.app
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// AppToAppAuthGateway 第三方应用授权地址
+const (
+	AppToAppAuthGateway = "https://openauth.alipay.com/oauth2/appToAppAuth.htm"
+)
+
+// GrantType ...
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+)
+
+// AppAuthTokenStatus ...
+const (
+	AppAuthTokenStatusValid   = "valid"
+	AppAuthTokenStatusInvalid = "invalid"
+)
+
+// ErrAppAuthTokenNotFound ...
+var (
+	ErrAppAuthTokenNotFound = errors.New("商户授权令牌不存在")
+	ErrAppAuthTokenExpired  = errors.New("商户授权令牌及刷新令牌均已过期，需要商户重新授权")
+)
+
+// OpenAuthTokenAppParam ...
+type OpenAuthTokenAppParam struct {
+	GrantType    string `json:"grant_type"`              // authorization_code表示换取app_auth_token，refresh_token表示刷新app_auth_token
+	Code         string `json:"code,omitempty"`          // 授权码，grant_type为authorization_code时必填
+	RefreshToken string `json:"refresh_token,omitempty"` // 刷新令牌，grant_type为refresh_token时必填
+}
+
+// OpenAuthTokenAppResponseToken ...
+type OpenAuthTokenAppResponseToken struct {
+	UserID          string      `json:"user_id"`           // 授权商户的user_id
+	AuthAppID       string      `json:"auth_app_id"`       // 授权商户的appid
+	AppAuthToken    string      `json:"app_auth_token"`    // 应用授权令牌
+	AppRefreshToken string      `json:"app_refresh_token"` // 刷新令牌
+	ExpiresIn       json.Number `json:"expires_in"`        // 应用授权令牌的有效时间（从接口调用时间作为起始时间），单位到秒
+	ReExpiresIn     json.Number `json:"re_expires_in"`     // 刷新令牌的有效时间（从接口调用时间作为起始时间），单位到秒
+}
+
+// OpenAuthTokenAppResponse ...
+type OpenAuthTokenAppResponse struct {
+	ResponseError
+	OpenAuthTokenAppResponseToken
+	Tokens []*OpenAuthTokenAppResponseToken `json:"tokens"` // 批量授权时返回的令牌列表
+}
+
+// TokenList 兼容新旧两种返回格式，旧格式的令牌直接放在响应中
+func (resp *OpenAuthTokenAppResponse) TokenList() []*OpenAuthTokenAppResponseToken {
+	if len(resp.Tokens) > 0 {
+		return resp.Tokens
+	}
+	if len(resp.AppAuthToken) > 0 {
+		return []*OpenAuthTokenAppResponseToken{&resp.OpenAuthTokenAppResponseToken}
+	}
+	return nil
+}
+
+// OpenAuthTokenApp ...
+func (alipay *Alipay) OpenAuthTokenApp(param *OpenAuthTokenAppParam, fillList ...Fill) (int, *OpenAuthTokenAppResponse, error) {
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayOpenAuthTokenApp,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	openAuthTokenAppResponse := new(OpenAuthTokenAppResponse)
+	if err := json.Unmarshal(body, openAuthTokenAppResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, openAuthTokenAppResponse, nil
+}
+
+// OpenAuthTokenAppQueryParam ...
+type OpenAuthTokenAppQueryParam struct {
+	AppAuthToken string `json:"app_auth_token"` // 应用授权令牌
+}
+
+// OpenAuthTokenAppQueryResponse ...
+type OpenAuthTokenAppQueryResponse struct {
+	ResponseError
+	UserID      string      `json:"user_id"`      // 授权商户的user_id
+	AuthAppID   string      `json:"auth_app_id"`  // 授权商户的appid
+	ExpiresIn   json.Number `json:"expires_in"`   // 应用授权令牌失效时间，单位到秒
+	AuthMethods []string    `json:"auth_methods"` // 当前app_auth_token的授权接口列表
+	AuthStart   string      `json:"auth_start"`   // 授权生效时间
+	AuthEnd     string      `json:"auth_end"`     // 授权失效时间
+	Status      string      `json:"status"`       // valid：有效状态；invalid：无效状态
+}
+
+// IsValid ...
+func (resp *OpenAuthTokenAppQueryResponse) IsValid() bool {
+	return resp.Success() && resp.Status == AppAuthTokenStatusValid
+}
+
+// OpenAuthTokenAppQuery ...
+func (alipay *Alipay) OpenAuthTokenAppQuery(param *OpenAuthTokenAppQueryParam, fillList ...Fill) (int, *OpenAuthTokenAppQueryResponse, error) {
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayOpenAuthTokenAppQuery,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	openAuthTokenAppQueryResponse := new(OpenAuthTokenAppQueryResponse)
+	if err := json.Unmarshal(body, openAuthTokenAppQueryResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, openAuthTokenAppQueryResponse, nil
+}
+
+// AppToAppAuthURL 生成商户授权页面地址，商户授权后支付宝会携带app_auth_code跳转到redirectURI
+func (alipay *Alipay) AppToAppAuthURL(redirectURI, state string) (string, error) {
+	u, err := url.Parse(AppToAppAuthGateway)
+	if err != nil {
+		return "", fmt.Errorf("解析支付宝授权地址失败: %w", err)
+	}
+	values := url.Values{}
+	values.Set("app_id", alipay.appID)
+	values.Set("redirect_uri", redirectURI)
+	if len(state) > 0 {
+		values.Set("state", state)
+	}
+	u.RawQuery = values.Encode()
+	return u.String(), nil
+}
+
+// AppAuthToken 商户授权令牌
+type AppAuthToken struct {
+	AuthAppID       string    // 授权商户的appid
+	UserID          string    // 授权商户的user_id
+	AppAuthToken    string    // 应用授权令牌
+	AppRefreshToken string    // 刷新令牌
+	ExpiresAt       time.Time // 应用授权令牌过期时间
+	ReExpiresAt     time.Time // 刷新令牌过期时间
+}
+
+// NewAppAuthToken 根据接口返回以及调用时间生成令牌
+func NewAppAuthToken(token *OpenAuthTokenAppResponseToken, issuedAt time.Time) (*AppAuthToken, error) {
+	expiresIn, err := token.ExpiresIn.Int64()
+	if err != nil {
+		return nil, fmt.Errorf("解析令牌有效时间失败: %w", err)
+	}
+	reExpiresIn, err := token.ReExpiresIn.Int64()
+	if err != nil {
+		return nil, fmt.Errorf("解析刷新令牌有效时间失败: %w", err)
+	}
+	return &AppAuthToken{
+		AuthAppID:       token.AuthAppID,
+		UserID:          token.UserID,
+		AppAuthToken:    token.AppAuthToken,
+		AppRefreshToken: token.AppRefreshToken,
+		ExpiresAt:       issuedAt.Add(time.Duration(expiresIn) * time.Second),
+		ReExpiresAt:     issuedAt.Add(time.Duration(reExpiresIn) * time.Second),
+	}, nil
+}
+
+// AppAuthTokenStore 商户授权令牌存储，找不到令牌时Load返回ErrAppAuthTokenNotFound
+type AppAuthTokenStore interface {
+	Load(authAppID string) (*AppAuthToken, error)
+	Save(token *AppAuthToken) error
+}
+
+// MemoryAppAuthTokenStore ...
+type MemoryAppAuthTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]*AppAuthToken
+}
+
+// NewMemoryAppAuthTokenStore ...
+func NewMemoryAppAuthTokenStore() *MemoryAppAuthTokenStore {
+	return &MemoryAppAuthTokenStore{
+		tokens: make(map[string]*AppAuthToken),
+	}
+}
+
+// Load ...
+func (store *MemoryAppAuthTokenStore) Load(authAppID string) (*AppAuthToken, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	token, ok := store.tokens[authAppID]
+	if !ok {
+		return nil, ErrAppAuthTokenNotFound
+	}
+	copied := *token
+	return &copied, nil
+}
+
+// Save ...
+func (store *MemoryAppAuthTokenStore) Save(token *AppAuthToken) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	copied := *token
+	store.tokens[token.AuthAppID] = &copied
+	return nil
+}
+
+// AppAuthTokenManager 管理授权商户的令牌，令牌临近过期时自动刷新。
+// 同一个商户的刷新和保存互斥，不同商户之间互不阻塞
+type AppAuthTokenManager struct {
+	alipay        *Alipay
+	store         AppAuthTokenStore
+	refreshBefore time.Duration
+	now           func() time.Time
+
+	mu    sync.Mutex                   // 保护locks
+	locks map[string]*appAuthTokenLock // key为auth_app_id，没有调用方持有或等待时删除
+}
+
+// appAuthTokenLock refs为持有或者等待该锁的调用数
+type appAuthTokenLock struct {
+	sync.Mutex
+	refs int
+}
+
+// DefaultAppAuthTokenRefreshBefore 令牌过期前多久开始刷新
+const (
+	DefaultAppAuthTokenRefreshBefore = 24 * time.Hour
+)
+
+// NewAppAuthTokenManager refreshBefore为0时使用DefaultAppAuthTokenRefreshBefore
+func NewAppAuthTokenManager(alipay *Alipay, store AppAuthTokenStore, refreshBefore time.Duration) *AppAuthTokenManager {
+	if refreshBefore <= 0 {
+		refreshBefore = DefaultAppAuthTokenRefreshBefore
+	}
+	return &AppAuthTokenManager{
+		alipay:        alipay,
+		store:         store,
+		refreshBefore: refreshBefore,
+		now:           time.Now,
+		locks:         make(map[string]*appAuthTokenLock),
+	}
+}
+
+// lock 锁住authAppID，返回解锁函数，最后一个调用方解锁时删除该锁
+func (m *AppAuthTokenManager) lock(authAppID string) func() {
+	m.mu.Lock()
+	l, ok := m.locks[authAppID]
+	if !ok {
+		l = &appAuthTokenLock{}
+		m.locks[authAppID] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		l.refs--
+		if l.refs == 0 {
+			delete(m.locks, authAppID)
+		}
+	}
+}
+
+// Exchange 用商户授权回调中的app_auth_code换取令牌并保存
+func (m *AppAuthTokenManager) Exchange(appAuthCode string) ([]*AppAuthToken, error) {
+	tokenList, err := m.grant(&OpenAuthTokenAppParam{
+		GrantType: GrantTypeAuthorizationCode,
+		Code:      appAuthCode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokenList {
+		unlock := m.lock(token.AuthAppID)
+		err := m.save(token)
+		unlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tokenList, nil
+}
+
+// fresh 令牌在refreshBefore之后仍然有效
+func (m *AppAuthTokenManager) fresh(token *AppAuthToken) bool {
+	return m.now().Add(m.refreshBefore).Before(token.ExpiresAt)
+}
+
+// Token 返回商户可用的令牌，临近过期时先刷新。令牌有效时只读取store，不加锁
+func (m *AppAuthTokenManager) Token(authAppID string) (*AppAuthToken, error) {
+	token, err := m.store.Load(authAppID)
+	if err != nil {
+		return nil, err
+	}
+	if m.fresh(token) {
+		return token, nil
+	}
+
+	unlock := m.lock(authAppID)
+	defer unlock()
+
+	// 等待锁期间其他调用可能已经刷新
+	token, err = m.store.Load(authAppID)
+	if err != nil {
+		return nil, err
+	}
+	if m.fresh(token) {
+		return token, nil
+	}
+
+	if !m.now().Before(token.ReExpiresAt) {
+		return nil, ErrAppAuthTokenExpired
+	}
+
+	tokenList, err := m.grant(&OpenAuthTokenAppParam{
+		GrantType:    GrantTypeRefreshToken,
+		RefreshToken: token.AppRefreshToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, refreshed := range tokenList {
+		if refreshed.AuthAppID == authAppID {
+			if err := m.save(refreshed); err != nil {
+				return nil, err
+			}
+			return refreshed, nil
+		}
+	}
+	return nil, ErrAppAuthTokenNotFound
+}
+
+// Fill 以授权商户身份调用接口，例如alipay.Query(param, fill)
+func (m *AppAuthTokenManager) Fill(authAppID string) (Fill, error) {
+	token, err := m.Token(authAppID)
+	if err != nil {
+		return nil, err
+	}
+	return WithAppAuthToken(token.AppAuthToken), nil
+}
+
+func (m *AppAuthTokenManager) save(token *AppAuthToken) error {
+	if err := m.store.Save(token); err != nil {
+		return fmt.Errorf("保存商户授权令牌失败: %w", err)
+	}
+	return nil
+}
+
+// grant 调用换取或刷新令牌的接口，不保存
+func (m *AppAuthTokenManager) grant(param *OpenAuthTokenAppParam) ([]*AppAuthToken, error) {
+	issuedAt := m.now()
+	_, resp, err := m.alipay.OpenAuthTokenApp(param)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success() {
+		return nil, fmt.Errorf("换取商户授权令牌失败: %s %s", resp.SubCode, resp.SubMsg)
+	}
+
+	tokenList := make([]*AppAuthToken, 0)
+	for _, item := range resp.TokenList() {
+		token, err := NewAppAuthToken(item, issuedAt)
+		if err != nil {
+			return nil, err
+		}
+		tokenList = append(tokenList, token)
+	}
+	return tokenList, nil
+}
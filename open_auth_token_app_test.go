@@ -0,0 +1,208 @@
+package alipay
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// handleTokenApp 授权码为auth_app_id，刷新令牌为"refresh-"+auth_app_id
+func handleTokenApp(gateway *testGateway) {
+	gateway.handle(MethodAlipayOpenAuthTokenApp, func(req *testRequest) map[string]interface{} {
+		authAppID := req.biz("code")
+		if req.biz("grant_type") == GrantTypeRefreshToken {
+			authAppID = strings.TrimPrefix(req.biz("refresh_token"), "refresh-")
+		}
+		return map[string]interface{}{
+			"user_id":           "2088" + authAppID,
+			"auth_app_id":       authAppID,
+			"app_auth_token":    "token-" + authAppID + "-" + req.Values.Get("timestamp"),
+			"app_refresh_token": "refresh-" + authAppID,
+			"expires_in":        31536000,
+			"re_expires_in":     32140800,
+		}
+	})
+}
+
+func expiringToken(authAppID string) *AppAuthToken {
+	return &AppAuthToken{
+		AuthAppID:       authAppID,
+		AppAuthToken:    "old-" + authAppID,
+		AppRefreshToken: "refresh-" + authAppID,
+		ExpiresAt:       time.Now().Add(time.Hour),
+		ReExpiresAt:     time.Now().Add(30 * 24 * time.Hour),
+	}
+}
+
+// lockCount 仍然保留的商户锁数量
+func lockCount(manager *AppAuthTokenManager) int {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	return len(manager.locks)
+}
+
+func TestAppAuthTokenManagerExchange(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	handleTokenApp(gateway)
+	manager := NewAppAuthTokenManager(alipay, NewMemoryAppAuthTokenStore(), 0)
+
+	tokenList, err := manager.Exchange("A1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokenList) != 1 || tokenList[0].AuthAppID != "A1" || tokenList[0].UserID != "2088A1" {
+		t.Fatalf("tokens: %+v", tokenList)
+	}
+	token, err := manager.Token("A1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AppAuthToken != tokenList[0].AppAuthToken {
+		t.Fatalf("token = %s, want %s", token.AppAuthToken, tokenList[0].AppAuthToken)
+	}
+	if count := len(gateway.sent(MethodAlipayOpenAuthTokenApp)); count != 1 {
+		t.Fatalf("fresh token should not be refreshed, sent %d requests", count)
+	}
+
+	if _, err := manager.Token("A2"); err != ErrAppAuthTokenNotFound {
+		t.Fatalf("err = %v, want ErrAppAuthTokenNotFound", err)
+	}
+}
+
+func TestAppAuthTokenManagerRefresh(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	handleTokenApp(gateway)
+	store := NewMemoryAppAuthTokenStore()
+	store.Save(expiringToken("A1"))
+	manager := NewAppAuthTokenManager(alipay, store, 0)
+
+	token, err := manager.Token("A1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(token.AppAuthToken, "token-A1-") {
+		t.Fatalf("token = %+v", token)
+	}
+	sent := gateway.sent(MethodAlipayOpenAuthTokenApp)
+	if len(sent) != 1 || sent[0].biz("refresh_token") != "refresh-A1" {
+		t.Fatalf("refresh requests: %+v", sent)
+	}
+	saved, err := store.Load("A1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.AppAuthToken != token.AppAuthToken {
+		t.Fatalf("refreshed token was not saved: %+v", saved)
+	}
+}
+
+func TestAppAuthTokenManagerExpired(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	handleTokenApp(gateway)
+	store := NewMemoryAppAuthTokenStore()
+	token := expiringToken("A1")
+	token.ExpiresAt = time.Now().Add(-time.Hour)
+	token.ReExpiresAt = time.Now().Add(-time.Minute)
+	store.Save(token)
+	manager := NewAppAuthTokenManager(alipay, store, 0)
+
+	if _, err := manager.Token("A1"); err != ErrAppAuthTokenExpired {
+		t.Fatalf("err = %v, want ErrAppAuthTokenExpired", err)
+	}
+	if len(gateway.sent(MethodAlipayOpenAuthTokenApp)) != 0 {
+		t.Fatal("expired refresh token should not be sent")
+	}
+}
+
+func TestAppAuthTokenManagerRefreshOnce(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	handleTokenApp(gateway)
+	store := NewMemoryAppAuthTokenStore()
+	store.Save(expiringToken("A1"))
+	manager := NewAppAuthTokenManager(alipay, store, 0)
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 10)
+	for idx := range tokens {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			token, err := manager.Token("A1")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			tokens[idx] = token.AppAuthToken
+		}(idx)
+	}
+	wg.Wait()
+
+	if count := len(gateway.sent(MethodAlipayOpenAuthTokenApp)); count != 1 {
+		t.Fatalf("concurrent callers should share one refresh, sent %d requests", count)
+	}
+	for _, token := range tokens {
+		if token != tokens[0] {
+			t.Fatalf("tokens: %v", tokens)
+		}
+	}
+	if count := lockCount(manager); count != 0 {
+		t.Fatalf("locks should be released after refresh, %d left", count)
+	}
+}
+
+func TestAppAuthTokenManagerPerMerchantLock(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	handleTokenApp(gateway)
+	release := make(chan struct{})
+	blocked := make(chan struct{})
+	gateway.mu.Lock()
+	refresh := gateway.handlers[MethodAlipayOpenAuthTokenApp]
+	gateway.mu.Unlock()
+	gateway.handle(MethodAlipayOpenAuthTokenApp, func(req *testRequest) map[string]interface{} {
+		if req.biz("refresh_token") == "refresh-A1" {
+			close(blocked)
+			<-release
+		}
+		return refresh(req)
+	})
+
+	store := NewMemoryAppAuthTokenStore()
+	for _, authAppID := range []string{"A1", "A2", "A3"} {
+		store.Save(expiringToken(authAppID))
+	}
+	manager := NewAppAuthTokenManager(alipay, store, 0)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := manager.Token("A1")
+		done <- err
+	}()
+	<-blocked
+
+	for _, authAppID := range []string{"A2", "A3"} {
+		token, err := manager.Token(authAppID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(token.AppAuthToken, "token-"+authAppID+"-") {
+			t.Fatalf("token = %+v", token)
+		}
+	}
+	select {
+	case <-done:
+		t.Fatal("A1 refresh should still be blocked")
+	default:
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.Exchange("A4"); err != nil {
+		t.Fatal(err)
+	}
+	if count := lockCount(manager); count != 0 {
+		t.Fatalf("locks should not grow with merchants, %d left", count)
+	}
+}
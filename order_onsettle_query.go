@@ -0,0 +1,40 @@
+// Package alipay https://opendocs.alipay.com/apis/api_1/alipay.trade.order.onsettle.query
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// OrderOnsettleQueryParam ...
+type OrderOnsettleQueryParam struct {
+	TradeNo      string `json:"trade_no"`                 // 支付宝交易号
+	OutRequestNo string `json:"out_request_no,omitempty"` // 查询的结算请求流水号
+}
+
+// OrderOnsettleQueryResponse ...
+type OrderOnsettleQueryResponse struct {
+	ResponseError
+	UnsettledAmount string `json:"unsettled_amount"` // 待分账金额，单位为元
+}
+
+// OrderOnsettleQuery ...
+func (alipay *Alipay) OrderOnsettleQuery(param *OrderOnsettleQueryParam, fillList ...Fill) (int, *OrderOnsettleQueryResponse, error) {
+	if len(param.TradeNo) == 0 {
+		return 0, nil, errors.New("支付宝交易号不能为空")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayTradeOrderOnsettleQuery,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	orderOnsettleQueryResponse := new(OrderOnsettleQueryResponse)
+	if err := json.Unmarshal(body, orderOnsettleQueryResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, orderOnsettleQueryResponse, nil
+}
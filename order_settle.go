@@ -1,44 +1,184 @@
+// Package alipay https://opendocs.alipay.com/apis/api_1/alipay.trade.order.settle
 package alipay
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Royalty ...
+const (
+	// RoyaltyTypeTransfer 分账
+	RoyaltyTypeTransfer = "transfer"
+
+	// RoyaltyTypeReplenish 营销补差
+	RoyaltyTypeReplenish = "replenish"
+
+	// RoyaltyAccountTypeUserID 支付宝账号对应的支付宝唯一用户号
+	RoyaltyAccountTypeUserID = "userId"
+
+	// RoyaltyAccountTypeLoginName 支付宝登录号
+	RoyaltyAccountTypeLoginName = "loginName"
+
+	// RoyaltyAccountTypeOpenID 支付宝用户在应用下的唯一标识
+	RoyaltyAccountTypeOpenID = "openId"
+
+	// RoyaltyModeSync 同步分账
+	RoyaltyModeSync = "sync"
+
+	// RoyaltyModeAsync 异步分账，分账结果通过alipay.trade.order.settle.notify通知
+	RoyaltyModeAsync = "async"
 )
 
 // OrderSettleParamOpenAPIRoyaltyDetailInfoPojo ...
 type OrderSettleParamOpenAPIRoyaltyDetailInfoPojo struct {
-	TransOut         string  `json:" trans_out"`        // 分账支出方账户，类型为userId，本参数为要分账的支付宝账号对应的支付宝唯一用户号。以2088开头的纯16位数字。
-	TransIn          string  `json:"trans_in"`          // 分账收入方账户，类型为userId，本参数为要分账的支付宝账号对应的支付宝唯一用户号。以2088开头的纯16位数字。
-	Amount           float64 `json:"amount"`            // 分账的金额，单位为元
-	AmountPercentage float64 `json:"amount_percentage"` // 分账信息中分账百分比。取值范围为大于0，少于或等于100的整数。
-	Desc             string  `json:"desc"`              // 分账描述
+	RoyaltyType      string `json:"royalty_type,omitempty"`      // 分账类型，普通分账为transfer，补差为replenish，默认为transfer
+	TransOut         string `json:"trans_out,omitempty"`         // 分账支出方账户，类型为userId，本参数为要分账的支付宝账号对应的支付宝唯一用户号。以2088开头的纯16位数字。
+	TransOutType     string `json:"trans_out_type,omitempty"`    // 支出方账户类型，userId或loginName
+	TransInType      string `json:"trans_in_type,omitempty"`     // 收入方账户类型，userId、loginName或openId
+	TransIn          string `json:"trans_in"`                    // 分账收入方账户，类型为userId，本参数为要分账的支付宝账号对应的支付宝唯一用户号。以2088开头的纯16位数字。
+	TransInName      string `json:"trans_in_name,omitempty"`     // 分账收款方姓名，上送则进行姓名与支付宝账号的一致性校验
+	Amount           string `json:"amount,omitempty"`            // 分账的金额，单位为元
+	AmountPercentage string `json:"amount_percentage,omitempty"` // 分账信息中分账百分比。取值范围为大于0，少于或等于100的整数。
+	Desc             string `json:"desc,omitempty"`              // 分账描述
+}
+
+// OrderSettleParamExtendParams ...
+type OrderSettleParamExtendParams struct {
+	RoyaltyFinish string `json:"royalty_finish,omitempty"` // 是否完结分账，传true表示本次分账后剩余的待分账金额解冻给卖家
 }
 
 // OrderSettleParam ...
 type OrderSettleParam struct {
-	OutRequestNo      string                                          `json:"out_request_no"`     // 结算请求流水号
-	TradeNo           string                                          `json:"trade_no"`           // 支付宝订单号
-	RoyaltyParameters []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo `json:"royalty_parameters"` // 分账明细信息
-	OperatorID        string                                          `json:"operator_id"`        // 操作员id
+	OutRequestNo      string                                          `json:"out_request_no"`          // 结算请求流水号
+	TradeNo           string                                          `json:"trade_no"`                // 支付宝订单号
+	RoyaltyParameters []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo `json:"royalty_parameters"`      // 分账明细信息
+	OperatorID        string                                          `json:"operator_id,omitempty"`   // 操作员id
+	ExtendParams      *OrderSettleParamExtendParams                   `json:"extend_params,omitempty"` // 分账结算业务扩展参数
+	RoyaltyMode       string                                          `json:"royalty_mode,omitempty"`  // 分账模式，sync同步或async异步，默认为sync
+}
+
+// TotalCent 分账明细中按金额分账部分的合计，单位为分，按百分比分账的明细只校验百分比格式
+func (param *OrderSettleParam) TotalCent() (int64, error) {
+	var total int64
+	for idx, royalty := range param.RoyaltyParameters {
+		if len(royalty.Amount) == 0 && len(royalty.AmountPercentage) == 0 {
+			return 0, fmt.Errorf("第%d条分账明细必须指定金额或百分比", idx+1)
+		}
+		if len(royalty.AmountPercentage) > 0 {
+			if len(royalty.Amount) > 0 {
+				return 0, fmt.Errorf("第%d条分账明细不能同时指定金额和百分比", idx+1)
+			}
+			if _, err := royalty.percentage(); err != nil {
+				return 0, fmt.Errorf("第%d条分账明细%w", idx+1, err)
+			}
+			continue
+		}
+		cent, err := Int64ifyCent(royalty.Amount)
+		if err != nil {
+			return 0, fmt.Errorf("第%d条分账明细金额错误: %w", idx+1, err)
+		}
+		if cent < 0 {
+			return 0, fmt.Errorf("第%d条分账明细金额不能为负数", idx+1)
+		}
+		total += cent
+	}
+	return total, nil
+}
+
+// percentage 分账百分比，大于0且不超过100的整数
+func (royalty *OrderSettleParamOpenAPIRoyaltyDetailInfoPojo) percentage() (int64, error) {
+	percentage, err := strconv.ParseInt(royalty.AmountPercentage, 10, 64)
+	if err != nil || percentage <= 0 || percentage > 100 {
+		return 0, fmt.Errorf("分账百分比错误: %s", royalty.AmountPercentage)
+	}
+	return percentage, nil
 }
 
+// ValidateAmount 校验分账总金额不超过可分账金额，settleableAmount单位为元。
+// 按百分比分账的明细按settleableAmount的百分比计算，不足一分的部分向上取整，宁可多算也不漏算
+func (param *OrderSettleParam) ValidateAmount(settleableAmount string) error {
+	settleable, err := Int64ifyCent(settleableAmount)
+	if err != nil {
+		return fmt.Errorf("可分账金额错误: %w", err)
+	}
+	total, err := param.TotalCent()
+	if err != nil {
+		return err
+	}
+	for _, royalty := range param.RoyaltyParameters {
+		if len(royalty.AmountPercentage) == 0 {
+			continue
+		}
+		percentage, _ := royalty.percentage()
+		total += (settleable*percentage + 99) / 100
+	}
+	if total > settleable {
+		return fmt.Errorf("%w: 分账金额%s，可分账金额%s", ErrRoyaltyAmountExceeded, StringifyCent(total), StringifyCent(settleable))
+	}
+	return nil
+}
+
+// ErrRoyaltyAmountExceeded ...
+var (
+	ErrRoyaltyAmountExceeded = errors.New("分账金额超过可分账金额")
+)
+
 // OrderSettleResponse ...
 type OrderSettleResponse struct {
 	ResponseError
-	TradeNo string `json:"trade_no"` // 支付宝交易号
+	TradeNo  string `json:"trade_no"`  // 支付宝交易号
+	SettleNo string `json:"settle_no"` // 支付宝分账单号，可以根据该单号查询单次分账请求执行结果
 }
 
-// OrderSettle ...
-func (alipay *Alipay) OrderSettle(param *OrderSettleParam) (int, *FastpayRefundQueryResponse, error) {
+// OrderSettle 只校验分账明细的格式，不查询待分账金额，分账总额是否超出由支付宝校验；需要在本地校验时使用OrderSettleWithinLimit
+func (alipay *Alipay) OrderSettle(param *OrderSettleParam, fillList ...Fill) (int, *OrderSettleResponse, error) {
+	if len(param.OutRequestNo) == 0 {
+		return 0, nil, errors.New("结算请求流水号不能为空")
+	}
+
+	if len(param.TradeNo) == 0 {
+		return 0, nil, errors.New("支付宝订单号不能为空")
+	}
+
+	if len(param.RoyaltyParameters) == 0 {
+		return 0, nil, errors.New("分账明细不能为空")
+	}
+
+	if _, err := param.TotalCent(); err != nil {
+		return 0, nil, err
+	}
+
 	statusCode, body, err := alipay.OnRequest(
 		param,
 		MethodAlipayTradeOrderSettle,
+		fillList...,
 	)
 	if err != nil {
 		return 0, nil, err
 	}
-	refundQueryResponse := new(FastpayRefundQueryResponse)
-	if err := json.Unmarshal(body, refundQueryResponse); err != nil {
+	orderSettleResponse := new(OrderSettleResponse)
+	if err := json.Unmarshal(body, orderSettleResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, orderSettleResponse, nil
+}
+
+// OrderSettleWithinLimit 先查询交易的待分账金额，校验通过后再发起分账
+func (alipay *Alipay) OrderSettleWithinLimit(param *OrderSettleParam, fillList ...Fill) (int, *OrderSettleResponse, error) {
+	_, onsettleResponse, err := alipay.OrderOnsettleQuery(&OrderOnsettleQueryParam{
+		TradeNo: param.TradeNo,
+	}, fillList...)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !onsettleResponse.Success() {
+		return 0, nil, fmt.Errorf("查询待分账金额失败: %s %s", onsettleResponse.SubCode, onsettleResponse.SubMsg)
+	}
+	if err := param.ValidateAmount(onsettleResponse.UnsettledAmount); err != nil {
 		return 0, nil, err
 	}
-	return statusCode, refundQueryResponse, nil
+	return alipay.OrderSettle(param, fillList...)
 }
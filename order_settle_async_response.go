@@ -0,0 +1,64 @@
+package alipay
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// MsgMethodAlipayTradeOrderSettleNotify 异步分账结果通知的消息名称
+const (
+	MsgMethodAlipayTradeOrderSettleNotify = "alipay.trade.order.settle.notify"
+)
+
+// OrderSettleAsyncResponseContent ...
+type OrderSettleAsyncResponseContent struct {
+	TradeNo           string           `json:"trade_no"`            // 支付宝交易号
+	SettleNo          string           `json:"settle_no"`           // 支付宝分账单号
+	OutRequestNo      string           `json:"out_request_no"`      // 分账请求的外部请求号
+	OperationDt       string           `json:"operation_dt"`        // 分账受理时间
+	RoyaltyDetailList []*RoyaltyDetail `json:"royalty_detail_list"` // 分账明细
+}
+
+// OrderSettleAsyncResponse ...
+type OrderSettleAsyncResponse struct {
+	NotifyID     string
+	UTCTimestamp string
+	MsgMethod    string
+	AppID        string
+	Charset      string
+	Version      string
+	SignType     string
+	Sign         string
+	BizContent   *OrderSettleAsyncResponseContent
+}
+
+// ParseOrderSettleAsyncResponse 解析royalty_mode为async时的分账结果通知
+func (alipay *Alipay) ParseOrderSettleAsyncResponse(values url.Values) (*OrderSettleAsyncResponse, error) {
+	if err := alipay.asyncVerifyRequest(values); err != nil {
+		return nil, ErrAsyncVerify
+	}
+
+	orderSettleAsyncResponse := new(OrderSettleAsyncResponse)
+
+	orderSettleAsyncResponse.NotifyID = values.Get("notify_id")
+	orderSettleAsyncResponse.UTCTimestamp = values.Get("utc_timestamp")
+	orderSettleAsyncResponse.MsgMethod = values.Get("msg_method")
+	orderSettleAsyncResponse.AppID = values.Get("app_id")
+	orderSettleAsyncResponse.Charset = values.Get("charset")
+	orderSettleAsyncResponse.Version = values.Get("version")
+	orderSettleAsyncResponse.SignType = values.Get("sign_type")
+	orderSettleAsyncResponse.Sign = values.Get("sign")
+
+	if orderSettleAsyncResponse.MsgMethod != MsgMethodAlipayTradeOrderSettleNotify {
+		return nil, fmt.Errorf("不是分账结果通知: %s", orderSettleAsyncResponse.MsgMethod)
+	}
+
+	content := new(OrderSettleAsyncResponseContent)
+	if err := json.Unmarshal([]byte(values.Get("biz_content")), content); err != nil {
+		return nil, fmt.Errorf("分账结果通知反序列化失败: %w", err)
+	}
+	orderSettleAsyncResponse.BizContent = content
+
+	return orderSettleAsyncResponse, nil
+}
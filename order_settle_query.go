@@ -0,0 +1,88 @@
+// Package alipay https://opendocs.alipay.com/apis/api_1/alipay.trade.order.settle.query
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// RoyaltyDetailState ...
+const (
+	RoyaltyDetailStateSuccess    = "SUCCESS"
+	RoyaltyDetailStateFail       = "FAIL"
+	RoyaltyDetailStateProcessing = "PROCESSING"
+)
+
+// OrderSettleQueryParam settle_no和out_request_no+trade_no二选一
+type OrderSettleQueryParam struct {
+	SettleNo     string `json:"settle_no,omitempty"`      // 支付宝分账请求单号
+	OutRequestNo string `json:"out_request_no,omitempty"` // 外部请求号，需要和支付宝交易号一起传入
+	TradeNo      string `json:"trade_no,omitempty"`       // 支付宝交易号
+}
+
+// RoyaltyDetail 分账明细的处理结果
+type RoyaltyDetail struct {
+	OperationType string `json:"operation_type"` // 分账操作类型，replenish、replenish_refund、transfer、transfer_refund
+	ExecuteDt     string `json:"execute_dt"`     // 分账执行时间
+	TransOut      string `json:"trans_out"`      // 分账转出账号
+	TransOutType  string `json:"trans_out_type"` // 分账转出账号类型
+	TransIn       string `json:"trans_in"`       // 分账转入账号
+	TransInType   string `json:"trans_in_type"`  // 分账转入账号类型
+	Amount        string `json:"amount"`         // 分账金额，单位为元
+	State         string `json:"state"`          // 分账状态，SUCCESS、FAIL、PROCESSING
+	DetailID      string `json:"detail_id"`      // 分账明细单号
+	ErrorCode     string `json:"error_code"`     // 分账失败错误码
+	ErrorDesc     string `json:"error_desc"`     // 分账错误描述信息
+}
+
+// OrderSettleQueryResponse ...
+type OrderSettleQueryResponse struct {
+	ResponseError
+	OutRequestNo      string           `json:"out_request_no"`      // 分账受理时的外部请求号
+	OperationDt       string           `json:"operation_dt"`        // 分账受理时间
+	RoyaltyDetailList []*RoyaltyDetail `json:"royalty_detail_list"` // 分账明细
+}
+
+// IsAllSuccess ...
+func (resp *OrderSettleQueryResponse) IsAllSuccess() bool {
+	if !resp.Success() {
+		return false
+	}
+	for _, detail := range resp.RoyaltyDetailList {
+		if detail.State != RoyaltyDetailStateSuccess {
+			return false
+		}
+	}
+	return true
+}
+
+// IsProcessing 存在处理中的分账明细
+func (resp *OrderSettleQueryResponse) IsProcessing() bool {
+	for _, detail := range resp.RoyaltyDetailList {
+		if detail.State == RoyaltyDetailStateProcessing {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderSettleQuery ...
+func (alipay *Alipay) OrderSettleQuery(param *OrderSettleQueryParam, fillList ...Fill) (int, *OrderSettleQueryResponse, error) {
+	if len(param.SettleNo) == 0 && (len(param.OutRequestNo) == 0 || len(param.TradeNo) == 0) {
+		return 0, nil, errors.New("支付宝分账请求单号为空时，外部请求号和支付宝交易号都不能为空")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayTradeOrderSettleQuery,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	orderSettleQueryResponse := new(OrderSettleQueryResponse)
+	if err := json.Unmarshal(body, orderSettleQueryResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, orderSettleQueryResponse, nil
+}
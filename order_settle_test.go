@@ -0,0 +1,143 @@
+package alipay
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestOrderSettleValidateAmount(t *testing.T) {
+	royalty := func(amount string) *OrderSettleParamOpenAPIRoyaltyDetailInfoPojo {
+		return &OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{TransIn: "2088000000000002", Amount: amount}
+	}
+	percentage := func(amountPercentage string) *OrderSettleParamOpenAPIRoyaltyDetailInfoPojo {
+		return &OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{TransIn: "2088000000000003", AmountPercentage: amountPercentage}
+	}
+	cases := []struct {
+		name       string
+		royalties  []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo
+		settleable string
+		exceeded   bool
+		invalid    bool
+	}{
+		{name: "amount", royalties: []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{royalty("6.00"), royalty("4.00")}, settleable: "10.00"},
+		{name: "amount exceeded", royalties: []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{royalty("6.00"), royalty("4.01")}, settleable: "10.00", exceeded: true},
+		{name: "negative amount", royalties: []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{royalty("-1.00")}, settleable: "10.00", invalid: true},
+		{name: "amount precision", royalties: []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{royalty("1.001")}, settleable: "10.00", invalid: true},
+		{name: "settleable amount", royalties: []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{royalty("1.00")}, settleable: "abc", invalid: true},
+		{name: "percentage", royalties: []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{percentage("100")}, settleable: "10.00"},
+		{name: "percentage exceeded", royalties: []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{percentage("60"), percentage("50")}, settleable: "10.00", exceeded: true},
+		{name: "mixed", royalties: []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{percentage("50"), royalty("5.00")}, settleable: "10.00"},
+		{name: "percentage rounds up", royalties: []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{percentage("50"), royalty("0.50")}, settleable: "0.99", exceeded: true},
+		{name: "percentage over 100", royalties: []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{percentage("101")}, settleable: "10.00", invalid: true},
+		{name: "percentage fraction", royalties: []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{percentage("12.5")}, settleable: "10.00", invalid: true},
+		{name: "amount and percentage", royalties: []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{{TransIn: "2088000000000002", Amount: "1.00", AmountPercentage: "10"}}, settleable: "10.00", invalid: true},
+		{name: "neither amount nor percentage", royalties: []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{royalty("5.00"), royalty("")}, settleable: "10.00", invalid: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			param := &OrderSettleParam{RoyaltyParameters: c.royalties}
+			err := param.ValidateAmount(c.settleable)
+			switch {
+			case c.exceeded:
+				if !errors.Is(err, ErrRoyaltyAmountExceeded) {
+					t.Fatalf("err = %v, want ErrRoyaltyAmountExceeded", err)
+				}
+			case c.invalid:
+				if err == nil || errors.Is(err, ErrRoyaltyAmountExceeded) {
+					t.Fatalf("err = %v, want a validation error", err)
+				}
+			default:
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderSettleWithinLimit(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayTradeOrderOnsettleQuery, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{"unsettled_amount": "10.00"}
+	})
+	gateway.handle(MethodAlipayTradeOrderSettle, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{"trade_no": req.biz("trade_no"), "settle_no": "S001"}
+	})
+
+	param := &OrderSettleParam{
+		OutRequestNo: "S001",
+		TradeNo:      "2020010222001",
+		RoyaltyParameters: []*OrderSettleParamOpenAPIRoyaltyDetailInfoPojo{
+			{TransIn: "2088000000000002", Amount: "8.00"},
+			{TransIn: "2088000000000003", Amount: "3.00"},
+		},
+	}
+	if _, _, err := alipay.OrderSettleWithinLimit(param); !errors.Is(err, ErrRoyaltyAmountExceeded) {
+		t.Fatalf("err = %v, want ErrRoyaltyAmountExceeded", err)
+	}
+	if len(gateway.sent(MethodAlipayTradeOrderSettle)) != 0 {
+		t.Fatal("settle should not be sent when the amount is exceeded")
+	}
+
+	param.RoyaltyParameters[1].Amount = "2.00"
+	_, resp, err := alipay.OrderSettleWithinLimit(param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success() || resp.SettleNo != "S001" {
+		t.Fatalf("settle: %+v", resp)
+	}
+
+	param.OutRequestNo = "S002"
+	param.RoyaltyParameters[1].Amount = ""
+	if _, _, err := alipay.OrderSettle(param); err == nil {
+		t.Fatal("royalty without amount or percentage should be rejected")
+	}
+	if count := len(gateway.sent(MethodAlipayTradeOrderSettle)); count != 1 {
+		t.Fatalf("invalid params should not be sent, sent %d requests", count)
+	}
+}
+
+func TestOrderSettleQuery(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayTradeOrderSettleQuery, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{
+			"out_request_no": "S001",
+			"royalty_detail_list": []map[string]interface{}{
+				{"trans_in": "2088000000000002", "amount": "6.00", "state": RoyaltyDetailStateSuccess},
+				{"trans_in": "2088000000000003", "amount": "4.00", "state": RoyaltyDetailStateProcessing},
+			},
+		}
+	})
+
+	_, resp, err := alipay.OrderSettleQuery(&OrderSettleQueryParam{SettleNo: "S001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.IsAllSuccess() || !resp.IsProcessing() || len(resp.RoyaltyDetailList) != 2 {
+		t.Fatalf("resp: %+v", resp)
+	}
+}
+
+func TestParseOrderSettleAsyncResponse(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	values := testNotification(t, url.Values{
+		"msg_method":  {MsgMethodAlipayTradeOrderSettleNotify},
+		"notify_id":   {"N001"},
+		"biz_content": {`{"trade_no":"2020010222001","settle_no":"S001","out_request_no":"S001","royalty_detail_list":[{"trans_in":"2088000000000002","amount":"6.00","state":"SUCCESS"}]}`},
+	})
+
+	resp, err := alipay.ParseOrderSettleAsyncResponse(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.BizContent.SettleNo != "S001" || len(resp.BizContent.RoyaltyDetailList) != 1 || resp.BizContent.RoyaltyDetailList[0].Amount != "6.00" {
+		t.Fatalf("resp: %+v", resp.BizContent)
+	}
+
+	values = testNotification(t, url.Values{"msg_method": {"alipay.trade.refund.depositback.completed"}, "biz_content": {"{}"}})
+	if _, err := alipay.ParseOrderSettleAsyncResponse(values); err == nil {
+		t.Fatal("other msg_method should be rejected")
+	}
+}
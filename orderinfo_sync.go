@@ -0,0 +1,105 @@
+// Package alipay https://opendocs.alipay.com/apis/api_1/alipay.trade.orderinfo.sync
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// OrderinfoSyncBizType ...
+const (
+	// OrderinfoSyncBizTypeCreditAuth 信用授权场景下的订单状态同步
+	OrderinfoSyncBizTypeCreditAuth = "CREDIT_AUTH"
+
+	// OrderinfoSyncBizTypeCreditDeduct 信用代扣场景下的订单状态同步
+	OrderinfoSyncBizTypeCreditDeduct = "CREDIT_DEDUCT"
+
+	// OrderinfoSyncBizTypeLogistics 物流信息同步
+	OrderinfoSyncBizTypeLogistics = "LOGISTICS"
+)
+
+// OrderinfoSyncStatus ...
+const (
+	// OrderinfoSyncStatusComplete 用户已履约
+	OrderinfoSyncStatusComplete = "COMPLETE"
+
+	// OrderinfoSyncStatusClosed 履约取消
+	OrderinfoSyncStatusClosed = "CLOSED"
+
+	// OrderinfoSyncStatusViolated 用户已违约
+	OrderinfoSyncStatusViolated = "VIOLATED"
+)
+
+// OrderinfoSyncOrderBizInfo 同步的订单信息，序列化后作为order_biz_info传入
+type OrderinfoSyncOrderBizInfo struct {
+	Status        string `json:"status,omitempty"`         // 履约状态，COMPLETE、CLOSED、VIOLATED
+	TrackingNo    string `json:"tracking_no,omitempty"`    // 物流单号
+	LogisticsCode string `json:"logistics_code,omitempty"` // 物流公司编码
+}
+
+// OrderinfoSyncParam trade_no为空时根据out_trade_no查询交易号
+type OrderinfoSyncParam struct {
+	TradeNo       string                     `json:"trade_no"`                  // 支付宝交易号，和商户订单号不能同时为空
+	OutTradeNo    string                     `json:"-"`                         // 商户订单号
+	OrigRequestNo string                     `json:"orig_request_no,omitempty"` // 原始业务请求单号，如对某一次退款进行履约时，该字段传退款时的退款请求号
+	OutRequestNo  string                     `json:"out_request_no"`            // 外部请求号，商家自定义，标识一次同步请求
+	BizType       string                     `json:"biz_type"`                  // 交易信息同步对应的业务类型
+	OrderBizInfo  *OrderinfoSyncOrderBizInfo `json:"-"`                         // 商户传入同步信息
+	RawBizInfo    string                     `json:"order_biz_info,omitempty"`  // OrderBizInfo序列化后的结果，OrderBizInfo不为空时自动生成
+}
+
+// OrderinfoSyncResponse ...
+type OrderinfoSyncResponse struct {
+	ResponseError
+	TradeNo     string `json:"trade_no"`      // 支付宝交易号
+	OutTradeNo  string `json:"out_trade_no"`  // 商户订单号
+	BuyerUserID string `json:"buyer_user_id"` // 买家在支付宝的用户id
+}
+
+// OrderinfoSync 用于Pay、Create创建的交易的履约信息同步
+func (alipay *Alipay) OrderinfoSync(param *OrderinfoSyncParam, fillList ...Fill) (int, *OrderinfoSyncResponse, error) {
+	if len(param.OutRequestNo) == 0 {
+		return 0, nil, errors.New("外部请求号不能为空")
+	}
+
+	if len(param.BizType) == 0 {
+		return 0, nil, errors.New("业务类型不能为空")
+	}
+
+	if param.OrderBizInfo != nil {
+		bizInfo, err := json.Marshal(param.OrderBizInfo)
+		if err != nil {
+			return 0, nil, fmt.Errorf("同步信息序列化失败: %w", err)
+		}
+		param.RawBizInfo = string(bizInfo)
+	}
+
+	if len(param.TradeNo) == 0 {
+		if len(param.OutTradeNo) == 0 {
+			return 0, nil, errors.New("支付宝交易号和商户订单号不能同时为空")
+		}
+		_, queryResponse, err := alipay.Query(&QueryParam{OutTradeNo: param.OutTradeNo}, fillList...)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !queryResponse.Success() {
+			return 0, nil, fmt.Errorf("查询支付宝交易号失败: %s %s", queryResponse.SubCode, queryResponse.SubMsg)
+		}
+		param.TradeNo = queryResponse.TradeNo
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayTradeOrderinfoSync,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	orderinfoSyncResponse := new(OrderinfoSyncResponse)
+	if err := json.Unmarshal(body, orderinfoSyncResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, orderinfoSyncResponse, nil
+}
@@ -0,0 +1,76 @@
+package alipay
+
+import "testing"
+
+func handleOrderinfoSync(gateway *testGateway) {
+	gateway.handle(MethodAlipayTradeQuery, func(req *testRequest) map[string]interface{} {
+		if req.biz("out_trade_no") != "T001" {
+			return map[string]interface{}{"code": "40004", "msg": "Business Failed", "sub_code": "ACQ.TRADE_NOT_EXIST", "sub_msg": "交易不存在"}
+		}
+		return map[string]interface{}{"trade_no": "2020010222001", "out_trade_no": "T001", "trade_status": TradeSuccess}
+	})
+	gateway.handle(MethodAlipayTradeOrderinfoSync, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{"trade_no": req.biz("trade_no"), "out_trade_no": "T001"}
+	})
+}
+
+func TestOrderinfoSyncByOutTradeNo(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	handleOrderinfoSync(gateway)
+
+	_, resp, err := alipay.OrderinfoSync(&OrderinfoSyncParam{
+		OutTradeNo:   "T001",
+		OutRequestNo: "S001",
+		BizType:      OrderinfoSyncBizTypeCreditAuth,
+		OrderBizInfo: &OrderinfoSyncOrderBizInfo{Status: OrderinfoSyncStatusComplete},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success() || resp.TradeNo != "2020010222001" {
+		t.Fatalf("resp: %+v", resp)
+	}
+	req := gateway.sent(MethodAlipayTradeOrderinfoSync)[0]
+	if req.biz("trade_no") != "2020010222001" || req.biz("order_biz_info") != `{"status":"COMPLETE"}` {
+		t.Fatalf("biz_content: %v", req.Biz)
+	}
+	if _, ok := req.Biz["out_trade_no"]; ok {
+		t.Fatal("out_trade_no should not be sent to orderinfo sync")
+	}
+}
+
+func TestOrderinfoSyncByTradeNo(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	handleOrderinfoSync(gateway)
+
+	if _, _, err := alipay.OrderinfoSync(&OrderinfoSyncParam{TradeNo: "2020010222002", OutRequestNo: "S001", BizType: OrderinfoSyncBizTypeLogistics}); err != nil {
+		t.Fatal(err)
+	}
+	if len(gateway.sent(MethodAlipayTradeQuery)) != 0 {
+		t.Fatal("trade_no should not be queried when it is given")
+	}
+	if req := gateway.sent(MethodAlipayTradeOrderinfoSync)[0]; req.biz("trade_no") != "2020010222002" {
+		t.Fatalf("biz_content: %v", req.Biz)
+	}
+}
+
+func TestOrderinfoSyncInvalid(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	handleOrderinfoSync(gateway)
+
+	if _, _, err := alipay.OrderinfoSync(&OrderinfoSyncParam{OutTradeNo: "T002", OutRequestNo: "S001", BizType: OrderinfoSyncBizTypeCreditAuth}); err == nil {
+		t.Fatal("unknown out_trade_no should fail")
+	}
+	for _, param := range []*OrderinfoSyncParam{
+		{OutRequestNo: "S001", BizType: OrderinfoSyncBizTypeCreditAuth},
+		{TradeNo: "2020010222001", BizType: OrderinfoSyncBizTypeCreditAuth},
+		{TradeNo: "2020010222001", OutRequestNo: "S001"},
+	} {
+		if _, _, err := alipay.OrderinfoSync(param); err == nil {
+			t.Errorf("param %+v should be rejected", param)
+		}
+	}
+	if len(gateway.sent(MethodAlipayTradeOrderinfoSync)) != 0 {
+		t.Fatal("invalid params should not be sent")
+	}
+}
@@ -34,7 +34,7 @@ type PagePayParam struct {
 }
 
 // PagePay ...
-func (alipay *Alipay) PagePay(param *PagePayParam, notifyURL string, returnURL string) (string, error) {
+func (alipay *Alipay) PagePay(param *PagePayParam, notifyURL string, returnURL string, fillList ...Fill) (string, error) {
 	param.ProductCode = "FAST_INSTANT_TRADE_PAY"
 
 	if len(param.OutTradeNo) == 0 {
@@ -56,13 +56,12 @@ func (alipay *Alipay) PagePay(param *PagePayParam, notifyURL string, returnURL s
 	paramStr, err := alipay.MakeParam(
 		param,
 		MethodAlipayTradePagePay,
-		WithNotifyURL(notifyURL),
-		WithReturnURL(returnURL),
+		append([]Fill{WithNotifyURL(notifyURL), WithReturnURL(returnURL)}, fillList...)...,
 	)
 	if err != nil {
 		return "", fmt.Errorf("支付宝电脑网站支付构造参数失败: %w", err)
 	}
-	url, err := url.Parse(AlipayGateway)
+	url, err := url.Parse(alipay.Gateway())
 	if err != nil {
 		return "", fmt.Errorf("解析支付宝网关失败: %w", err)
 	}
@@ -3,6 +3,7 @@ package alipay
 
 import (
 	"encoding/json"
+	"errors"
 )
 
 // PayParamGoods ...
@@ -21,24 +22,34 @@ type PayParamExtendParams struct {
 	SysServiceProviderID string `json:"sys_service_provider_id"` // 系统商编号
 }
 
+// PayParamAgreementParams ...
+type PayParamAgreementParams struct {
+	AgreementNo   string `json:"agreement_no"`              // 支付宝系统中用以唯一标识用户签约记录的编号
+	AuthConfirmNo string `json:"auth_confirm_no,omitempty"` // 鉴权确认码，在需要做支付鉴权校验时，该参数不能为空
+	ApplyToken    string `json:"apply_token,omitempty"`     // 鉴权申请token，其格式和内容，由支付宝定义
+}
+
 // PayParam ...
 type PayParam struct {
-	OutTradeNo         string               `json:"out_trade_no"`        // 商户订单号
-	Scene              string               `json:"scene"`               // 支付场景
-	AuthCode           string               `json:"auth_code"`           // 支付授权码
-	ProductCode        string               `json:"product_code"`        // 销售产品码
-	Subject            string               `json:"subject"`             // 订单标题
-	BuyerID            string               `json:"buyer_id"`            // 买家的支付宝用户id
-	SellerID           string               `json:"seller_id "`          // 商户签约账号对应的支付宝用户ID
-	TotalAmount        string               `json:"total_amount"`        // 订单总金额
-	DiscountableAmount string               `json:"discountable_amount"` // 参与优惠计算的金额
-	Body               string               `json:"body"`                // 订单描述
-	GoodsDetailList    []*PayParamGoods     `json:" goods_detail"`       // 订单包含的商品列表信息
-	OperatorID         string               `json:"operator_id"`         // 商户操作员编号
-	StoreID            string               `json:"store_id"`            // 商户门店编号
-	TerminalID         string               `json:"terminal_id"`         // 商户机具终端编号
-	ExtendParams       PayParamExtendParams `json:"extend_params"`       // 业务扩展参数
-	TimeoutExpress     string               `json:"timeout_express"`     // 该笔订单允许的最晚付款时间
+	OutTradeNo         string                   `json:"out_trade_no"`                // 商户订单号
+	Scene              string                   `json:"scene"`                       // 支付场景
+	AuthCode           string                   `json:"auth_code"`                   // 支付授权码
+	ProductCode        string                   `json:"product_code"`                // 销售产品码
+	Subject            string                   `json:"subject"`                     // 订单标题
+	BuyerID            string                   `json:"buyer_id"`                    // 买家的支付宝用户id
+	SellerID           string                   `json:"seller_id"`                   // 商户签约账号对应的支付宝用户ID
+	TotalAmount        string                   `json:"total_amount"`                // 订单总金额
+	DiscountableAmount string                   `json:"discountable_amount"`         // 参与优惠计算的金额
+	Body               string                   `json:"body"`                        // 订单描述
+	GoodsDetailList    []*PayParamGoods         `json:"goods_detail,omitempty"`      // 订单包含的商品列表信息
+	OperatorID         string                   `json:"operator_id"`                 // 商户操作员编号
+	StoreID            string                   `json:"store_id"`                    // 商户门店编号
+	TerminalID         string                   `json:"terminal_id"`                 // 商户机具终端编号
+	ExtendParams       PayParamExtendParams     `json:"extend_params"`               // 业务扩展参数
+	TimeoutExpress     string                   `json:"timeout_express"`             // 该笔订单允许的最晚付款时间
+	AuthNo             string                   `json:"auth_no,omitempty"`           // 资金预授权单号，预授权转支付时必填
+	AuthConfirmMode    string                   `json:"auth_confirm_mode,omitempty"` // 预授权确认模式，COMPLETE转交易支付完成结束预授权，NOT_COMPLETE不结束
+	AgreementParams    *PayParamAgreementParams `json:"agreement_params,omitempty"`  // 代扣业务需要传入协议相关信息
 }
 
 // PayResponseFundBill ...
@@ -55,9 +66,9 @@ type PayResponseVoucherDetail struct {
 	Type                       string `json:"type"`                         // 当前有三种类型
 	Amount                     string `json:"amount"`                       // 优惠券面额
 	MerchantContribute         string `json:"merchant_contribute"`          // 商家出资
-	OtherContribute            string `json:" other_contribute"`            // 其他出资方出资金额
-	Meomo                      string `json:" memo"`                        // 优惠券备注信息
-	TemplateID                 string `json:" template_id"`                 // 券模板id
+	OtherContribute            string `json:"other_contribute"`             // 其他出资方出资金额
+	Meomo                      string `json:"memo"`                         // 优惠券备注信息
+	TemplateID                 string `json:"template_id"`                  // 券模板id
 	PurchaseBuyerContribute    string `json:"purchase_buyer_contribute"`    // 如果使用的这张券是用户购买的，则该字段代表用户在购买这张券时用户实际付款的金额
 	PurchaseMerchantContribute string `json:"purchase_merchant_contribute"` // 如果使用的这张券是用户购买的，则该字段代表用户在购买这张券时商户优惠的金额
 	PurchaseAntContribute      string `json:"purchase_ant_contribute"`      // 如果使用的这张券是用户购买的，则该字段代表用户在购买这张券时平台优惠的金额
@@ -80,18 +91,47 @@ type PayResponse struct {
 	StoreName           string                      `json:"store_name"`            // 发生支付交易的商户门店名称
 	BuyerUserID         string                      `json:"buyer_user_id"`         // 买家在支付宝的用户id
 	DiscountGoodsDetail string                      `json:"discount_goods_detail"` // 本次交易支付所使用的单品券优惠的商品优惠信息
-	VoucherDetailList   []*PayResponseVoucherDetail `json:" voucher_detail_list"`  // 本交易支付时使用的所有优惠券信息
+	VoucherDetailList   []*PayResponseVoucherDetail `json:"voucher_detail_list"`   // 本交易支付时使用的所有优惠券信息
 	BusinessParams      string                      `json:"business_params"`       // 商户传入业务信息
 	BuyerUserType       string                      `json:"buyer_user_type"`       // 买家用户类型
 }
 
+// PayFundAuth 预授权转支付，product_code固定为PRE_AUTH_ONLINE，需要传入auth_no
+func (alipay *Alipay) PayFundAuth(param *PayParam, notifyURL string, fillList ...Fill) (int, *PayResponse, error) {
+	if len(param.AuthNo) == 0 {
+		return 0, nil, errors.New("资金预授权单号不能为空")
+	}
+
+	if len(param.ProductCode) == 0 {
+		param.ProductCode = FundAuthProductCodePreAuthOnline
+	}
+
+	if len(param.AuthConfirmMode) == 0 {
+		param.AuthConfirmMode = AuthConfirmModeComplete
+	}
+
+	return alipay.Pay(param, notifyURL, "", fillList...)
+}
+
+// PayAgreement 协议代扣，product_code默认为GENERAL_WITHHOLDING，需要传入agreement_params
+func (alipay *Alipay) PayAgreement(param *PayParam, notifyURL string, fillList ...Fill) (int, *PayResponse, error) {
+	if param.AgreementParams == nil || len(param.AgreementParams.AgreementNo) == 0 {
+		return 0, nil, errors.New("代扣协议号不能为空")
+	}
+
+	if len(param.ProductCode) == 0 {
+		param.ProductCode = UserAgreementProductCodeGeneralWithholding
+	}
+
+	return alipay.Pay(param, notifyURL, "", fillList...)
+}
+
 // Pay ...
-func (alipay *Alipay) Pay(param *PayParam, notifyURL, appAuthToken string) (int, *PayResponse, error) {
+func (alipay *Alipay) Pay(param *PayParam, notifyURL, appAuthToken string, fillList ...Fill) (int, *PayResponse, error) {
 	statusCode, body, err := alipay.OnRequest(
 		param,
 		MethodAlipayTradePay,
-		WithNotifyURL(notifyURL),
-		WithAppAuthToken(appAuthToken),
+		append([]Fill{WithNotifyURL(notifyURL), WithAppAuthToken(appAuthToken)}, fillList...)...,
 	)
 	if err != nil {
 		return 0, nil, err
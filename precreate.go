@@ -23,21 +23,21 @@ type PrecreateParamExtendParams struct {
 
 // PrecreateParam ...
 type PrecreateParam struct {
-	OutTradeNo         string                     `json:"out_trade_no"`         // 商户订单号
-	SellerID           string                     `json:"seller_id"`            // 卖家支付宝用户ID
-	TotalAmount        string                     `json:"total_amount"`         // 订单总金额
-	DiscountableAmount string                     `json:"discountable_amount"`  // 参与优惠计算的金额
-	Subject            string                     `json:"subject"`              // 订单标题
-	GoodsDetailList    []*PrecreateParamGoods     `json:" goods_detail"`        // 订单包含的商品列表信息
-	Body               string                     `json:"body"`                 // 订单描述
-	OperatorID         string                     `json:"operator_id"`          // 商户操作员编号
-	StoreID            string                     `json:"store_id"`             // 商户门店编号
-	DisablePayChannels string                     `json:"disable_pay_channels"` // 禁用渠道，用户不可用指定渠道支付
-	EnablePayChannels  string                     `json:"enable_pay_channels"`  // 可用渠道，用户只能在指定渠道范围内支付
-	TerminalID         string                     `json:"terminal_id"`          // 商户机具终端编号
-	ExtendParams       PrecreateParamExtendParams `json:"extend_params"`        // 业务扩展参数
-	TimeoutExpress     string                     `json:"timeout_express"`      // 该笔订单允许的最晚付款时间
-	BusinessParams     string                     `json:"business_params"`      // 商户传入业务信息
+	OutTradeNo         string                     `json:"out_trade_no"`           // 商户订单号
+	SellerID           string                     `json:"seller_id"`              // 卖家支付宝用户ID
+	TotalAmount        string                     `json:"total_amount"`           // 订单总金额
+	DiscountableAmount string                     `json:"discountable_amount"`    // 参与优惠计算的金额
+	Subject            string                     `json:"subject"`                // 订单标题
+	GoodsDetailList    []*PrecreateParamGoods     `json:"goods_detail,omitempty"` // 订单包含的商品列表信息
+	Body               string                     `json:"body"`                   // 订单描述
+	OperatorID         string                     `json:"operator_id"`            // 商户操作员编号
+	StoreID            string                     `json:"store_id"`               // 商户门店编号
+	DisablePayChannels string                     `json:"disable_pay_channels"`   // 禁用渠道，用户不可用指定渠道支付
+	EnablePayChannels  string                     `json:"enable_pay_channels"`    // 可用渠道，用户只能在指定渠道范围内支付
+	TerminalID         string                     `json:"terminal_id"`            // 商户机具终端编号
+	ExtendParams       PrecreateParamExtendParams `json:"extend_params"`          // 业务扩展参数
+	TimeoutExpress     string                     `json:"timeout_express"`        // 该笔订单允许的最晚付款时间
+	BusinessParams     string                     `json:"business_params"`        // 商户传入业务信息
 }
 
 // PrecreateResponse ...
@@ -48,10 +48,11 @@ type PrecreateResponse struct {
 }
 
 // Precreate ...
-func (alipay *Alipay) Precreate(param *PrecreateParam) (int, *PrecreateResponse, error) {
+func (alipay *Alipay) Precreate(param *PrecreateParam, fillList ...Fill) (int, *PrecreateResponse, error) {
 	statusCode, body, err := alipay.OnRequest(
 		param,
 		MethodAlipayTradePrecreate,
+		fillList...,
 	)
 	if err != nil {
 		return 0, nil, err
@@ -40,10 +40,11 @@ type QueryResponse struct {
 }
 
 // Query ...
-func (alipay *Alipay) Query(param *QueryParam) (int, *QueryResponse, error) {
+func (alipay *Alipay) Query(param *QueryParam, fillList ...Fill) (int, *QueryResponse, error) {
 	statusCode, body, err := alipay.OnRequest(
 		param,
 		MethodAlipayTradeQuery,
+		fillList...,
 	)
 	if err != nil {
 		return 0, nil, err
@@ -6,9 +6,9 @@ import (
 
 // RefundParamGoodsDetail ...
 type RefundParamGoodsDetail struct {
-	GoodsID       string `json:" goods_id,omitempty"`       // 商品的编号
+	GoodsID       string `json:"goods_id,omitempty"`        // 商品的编号
 	AlipayGoodsID string `json:"alipay_goods_id,omitempty"` // 支付宝定义的统一商品编号
-	GoodsName     string `json:" goods_name,omitempty"`     // 商品名称
+	GoodsName     string `json:"goods_name,omitempty"`      // 商品名称
 	Quantity      string `json:"quantity,omitempty"`        // 商品数量
 	Price         string `json:"price,omitempty"`           // 商品单价，单位为元
 	GoodsCategory string `json:"goods_category,omitempty"`  // 商品类目
@@ -71,10 +71,11 @@ func (resp *RefundResponse) IsNotEqualTotal() bool {
 }
 
 // Refund ...
-func (alipay *Alipay) Refund(param *RefundParam) (int, *RefundResponse, error) {
+func (alipay *Alipay) Refund(param *RefundParam, fillList ...Fill) (int, *RefundResponse, error) {
 	statusCode, body, err := alipay.OnRequest(
 		param,
 		MethodAlipayTradeRefund,
+		fillList...,
 	)
 	if err != nil {
 		return 0, nil, err
@@ -0,0 +1,177 @@
+package alipay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// RetryCheck 判断一次请求用相同参数重试是否安全
+type RetryCheck func(inv *Invocation) bool
+
+// RetryAlways 查询、关闭、撤销等接口用相同参数重试总是安全的
+func RetryAlways(inv *Invocation) bool {
+	return true
+}
+
+// RetryWithBizKey 业务参数中key不为空时才可以重试，例如退款需要out_request_no，否则无法保证不重复退款
+func RetryWithBizKey(key string) RetryCheck {
+	return func(inv *Invocation) bool {
+		biz := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(inv.Param.BizContent), &biz); err != nil {
+			return false
+		}
+		value, ok := biz[key].(string)
+		return ok && len(value) > 0
+	}
+}
+
+// IdempotentMethods 默认可以重试的接口。
+// 不在其中的接口不会重试，例如alipay.trade.pay超时或者返回ACQ.SYSTEM_ERROR后，应该调用Query确认结果，未支付时调用Cancel
+var IdempotentMethods = map[string]RetryCheck{
+	MethodAlipayTradeQuery:                          RetryAlways,
+	MethodAlipayTradeFastpayRefundQuery:             RetryAlways,
+	MethodAlipayTradeOrderSettleQuery:               RetryAlways,
+	MethodAlipayTradeOrderOnsettleQuery:             RetryAlways,
+	MethodAlipayTradeRoyaltyRateQuery:               RetryAlways,
+	MethodAlipayTradeRoyaltyRelationBatchquery:      RetryAlways,
+	MethodAlipayFundTransCommonQuery:                RetryAlways,
+	MethodAlipayFundAccountQuery:                    RetryAlways,
+	MethodAlipayFundAuthOperationDetailQuery:        RetryAlways,
+	MethodAlipayUserAgreementQuery:                  RetryAlways,
+	MethodAlipayOpenAuthTokenAppQuery:               RetryAlways,
+	MethodAlipayDataDataserviceBillDownloadurlQuery: RetryAlways,
+	MethodAlipayDataBillBalanceQuery:                RetryAlways,
+	MethodAlipayDataBillAccountlogQuery:             RetryAlways,
+	MethodAlipayDataBillSellQuery:                   RetryAlways,
+	MethodAlipayDataBillBuyQuery:                    RetryAlways,
+
+	MethodAlipayTradeClose:            RetryAlways,
+	MethodAlipayTradeCancel:           RetryAlways,
+	MethodAlipayTradePrecreate:        RetryWithBizKey("out_trade_no"),
+	MethodAlipayTradeRefund:           RetryWithBizKey("out_request_no"),
+	MethodAlipayTradeOrderSettle:      RetryWithBizKey("out_request_no"),
+	MethodAlipayFundAuthOrderUnfreeze: RetryWithBizKey("out_request_no"),
+	MethodAlipayFundTransUniTransfer:  RetryWithBizKey("out_biz_no"),
+}
+
+// RetryPolicy 对幂等接口在网络错误、HTTP 5xx、20000以及ACQ.SYSTEM_ERROR时按指数退避加随机抖动重试
+type RetryPolicy struct {
+	MaxAttempts int                   // 包括第一次请求，默认为3
+	BaseDelay   time.Duration         // 第一次重试前的最长等待时间，默认为200毫秒，之后每次翻倍
+	MaxDelay    time.Duration         // 等待时间上限，默认为5秒
+	Methods     map[string]RetryCheck // 可以重试的接口，默认为IdempotentMethods
+	Sleep       func(time.Duration)   // 替换等待，用于测试；为空时用定时器等待，请求的ctx结束时立即返回
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// 零值字段使用的默认值
+const (
+	DefaultRetryMaxAttempts = 3
+	DefaultRetryBaseDelay   = 200 * time.Millisecond
+	DefaultRetryMaxDelay    = 5 * time.Second
+)
+
+// NewRetryPolicy 通过alipay.Use(NewRetryPolicy().Interceptor())启用，零值的RetryPolicy与之等价
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: DefaultRetryMaxAttempts,
+		BaseDelay:   DefaultRetryBaseDelay,
+		MaxDelay:    DefaultRetryMaxDelay,
+	}
+}
+
+// ShouldRetry 请求结果是否属于可以重试的失败，不考虑接口是否幂等。ctx取消或者超时导致的错误不重试
+func ShouldRetry(inv *Invocation, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		var urlErr *url.Error
+		return errors.As(err, &urlErr) || inv.StatusCode >= http.StatusInternalServerError
+	}
+	return inv.Response.Code == GatewayUnknownError || inv.Response.SubCode == SubCodeSystemError
+}
+
+func (policy *RetryPolicy) allow(inv *Invocation) bool {
+	methods := policy.Methods
+	if methods == nil {
+		methods = IdempotentMethods
+	}
+	check, ok := methods[inv.Method]
+	return ok && check(inv)
+}
+
+// delay 第attempt次重试前的等待时间，在上限的一半到上限之间随机
+func (policy *RetryPolicy) delay(attempt int, baseDelay, maxDelay time.Duration) time.Duration {
+	delay := baseDelay
+	for idx := 1; idx < attempt && delay < maxDelay; idx++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	policy.mu.Lock()
+	defer policy.mu.Unlock()
+	if policy.rand == nil {
+		policy.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return delay/2 + time.Duration(policy.rand.Int63n(int64(delay/2)+1))
+}
+
+// wait 等待delay，期间ctx结束时返回ctx.Err()
+func (policy *RetryPolicy) wait(ctx context.Context, delay time.Duration) error {
+	if policy.Sleep != nil {
+		policy.Sleep(delay)
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Interceptor 重试用尽后返回最后一次的结果或者错误，等待重试时ctx结束则返回ctx.Err()，零值字段使用默认值
+func (policy *RetryPolicy) Interceptor() Interceptor {
+	return func(inv *Invocation, next Invoker) error {
+		if !policy.allow(inv) {
+			return next(inv)
+		}
+		maxAttempts := policy.MaxAttempts
+		if maxAttempts <= 0 {
+			maxAttempts = DefaultRetryMaxAttempts
+		}
+		baseDelay := policy.BaseDelay
+		if baseDelay <= 0 {
+			baseDelay = DefaultRetryBaseDelay
+		}
+		maxDelay := policy.MaxDelay
+		if maxDelay <= 0 {
+			maxDelay = DefaultRetryMaxDelay
+		}
+		if maxDelay < baseDelay {
+			maxDelay = baseDelay
+		}
+		for attempt := 1; ; attempt++ {
+			inv.StatusCode, inv.Result, inv.Response = 0, nil, ResponseError{}
+			err := next(inv)
+			if attempt >= maxAttempts || !ShouldRetry(inv, err) {
+				return err
+			}
+			if err := policy.wait(inv.Param.ctx(), policy.delay(attempt, baseDelay, maxDelay)); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package alipay_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xiaojiaoyu100/alipay"
+	"github.com/xiaojiaoyu100/alipay/alipaytest"
+)
+
+// newRetryClient 零值的RetryPolicy，只替换Sleep记录等待时间
+func newRetryClient(t *testing.T) (*alipay.Alipay, *alipaytest.Gateway, *[]time.Duration) {
+	client, gateway := newTestClient(t)
+	delays := make([]time.Duration, 0)
+	policy := &alipay.RetryPolicy{
+		Sleep: func(delay time.Duration) {
+			delays = append(delays, delay)
+		},
+	}
+	client.Use(policy.Interceptor())
+	return client, gateway, &delays
+}
+
+func countRequests(gateway *alipaytest.Gateway, method string) int {
+	count := 0
+	for _, req := range gateway.Requests() {
+		if req.Method == method {
+			count++
+		}
+	}
+	return count
+}
+
+func TestRetryPolicyZeroValue(t *testing.T) {
+	client, gateway, delays := newRetryClient(t)
+	if _, _, err := client.Precreate(&alipay.PrecreateParam{OutTradeNo: "T001", TotalAmount: "1.00", Subject: "测试"}); err != nil {
+		t.Fatal(err)
+	}
+	gateway.AddFault(&alipaytest.FaultRule{Method: alipay.MethodAlipayTradeQuery, Kind: alipaytest.FaultUnknownError, Times: 2})
+
+	_, resp, err := client.Query(&alipay.QueryParam{OutTradeNo: "T001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success() || resp.TradeStatus != alipay.WaitBuyerPay {
+		t.Fatalf("query: %+v", resp)
+	}
+	if count := countRequests(gateway, alipay.MethodAlipayTradeQuery); count != alipay.DefaultRetryMaxAttempts {
+		t.Fatalf("query sent %d times, want %d", count, alipay.DefaultRetryMaxAttempts)
+	}
+	if len(*delays) != 2 {
+		t.Fatalf("delays = %v, want 2", *delays)
+	}
+	bounds := []time.Duration{alipay.DefaultRetryBaseDelay, 2 * alipay.DefaultRetryBaseDelay}
+	for idx, delay := range *delays {
+		if delay < bounds[idx]/2 || delay > bounds[idx] {
+			t.Errorf("delay #%d = %v, want between %v and %v", idx+1, delay, bounds[idx]/2, bounds[idx])
+		}
+	}
+}
+
+func TestRetryPolicyExhausted(t *testing.T) {
+	client, gateway, _ := newRetryClient(t)
+	gateway.AddFault(&alipaytest.FaultRule{Method: alipay.MethodAlipayTradeQuery, Kind: alipaytest.FaultSystemError})
+
+	_, resp, err := client.Query(&alipay.QueryParam{OutTradeNo: "T001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.SubCode != alipay.SubCodeSystemError {
+		t.Fatalf("last response should be returned, got %+v", resp)
+	}
+	if count := countRequests(gateway, alipay.MethodAlipayTradeQuery); count != alipay.DefaultRetryMaxAttempts {
+		t.Fatalf("query sent %d times, want %d", count, alipay.DefaultRetryMaxAttempts)
+	}
+}
+
+func TestRetryPolicySkipsPay(t *testing.T) {
+	client, gateway, delays := newRetryClient(t)
+	gateway.AddFault(&alipaytest.FaultRule{Method: alipay.MethodAlipayTradePay, Kind: alipaytest.FaultSystemError, Times: 1})
+
+	_, resp, err := client.Pay(&alipay.PayParam{
+		OutTradeNo:  "T001",
+		Scene:       "bar_code",
+		AuthCode:    "281234567890123456",
+		Subject:     "测试",
+		TotalAmount: "1.00",
+	}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.SubCode != alipay.SubCodeSystemError {
+		t.Fatalf("pay: %+v", resp)
+	}
+	if count := countRequests(gateway, alipay.MethodAlipayTradePay); count != 1 || len(*delays) != 0 {
+		t.Fatalf("pay must not be retried, sent %d times", count)
+	}
+}
+
+func TestRetryPolicyRefund(t *testing.T) {
+	client, gateway, _ := newRetryClient(t)
+	if _, _, err := client.Pay(&alipay.PayParam{
+		OutTradeNo:  "T001",
+		Scene:       "bar_code",
+		AuthCode:    "281234567890123456",
+		Subject:     "测试",
+		TotalAmount: "10.00",
+	}, "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	gateway.AddFault(&alipaytest.FaultRule{Method: alipay.MethodAlipayTradeRefund, Kind: alipaytest.FaultSystemError, Times: 1, Process: true})
+	_, resp, err := client.Refund(&alipay.RefundParam{OutTradeNo: "T001", RefundAmount: "1.00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.SubCode != alipay.SubCodeSystemError || countRequests(gateway, alipay.MethodAlipayTradeRefund) != 1 {
+		t.Fatalf("refund without out_request_no must not be retried: %+v", resp)
+	}
+
+	gateway.AddFault(&alipaytest.FaultRule{Method: alipay.MethodAlipayTradeRefund, Kind: alipaytest.FaultSystemError, Times: 1, Process: true})
+	_, resp, err = client.Refund(&alipay.RefundParam{OutTradeNo: "T001", RefundAmount: "2.00", OutRequestNo: "R002"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success() || countRequests(gateway, alipay.MethodAlipayTradeRefund) != 3 {
+		t.Fatalf("refund with out_request_no should be retried: %+v", resp)
+	}
+
+	trade, _ := gateway.Trade("T001")
+	if trade.RefundAmount != 300 || len(trade.Refunds) != 2 {
+		t.Fatalf("refunded %d in %d refunds, want 300 in 2", trade.RefundAmount, len(trade.Refunds))
+	}
+}
+
+func TestRetryPolicyCanceledWhileWaiting(t *testing.T) {
+	client, gateway := newTestClient(t)
+	client.Use((&alipay.RetryPolicy{BaseDelay: time.Hour, MaxDelay: time.Hour}).Interceptor())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client.Use(func(inv *alipay.Invocation, next alipay.Invoker) error {
+		err := next(inv)
+		cancel()
+		return err
+	})
+	gateway.AddFault(&alipaytest.FaultRule{Method: alipay.MethodAlipayTradeQuery, Kind: alipaytest.FaultSystemError})
+
+	start := time.Now()
+	_, _, err := client.Query(&alipay.QueryParam{OutTradeNo: "T001"}, alipay.WithContext(ctx))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("canceled retry waited %v", elapsed)
+	}
+	if count := countRequests(gateway, alipay.MethodAlipayTradeQuery); count != 1 {
+		t.Fatalf("query sent %d times, want 1", count)
+	}
+}
+
+func TestRetryPolicyDeadlineExceeded(t *testing.T) {
+	client, gateway, delays := newRetryClient(t)
+	gateway.AddFault(&alipaytest.FaultRule{Method: alipay.MethodAlipayTradeQuery, Kind: alipaytest.FaultSlow, Delay: 200 * time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err := client.Query(&alipay.QueryParam{OutTradeNo: "T001"}, alipay.WithContext(ctx))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if len(*delays) != 0 {
+		t.Fatalf("timed out request must not be retried, delays = %v", *delays)
+	}
+}
@@ -0,0 +1,40 @@
+// Package alipay https://opendocs.alipay.com/apis/api_1/alipay.trade.royalty.rate.query
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// RoyaltyRateQueryParam ...
+type RoyaltyRateQueryParam struct {
+	OutRequestNo string `json:"out_request_no"` // 外部请求号，由商家自定义
+}
+
+// RoyaltyRateQueryResponse ...
+type RoyaltyRateQueryResponse struct {
+	ResponseError
+	UserID   string `json:"user_id"`   // 分账收款方账号，即商户签约账号
+	MaxRatio int    `json:"max_ratio"` // 商户签约的最大分账比例，如30表示30%
+}
+
+// RoyaltyRateQuery ...
+func (alipay *Alipay) RoyaltyRateQuery(param *RoyaltyRateQueryParam, fillList ...Fill) (int, *RoyaltyRateQueryResponse, error) {
+	if len(param.OutRequestNo) == 0 {
+		return 0, nil, errors.New("外部请求号不能为空")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayTradeRoyaltyRateQuery,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	royaltyRateQueryResponse := new(RoyaltyRateQueryResponse)
+	if err := json.Unmarshal(body, royaltyRateQueryResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, royaltyRateQueryResponse, nil
+}
@@ -0,0 +1,51 @@
+// Package alipay https://opendocs.alipay.com/apis/api_1/alipay.trade.royalty.relation.batchquery
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// RoyaltyRelationBatchqueryParam ...
+type RoyaltyRelationBatchqueryParam struct {
+	PageNum      int    `json:"page_num,omitempty"`  // 页码，从1开始
+	PageSize     int    `json:"page_size,omitempty"` // 页面大小，每页记录数，取值范围是[1,100]
+	OutRequestNo string `json:"out_request_no"`      // 外部请求号，由商家自定义
+}
+
+// RoyaltyRelationBatchqueryResponse ...
+type RoyaltyRelationBatchqueryResponse struct {
+	ResponseError
+	ResultCode      string           `json:"result_code"`       // 查询结果，SUCCESS或FAIL
+	ReceiverList    []*RoyaltyEntity `json:"receiver_list"`     // 分账接收方列表
+	TotalPageNum    int              `json:"total_page_num"`    // 总页数
+	TotalRecordNum  int              `json:"total_record_num"`  // 总记录数
+	CurrentPageNum  int              `json:"current_page_num"`  // 当前页数
+	CurrentPageSize int              `json:"current_page_size"` // 当前页面大小
+}
+
+// HasNextPage ...
+func (resp *RoyaltyRelationBatchqueryResponse) HasNextPage() bool {
+	return resp.CurrentPageNum < resp.TotalPageNum
+}
+
+// RoyaltyRelationBatchquery ...
+func (alipay *Alipay) RoyaltyRelationBatchquery(param *RoyaltyRelationBatchqueryParam, fillList ...Fill) (int, *RoyaltyRelationBatchqueryResponse, error) {
+	if len(param.OutRequestNo) == 0 {
+		return 0, nil, errors.New("外部请求号不能为空")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayTradeRoyaltyRelationBatchquery,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	royaltyRelationBatchqueryResponse := new(RoyaltyRelationBatchqueryResponse)
+	if err := json.Unmarshal(body, royaltyRelationBatchqueryResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, royaltyRelationBatchqueryResponse, nil
+}
@@ -0,0 +1,65 @@
+// Package alipay https://opendocs.alipay.com/apis/api_1/alipay.trade.royalty.relation.bind
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// RoyaltyRelationResultCode ...
+const (
+	RoyaltyRelationResultCodeSuccess = "SUCCESS"
+	RoyaltyRelationResultCodeFail    = "FAIL"
+)
+
+// RoyaltyEntity 分账接收方
+type RoyaltyEntity struct {
+	Type          string `json:"type"`                      // 分账接收方方类型，userId、loginName或openId
+	Account       string `json:"account"`                   // 分账接收方账号
+	Name          string `json:"name,omitempty"`            // 分账接收方真实姓名，绑定分账关系时type为loginName必填
+	Memo          string `json:"memo,omitempty"`            // 分账关系描述
+	LoginName     string `json:"login_name,omitempty"`      // 作为分账方的支付宝登录号
+	BindLoginName string `json:"bind_login_name,omitempty"` // 被绑定方的支付宝登录号
+}
+
+// RoyaltyRelationBindParam ...
+type RoyaltyRelationBindParam struct {
+	ReceiverList []*RoyaltyEntity `json:"receiver_list"`  // 分账接收方列表，单次传入最多20个
+	OutRequestNo string           `json:"out_request_no"` // 外部请求号，由商家自定义
+}
+
+// RoyaltyRelationBindResponse ...
+type RoyaltyRelationBindResponse struct {
+	ResponseError
+	ResultCode string `json:"result_code"` // 分账关系绑定结果，SUCCESS或FAIL
+}
+
+// IsSuccess ...
+func (resp *RoyaltyRelationBindResponse) IsSuccess() bool {
+	return resp.Success() && resp.ResultCode == RoyaltyRelationResultCodeSuccess
+}
+
+// RoyaltyRelationBind ...
+func (alipay *Alipay) RoyaltyRelationBind(param *RoyaltyRelationBindParam, fillList ...Fill) (int, *RoyaltyRelationBindResponse, error) {
+	if len(param.OutRequestNo) == 0 {
+		return 0, nil, errors.New("外部请求号不能为空")
+	}
+
+	if len(param.ReceiverList) == 0 || len(param.ReceiverList) > 20 {
+		return 0, nil, errors.New("分账接收方数量应为1到20个")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayTradeRoyaltyRelationBind,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	royaltyRelationBindResponse := new(RoyaltyRelationBindResponse)
+	if err := json.Unmarshal(body, royaltyRelationBindResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, royaltyRelationBindResponse, nil
+}
@@ -0,0 +1,49 @@
+// Package alipay https://opendocs.alipay.com/apis/api_1/alipay.trade.royalty.relation.unbind
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// RoyaltyRelationUnbindParam ...
+type RoyaltyRelationUnbindParam struct {
+	ReceiverList []*RoyaltyEntity `json:"receiver_list"`  // 分账接收方列表，单次传入最多20个
+	OutRequestNo string           `json:"out_request_no"` // 外部请求号，由商家自定义
+}
+
+// RoyaltyRelationUnbindResponse ...
+type RoyaltyRelationUnbindResponse struct {
+	ResponseError
+	ResultCode string `json:"result_code"` // 分账关系解绑结果，SUCCESS或FAIL
+}
+
+// IsSuccess ...
+func (resp *RoyaltyRelationUnbindResponse) IsSuccess() bool {
+	return resp.Success() && resp.ResultCode == RoyaltyRelationResultCodeSuccess
+}
+
+// RoyaltyRelationUnbind ...
+func (alipay *Alipay) RoyaltyRelationUnbind(param *RoyaltyRelationUnbindParam, fillList ...Fill) (int, *RoyaltyRelationUnbindResponse, error) {
+	if len(param.OutRequestNo) == 0 {
+		return 0, nil, errors.New("外部请求号不能为空")
+	}
+
+	if len(param.ReceiverList) == 0 || len(param.ReceiverList) > 20 {
+		return 0, nil, errors.New("分账接收方数量应为1到20个")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayTradeRoyaltyRelationUnbind,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	royaltyRelationUnbindResponse := new(RoyaltyRelationUnbindResponse)
+	if err := json.Unmarshal(body, royaltyRelationUnbindResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, royaltyRelationUnbindResponse, nil
+}
@@ -0,0 +1,100 @@
+// Package alipay https://opendocs.alipay.com/apis/api_9/alipay.system.oauth.token
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// PublicAppAuthorizeGateway 用户信息授权地址
+const (
+	PublicAppAuthorizeGateway = "https://openauth.alipay.com/oauth2/publicAppAuthorize.htm"
+)
+
+// OAuthScope ...
+const (
+	// OAuthScopeAuthBase 静默授权，只能获取用户的user_id
+	OAuthScopeAuthBase = "auth_base"
+
+	// OAuthScopeAuthUser 主动授权，可以获取用户的基础信息
+	OAuthScopeAuthUser = "auth_user"
+)
+
+// SystemOAuthTokenParam 该接口的参数不放在biz_content中，而是作为公共参数传递
+type SystemOAuthTokenParam struct {
+	GrantType    string // authorization_code表示用授权码换取，refresh_token表示刷新
+	Code         string // 授权码，用户对应用授权后得到
+	RefreshToken string // 刷新令牌，上次换取访问令牌时得到
+}
+
+// SystemOAuthTokenResponse ...
+type SystemOAuthTokenResponse struct {
+	ResponseError
+	UserID       string      `json:"user_id"`       // 支付宝用户的唯一userId
+	OpenID       string      `json:"open_id"`       // 支付宝用户在应用下的唯一标识
+	AccessToken  string      `json:"access_token"`  // 访问令牌，用于获取用户信息
+	ExpiresIn    json.Number `json:"expires_in"`    // 访问令牌的有效时间，单位是秒
+	RefreshToken string      `json:"refresh_token"` // 刷新令牌，通过该令牌可以刷新access_token
+	ReExpiresIn  json.Number `json:"re_expires_in"` // 刷新令牌的有效时间，单位是秒
+	AuthStart    string      `json:"auth_start"`    // 授权token开始时间，作为有效期计算的起点
+}
+
+// SystemOAuthToken ...
+func (alipay *Alipay) SystemOAuthToken(param *SystemOAuthTokenParam, fillList ...Fill) (int, *SystemOAuthTokenResponse, error) {
+	switch param.GrantType {
+	case GrantTypeAuthorizationCode:
+		if len(param.Code) == 0 {
+			return 0, nil, errors.New("授权码不能为空")
+		}
+	case GrantTypeRefreshToken:
+		if len(param.RefreshToken) == 0 {
+			return 0, nil, errors.New("刷新令牌不能为空")
+		}
+	default:
+		return 0, nil, fmt.Errorf("不支持的授权方式: %s", param.GrantType)
+	}
+
+	paramFillList := []Fill{WithExtraParam("grant_type", param.GrantType)}
+	if len(param.Code) > 0 {
+		paramFillList = append(paramFillList, WithExtraParam("code", param.Code))
+	}
+	if len(param.RefreshToken) > 0 {
+		paramFillList = append(paramFillList, WithExtraParam("refresh_token", param.RefreshToken))
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		nil,
+		MethodAlipaySystemOAuthToken,
+		append(paramFillList, fillList...)...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	systemOAuthTokenResponse := new(SystemOAuthTokenResponse)
+	if err := json.Unmarshal(body, systemOAuthTokenResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, systemOAuthTokenResponse, nil
+}
+
+// PublicAppAuthorizeURL 生成用户授权页面地址，scope为OAuthScopeAuthBase或OAuthScopeAuthUser，多个scope用逗号分隔
+func (alipay *Alipay) PublicAppAuthorizeURL(scope, redirectURI, state string) (string, error) {
+	if len(scope) == 0 {
+		return "", errors.New("授权范围不能为空")
+	}
+	u, err := url.Parse(PublicAppAuthorizeGateway)
+	if err != nil {
+		return "", fmt.Errorf("解析支付宝授权地址失败: %w", err)
+	}
+	values := url.Values{}
+	values.Set("app_id", alipay.appID)
+	values.Set("scope", scope)
+	values.Set("redirect_uri", redirectURI)
+	if len(state) > 0 {
+		values.Set("state", state)
+	}
+	u.RawQuery = values.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,75 @@
+package alipay
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestSystemOAuthToken(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipaySystemOAuthToken, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{
+			"user_id":       "2088000000000001",
+			"access_token":  "access-" + req.Values.Get("code") + req.Values.Get("refresh_token"),
+			"expires_in":    3600,
+			"refresh_token": "refresh-token",
+			"re_expires_in": 7200,
+		}
+	})
+
+	_, resp, err := alipay.SystemOAuthToken(&SystemOAuthTokenParam{GrantType: GrantTypeAuthorizationCode, Code: "C1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success() || resp.AccessToken != "access-C1" || resp.ExpiresIn.String() != "3600" {
+		t.Fatalf("resp: %+v", resp)
+	}
+	req := gateway.sent(MethodAlipaySystemOAuthToken)[0]
+	if req.Values.Get("grant_type") != GrantTypeAuthorizationCode {
+		t.Fatalf("grant_type = %q", req.Values.Get("grant_type"))
+	}
+	if _, ok := req.Values["biz_content"]; ok {
+		t.Fatal("biz_content should not be sent")
+	}
+
+	_, resp, err = alipay.SystemOAuthToken(&SystemOAuthTokenParam{GrantType: GrantTypeRefreshToken, RefreshToken: "R1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.AccessToken != "access-R1" {
+		t.Fatalf("resp: %+v", resp)
+	}
+
+	for _, param := range []*SystemOAuthTokenParam{
+		{GrantType: GrantTypeAuthorizationCode},
+		{GrantType: GrantTypeRefreshToken},
+		{GrantType: "password", Code: "C1"},
+	} {
+		if _, _, err := alipay.SystemOAuthToken(param); err == nil {
+			t.Errorf("param %+v should be rejected", param)
+		}
+	}
+	if count := len(gateway.sent(MethodAlipaySystemOAuthToken)); count != 2 {
+		t.Fatalf("invalid params should not be sent, sent %d requests", count)
+	}
+}
+
+func TestPublicAppAuthorizeURL(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	rawURL, err := alipay.PublicAppAuthorizeURL(OAuthScopeAuthUser, "https://example.com/callback", "S1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("app_id") != alipay.AppID() || query.Get("scope") != OAuthScopeAuthUser ||
+		query.Get("redirect_uri") != "https://example.com/callback" || query.Get("state") != "S1" {
+		t.Fatalf("url = %s", rawURL)
+	}
+	if _, err := alipay.PublicAppAuthorizeURL("", "https://example.com/callback", ""); err == nil {
+		t.Fatal("empty scope should be rejected")
+	}
+}
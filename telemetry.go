@@ -0,0 +1,125 @@
+package alipay
+
+import (
+	"context"
+	"time"
+)
+
+// Span 一段追踪，由调用方适配OpenTelemetry等实现
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Tracer 创建Span，没有设置时不追踪。返回的context.Context包含新建的Span，适配OpenTelemetry时对应tracer.Start(ctx, name)。
+// OnRequest的父Span来自WithContext传入的ctx，DownloadBill来自DownloadBillContext的ctx，FetchBill来自WithBillFill(WithContext(ctx))；
+// 没有传入ctx的调用以及异步通知验签（ParseAsyncResponse等没有ctx参数）创建的都是没有父Span的根Span
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Metrics 计数器和直方图，labels可以直接转换成prometheus.Labels，没有设置时不统计
+type Metrics interface {
+	IncCounter(name string, labels map[string]string)
+	ObserveHistogram(name string, value float64, labels map[string]string)
+}
+
+// Span名称
+const (
+	SpanOnRequest    = "alipay.request"
+	SpanDownloadBill = "alipay.bill.download"
+	SpanNotify       = "alipay.notify"
+)
+
+// 指标名称，labels见各个指标的说明
+const (
+	MetricRequestsTotal           = "alipay_requests_total"                 // method、outcome
+	MetricRequestDuration         = "alipay_request_duration_seconds"       // method、outcome
+	MetricBillDownloadsTotal      = "alipay_bill_downloads_total"           // outcome
+	MetricBillDownloadDuration    = "alipay_bill_download_duration_seconds" // outcome
+	MetricNotificationsTotal      = "alipay_notifications_total"            // notify_type、outcome，notify_type见NotifyTypeLabels
+	MetricSignVerifyFailuresTotal = "alipay_sign_verify_failures_total"     // source、method，source为notify时method为notify_type，取值同NotifyTypeLabels
+)
+
+// outcome的取值
+const (
+	OutcomeSuccess       = "success"        // 网关返回10000，或者下载、验签成功
+	OutcomeBusinessError = "business_error" // 网关返回了其他code
+	OutcomeError         = "error"          // 网络错误、验签失败等没有拿到可信结果的情况
+)
+
+// NotifyTypeLabels 可以作为指标label的notify_type，其他值统一记为other。
+// notify_type在验签之前就要记录，任何人都可以向notify_url提交任意值，不能直接用作label
+var NotifyTypeLabels = map[string]bool{
+	"trade_status_sync":        true,
+	NotifyTypeFundAuthFreeze:   true,
+	NotifyTypeFundAuthUnfreeze: true,
+	NotifyTypeDutUserSign:      true,
+	NotifyTypeDutUserUnsign:    true,
+}
+
+// notifyTypeLabel ...
+func notifyTypeLabel(notifyType string) string {
+	if NotifyTypeLabels[notifyType] {
+		return notifyType
+	}
+	return "other"
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key string, value interface{}) {}
+func (noopSpan) RecordError(err error)                      {}
+func (noopSpan) End()                                       {}
+
+// SetTracer 设置后OnRequest、DownloadBill以及异步通知验签会创建Span
+func (alipay *Alipay) SetTracer(tracer Tracer) {
+	alipay.tracer = tracer
+}
+
+// SetMetrics 设置后按接口和结果统计请求次数和耗时，以及验签失败次数
+func (alipay *Alipay) SetMetrics(metrics Metrics) {
+	alipay.metrics = metrics
+}
+
+func (alipay *Alipay) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if alipay.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return alipay.tracer.StartSpan(ctx, name)
+}
+
+func (alipay *Alipay) incCounter(name string, labels map[string]string) {
+	if alipay.metrics != nil {
+		alipay.metrics.IncCounter(name, labels)
+	}
+}
+
+func (alipay *Alipay) observeHistogram(name string, value float64, labels map[string]string) {
+	if alipay.metrics != nil {
+		alipay.metrics.ObserveHistogram(name, value, labels)
+	}
+}
+
+// observeInvocation 结束OnRequest的Span并记录指标
+func (alipay *Alipay) observeInvocation(span Span, inv *Invocation, err error, elapsed time.Duration) {
+	outcome := OutcomeSuccess
+	switch {
+	case err != nil:
+		outcome = OutcomeError
+		span.RecordError(err)
+	case inv.Response.Code != GatewaySuccess:
+		outcome = OutcomeBusinessError
+	}
+	span.SetAttribute("alipay.method", inv.Method)
+	span.SetAttribute("alipay.code", inv.Response.Code)
+	span.SetAttribute("alipay.sub_code", inv.Response.SubCode)
+	span.SetAttribute("http.status_code", inv.StatusCode)
+	span.SetAttribute("alipay.outcome", outcome)
+	span.End()
+
+	labels := map[string]string{"method": inv.Method, "outcome": outcome}
+	alipay.incCounter(MetricRequestsTotal, labels)
+	alipay.observeHistogram(MetricRequestDuration, elapsed.Seconds(), labels)
+}
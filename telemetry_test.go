@@ -0,0 +1,200 @@
+package alipay_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/xiaojiaoyu100/alipay"
+	"github.com/xiaojiaoyu100/alipay/alipaytest"
+)
+
+type testSpanKey struct{}
+
+type testSpan struct {
+	name       string
+	parent     *testSpan
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (span *testSpan) SetAttribute(key string, value interface{}) { span.attributes[key] = value }
+func (span *testSpan) RecordError(err error)                      { span.err = err }
+func (span *testSpan) End()                                       { span.ended = true }
+
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (tracer *testTracer) StartSpan(ctx context.Context, name string) (context.Context, alipay.Span) {
+	span := &testSpan{name: name, attributes: make(map[string]interface{})}
+	span.parent, _ = ctx.Value(testSpanKey{}).(*testSpan)
+	tracer.mu.Lock()
+	tracer.spans = append(tracer.spans, span)
+	tracer.mu.Unlock()
+	return context.WithValue(ctx, testSpanKey{}, span), span
+}
+
+func (tracer *testTracer) find(name string) []*testSpan {
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	spans := make([]*testSpan, 0)
+	for _, span := range tracer.spans {
+		if span.name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+type testMetrics struct {
+	mu       sync.Mutex
+	counters map[string][]map[string]string
+	observed map[string]int
+}
+
+func newTestMetrics() *testMetrics {
+	return &testMetrics{
+		counters: make(map[string][]map[string]string),
+		observed: make(map[string]int),
+	}
+}
+
+func (metrics *testMetrics) IncCounter(name string, labels map[string]string) {
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	metrics.counters[name] = append(metrics.counters[name], labels)
+}
+
+func (metrics *testMetrics) ObserveHistogram(name string, value float64, labels map[string]string) {
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	metrics.observed[name]++
+}
+
+func (metrics *testMetrics) labels(name string) []map[string]string {
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	return metrics.counters[name]
+}
+
+func newTelemetryClient(t *testing.T) (*alipay.Alipay, *alipaytest.Gateway, *testTracer, *testMetrics) {
+	client, gateway := newTestClient(t)
+	tracer, metrics := &testTracer{}, newTestMetrics()
+	client.SetTracer(tracer)
+	client.SetMetrics(metrics)
+	return client, gateway, tracer, metrics
+}
+
+func TestTelemetryRequest(t *testing.T) {
+	client, gateway, tracer, metrics := newTelemetryClient(t)
+
+	ctx, parent := tracer.StartSpan(context.Background(), "handler")
+	if _, _, err := client.Query(&alipay.QueryParam{OutTradeNo: "T001"}, alipay.WithContext(ctx)); err != nil {
+		t.Fatal(err)
+	}
+	gateway.AddFault(&alipaytest.FaultRule{Method: alipay.MethodAlipayTradeQuery, Kind: alipaytest.FaultWrongSign})
+	if _, _, err := client.Query(&alipay.QueryParam{OutTradeNo: "T001"}); err == nil {
+		t.Fatal("wrong sign should fail")
+	}
+
+	spans := tracer.find(alipay.SpanOnRequest)
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	if spans[0].parent != parent || spans[1].parent != nil {
+		t.Errorf("request span should be parented by the ctx passed in WithContext")
+	}
+	if !spans[0].ended || spans[0].attributes["alipay.outcome"] != alipay.OutcomeBusinessError || spans[0].attributes["alipay.sub_code"] != "ACQ.TRADE_NOT_EXIST" {
+		t.Errorf("span 1: %+v", spans[0])
+	}
+	if spans[1].err == nil || spans[1].attributes["alipay.outcome"] != alipay.OutcomeError {
+		t.Errorf("span 2: %+v", spans[1])
+	}
+
+	requests := metrics.labels(alipay.MetricRequestsTotal)
+	if len(requests) != 2 || requests[0]["method"] != alipay.MethodAlipayTradeQuery || requests[1]["outcome"] != alipay.OutcomeError {
+		t.Errorf("requests_total labels: %v", requests)
+	}
+	if metrics.observed[alipay.MetricRequestDuration] != 2 {
+		t.Errorf("request_duration observed %d times, want 2", metrics.observed[alipay.MetricRequestDuration])
+	}
+	failures := metrics.labels(alipay.MetricSignVerifyFailuresTotal)
+	if len(failures) != 1 || failures[0]["source"] != "response" || failures[0]["method"] != alipay.MethodAlipayTradeQuery {
+		t.Errorf("sign_verify_failures_total labels: %v", failures)
+	}
+}
+
+func TestTelemetryFetchBill(t *testing.T) {
+	client, gateway, tracer, metrics := newTelemetryClient(t)
+	data, err := (&alipaytest.BillArchive{
+		BillDate: "2020-01-02",
+		TradeList: []*alipay.BillTradeEntry{
+			{TradeNo: "2020010222001", OutTradeNo: "T001", BusinessType: alipay.CsvBusinessTypeTrade, TotalAmount: "10.00", ReceiptAmount: "10.00"},
+		},
+	}).Zip(alipay.BillTypeTrade)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gateway.AddBill(alipay.BillTypeTrade, "2020-01-02", data)
+
+	ctx, parent := tracer.StartSpan(context.Background(), "job")
+	if _, err := client.FetchBill("2020-01-02", alipay.BillTypeTrade, alipay.WithBillFill(alipay.WithContext(ctx))); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{alipay.SpanOnRequest, alipay.SpanDownloadBill} {
+		spans := tracer.find(name)
+		if len(spans) != 1 || spans[0].parent != parent {
+			t.Errorf("%s should be parented by the FetchBill ctx", name)
+		}
+	}
+	downloads := metrics.labels(alipay.MetricBillDownloadsTotal)
+	if len(downloads) != 1 || downloads[0]["outcome"] != alipay.OutcomeSuccess {
+		t.Errorf("bill_downloads_total labels: %v", downloads)
+	}
+}
+
+func TestTelemetryNotify(t *testing.T) {
+	client, gateway, tracer, metrics := newTelemetryClient(t)
+	if _, _, err := client.Precreate(&alipay.PrecreateParam{OutTradeNo: "T001", TotalAmount: "1.00", Subject: "测试"}); err != nil {
+		t.Fatal(err)
+	}
+	trade, _ := gateway.Trade("T001")
+	values, err := gateway.TradeNotification(trade)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ParseAsyncResponse(values); err != nil {
+		t.Fatal(err)
+	}
+
+	values.Set("notify_type", "attacker_controlled_value")
+	if _, err := client.ParseAsyncResponse(values); err != alipay.ErrAsyncVerify {
+		t.Fatalf("err = %v, want ErrAsyncVerify", err)
+	}
+
+	notifications := metrics.labels(alipay.MetricNotificationsTotal)
+	if len(notifications) != 2 {
+		t.Fatalf("notifications_total labels: %v", notifications)
+	}
+	if notifications[0]["notify_type"] != alipaytest.NotifyTypeTradeStatusSync || notifications[0]["outcome"] != alipay.OutcomeSuccess {
+		t.Errorf("notification 1 labels: %v", notifications[0])
+	}
+	if notifications[1]["notify_type"] != "other" || notifications[1]["outcome"] != alipay.OutcomeError {
+		t.Errorf("unknown notify_type should be recorded as other: %v", notifications[1])
+	}
+	failures := metrics.labels(alipay.MetricSignVerifyFailuresTotal)
+	if len(failures) != 1 || failures[0]["source"] != "notify" || failures[0]["method"] != "other" {
+		t.Errorf("sign_verify_failures_total labels: %v", failures)
+	}
+
+	spans := tracer.find(alipay.SpanNotify)
+	if len(spans) != 2 || spans[1].attributes["alipay.notify_type"] != "other" || spans[1].err == nil {
+		t.Fatalf("notify spans: %+v", spans)
+	}
+	if _, ok := spans[1].attributes["alipay.notify_id"]; ok {
+		t.Error("notify_id of an unverified notification should not be recorded")
+	}
+}
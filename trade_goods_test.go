@@ -0,0 +1,88 @@
+package alipay
+
+import (
+	"testing"
+)
+
+// goodsDetail 返回biz_content中的goods_detail
+func goodsDetail(req *testRequest) []interface{} {
+	goodsList, _ := req.Biz["goods_detail"].([]interface{})
+	return goodsList
+}
+
+func TestTradeGoodsDetail(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayTradePay, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{
+			"out_trade_no": req.biz("out_trade_no"),
+			"trade_no":     "2020010122001",
+			"voucher_detail_list": []map[string]interface{}{
+				{"id": "V001", "other_contribute": "1.00", "memo": "满减", "template_id": "TPL001"},
+			},
+		}
+	})
+	gateway.handle(MethodAlipayTradePrecreate, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{"out_trade_no": req.biz("out_trade_no"), "qr_code": "https://qr.alipay.com/x"}
+	})
+	gateway.handle(MethodAlipayTradeCreate, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{"out_trade_no": req.biz("out_trade_no"), "trade_no": "2020010122002"}
+	})
+	gateway.handle(MethodAlipayTradeRefund, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{"out_trade_no": req.biz("out_trade_no"), "refund_fee": req.biz("refund_amount")}
+	})
+
+	_, payResp, err := alipay.Pay(&PayParam{
+		OutTradeNo:      "T001",
+		Subject:         "商品",
+		TotalAmount:     "10.00",
+		GoodsDetailList: []*PayParamGoods{{GoodsID: "G001", GoodsName: "苹果", Quantity: 1, Price: "10.00"}},
+	}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payResp.VoucherDetailList) != 1 {
+		t.Fatalf("resp: %+v", payResp)
+	}
+	voucher := payResp.VoucherDetailList[0]
+	if voucher.OtherContribute != "1.00" || voucher.Meomo != "满减" || voucher.TemplateID != "TPL001" {
+		t.Fatalf("voucher: %+v", voucher)
+	}
+	if goodsList := goodsDetail(gateway.sent(MethodAlipayTradePay)[0]); len(goodsList) != 1 {
+		t.Fatalf("pay goods_detail: %v", goodsList)
+	}
+
+	if _, _, err := alipay.Precreate(&PrecreateParam{
+		OutTradeNo:      "T002",
+		Subject:         "商品",
+		TotalAmount:     "10.00",
+		GoodsDetailList: []*PrecreateParamGoods{{GoodsID: "G001", GoodsName: "苹果", Quantity: 1, Price: "10.00"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if goodsList := goodsDetail(gateway.sent(MethodAlipayTradePrecreate)[0]); len(goodsList) != 1 {
+		t.Fatalf("precreate goods_detail: %v", goodsList)
+	}
+
+	if _, _, err := alipay.Create(&CreateParam{OutTradeNo: "T003", Subject: "商品", TotalAmount: "10.00"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := gateway.sent(MethodAlipayTradeCreate)[0].Biz["goods_detail"]; ok {
+		t.Fatal("empty goods_detail should be omitted")
+	}
+
+	if _, _, err := alipay.Refund(&RefundParam{
+		OutTradeNo:     "T001",
+		RefundAmount:   "10.00",
+		GoodDetailList: []*RefundParamGoodsDetail{{GoodsID: "G001", GoodsName: "苹果"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	goodsList := goodsDetail(gateway.sent(MethodAlipayTradeRefund)[0])
+	if len(goodsList) != 1 {
+		t.Fatalf("refund goods_detail: %v", goodsList)
+	}
+	goods, _ := goodsList[0].(map[string]interface{})
+	if goods["goods_id"] != "G001" || goods["goods_name"] != "苹果" {
+		t.Fatalf("refund goods: %v", goods)
+	}
+}
@@ -0,0 +1,81 @@
+package alipay
+
+import (
+	"net/url"
+)
+
+// ParseUserAgreementAsyncResponse 解析签约、解约的异步通知，notify_type为dut_user_sign或dut_user_unsign
+func (alipay *Alipay) ParseUserAgreementAsyncResponse(values url.Values) (*UserAgreementAsyncResponse, error) {
+	if err := alipay.asyncVerifyRequest(values); err != nil {
+		return nil, ErrAsyncVerify
+	}
+
+	userAgreementAsyncResponse := new(UserAgreementAsyncResponse)
+
+	userAgreementAsyncResponse.NotifyTime = values.Get("notify_time")
+	userAgreementAsyncResponse.NotifyType = values.Get("notify_type")
+	userAgreementAsyncResponse.NotifyID = values.Get("notify_id")
+	userAgreementAsyncResponse.AppID = values.Get("app_id")
+	userAgreementAsyncResponse.Charset = values.Get("charset")
+	userAgreementAsyncResponse.Version = values.Get("version")
+	userAgreementAsyncResponse.SignType = values.Get("sign_type")
+	userAgreementAsyncResponse.Sign = values.Get("sign")
+	userAgreementAsyncResponse.AgreementNo = values.Get("agreement_no")
+	userAgreementAsyncResponse.ExternalAgreementNo = values.Get("external_agreement_no")
+	userAgreementAsyncResponse.PersonalProductCode = values.Get("personal_product_code")
+	userAgreementAsyncResponse.SignScene = values.Get("sign_scene")
+	userAgreementAsyncResponse.Status = values.Get("status")
+	userAgreementAsyncResponse.AlipayUserID = values.Get("alipay_user_id")
+	userAgreementAsyncResponse.AlipayLogonID = values.Get("alipay_logon_id")
+	userAgreementAsyncResponse.ExternalLogonID = values.Get("external_logon_id")
+	userAgreementAsyncResponse.PartnerID = values.Get("partner_id")
+	userAgreementAsyncResponse.MerchantAppID = values.Get("merchant_app_id")
+	userAgreementAsyncResponse.SignTime = values.Get("sign_time")
+	userAgreementAsyncResponse.ValidTime = values.Get("valid_time")
+	userAgreementAsyncResponse.InvalidTime = values.Get("invalid_time")
+	userAgreementAsyncResponse.UnsignTime = values.Get("unsign_time")
+	userAgreementAsyncResponse.SingleQuota = values.Get("single_quota")
+	userAgreementAsyncResponse.CreditAuthMode = values.Get("credit_auth_mode")
+	userAgreementAsyncResponse.ZmOpenID = values.Get("zm_open_id")
+
+	return userAgreementAsyncResponse, nil
+}
+
+// UserAgreementAsyncResponse ...
+type UserAgreementAsyncResponse struct {
+	NotifyTime          string
+	NotifyType          string
+	NotifyID            string
+	AppID               string
+	Charset             string
+	Version             string
+	SignType            string
+	Sign                string
+	AgreementNo         string
+	ExternalAgreementNo string
+	PersonalProductCode string
+	SignScene           string
+	Status              string
+	AlipayUserID        string
+	AlipayLogonID       string
+	ExternalLogonID     string
+	PartnerID           string
+	MerchantAppID       string
+	SignTime            string
+	ValidTime           string
+	InvalidTime         string
+	UnsignTime          string
+	SingleQuota         string
+	CreditAuthMode      string
+	ZmOpenID            string
+}
+
+// IsSign ...
+func (resp *UserAgreementAsyncResponse) IsSign() bool {
+	return resp.NotifyType == NotifyTypeDutUserSign && resp.Status == UserAgreementStatusNormal
+}
+
+// IsUnsign ...
+func (resp *UserAgreementAsyncResponse) IsUnsign() bool {
+	return resp.NotifyType == NotifyTypeDutUserUnsign
+}
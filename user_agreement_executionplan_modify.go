@@ -0,0 +1,48 @@
+// Package alipay https://opendocs.alipay.com/apis/api_2/alipay.user.agreement.executionplan.modify
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// UserAgreementExecutionplanModifyParam ...
+type UserAgreementExecutionplanModifyParam struct {
+	AgreementNo string `json:"agreement_no"`   // 周期性扣款产品，授权免密支付协议号
+	DeductTime  string `json:"deduct_time"`    // 商户下一次扣款时间，格式为yyyy-MM-dd
+	Memo        string `json:"memo,omitempty"` // 具体修改原因
+}
+
+// UserAgreementExecutionplanModifyResponse ...
+type UserAgreementExecutionplanModifyResponse struct {
+	ResponseError
+	AgreementNo string `json:"agreement_no"` // 周期性扣款产品，授权免密支付协议号
+	DeductTime  string `json:"deduct_time"`  // 商户下一次扣款时间
+}
+
+// UserAgreementExecutionplanModify 延期扣款，每个周期只能修改一次
+func (alipay *Alipay) UserAgreementExecutionplanModify(param *UserAgreementExecutionplanModifyParam, fillList ...Fill) (int, *UserAgreementExecutionplanModifyResponse, error) {
+	if len(param.AgreementNo) == 0 {
+		return 0, nil, errors.New("协议号不能为空")
+	}
+
+	if _, err := time.Parse("2006-01-02", param.DeductTime); err != nil {
+		return 0, nil, fmt.Errorf("扣款时间格式应为yyyy-MM-dd: %w", err)
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayUserAgreementExecutionplanModify,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	userAgreementExecutionplanModifyResponse := new(UserAgreementExecutionplanModifyResponse)
+	if err := json.Unmarshal(body, userAgreementExecutionplanModifyResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, userAgreementExecutionplanModifyResponse, nil
+}
@@ -0,0 +1,142 @@
+// Package alipay https://opendocs.alipay.com/apis/api_2/alipay.user.agreement.page.sign
+package alipay
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// AppSignScheme 在支付宝客户端中打开签约页面的地址
+const (
+	AppSignScheme = "alipays://platformapi/startapp?appId=60000157&appClearTop=false&startMultApp=YES&sign_params="
+)
+
+// UserAgreementPageSignParamAccessParams ...
+type UserAgreementPageSignParamAccessParams struct {
+	Channel string `json:"channel"` // 目前支持以下值：ALIPAYAPP（钱包h5页面签约）、QRCODE（扫码签约）、QRCODEORSMS（扫码签约或者短信签约）
+}
+
+// UserAgreementPageSignParamPeriodRuleParams 周期管控规则参数
+type UserAgreementPageSignParamPeriodRuleParams struct {
+	PeriodType    string `json:"period_type"`              // 周期类型，DAY或MONTH
+	Period        int    `json:"period"`                   // 周期数，与period_type组合使用确定扣款周期，周期类型为DAY时不小于7
+	ExecuteTime   string `json:"execute_time"`             // 商户发起首次扣款的时间，精确到日，格式为yyyy-MM-dd
+	SingleAmount  string `json:"single_amount"`            // 单次扣款最大金额，单位为元
+	TotalAmount   string `json:"total_amount,omitempty"`   // 周期内允许扣款的总金额，单位为元
+	TotalPayments int    `json:"total_payments,omitempty"` // 总扣款次数
+}
+
+// Validate 校验周期规则
+func (rule *UserAgreementPageSignParamPeriodRuleParams) Validate() error {
+	switch rule.PeriodType {
+	case UserAgreementPeriodTypeDay:
+		if rule.Period < 7 {
+			return errors.New("周期类型为DAY时周期数不能小于7")
+		}
+	case UserAgreementPeriodTypeMonth:
+		if rule.Period < 1 {
+			return errors.New("周期类型为MONTH时周期数不能小于1")
+		}
+	default:
+		return fmt.Errorf("不支持的周期类型: %s", rule.PeriodType)
+	}
+
+	if _, err := time.Parse("2006-01-02", rule.ExecuteTime); err != nil {
+		return fmt.Errorf("首次扣款时间格式应为yyyy-MM-dd: %w", err)
+	}
+
+	if Float64ifyPrice(rule.SingleAmount) <= 0 {
+		return errors.New("单次扣款最大金额必须大于0")
+	}
+
+	if len(rule.TotalAmount) > 0 && Float64ifyPrice(rule.TotalAmount) < Float64ifyPrice(rule.SingleAmount) {
+		return errors.New("周期内扣款总金额不能小于单次扣款最大金额")
+	}
+
+	return nil
+}
+
+// UserAgreementPageSignParam ...
+type UserAgreementPageSignParam struct {
+	PersonalProductCode string                                      `json:"personal_product_code"`           // 个人签约产品码，周期扣款固定为CYCLE_PAY_AUTH_P
+	ProductCode         string                                      `json:"product_code"`                    // 销售产品码，周期扣款固定为CYCLE_PAY_AUTH
+	SignScene           string                                      `json:"sign_scene"`                      // 协议签约场景，商户和支付宝签约时确定
+	ExternalAgreementNo string                                      `json:"external_agreement_no,omitempty"` // 商户签约号，代扣协议中标示用户的唯一签约号
+	ExternalLogonID     string                                      `json:"external_logon_id,omitempty"`     // 用户在商户网站的登录账号，用于在签约页面展示
+	AccessParams        *UserAgreementPageSignParamAccessParams     `json:"access_params"`                   // 请按当前接入的方式进行填充
+	PeriodRuleParams    *UserAgreementPageSignParamPeriodRuleParams `json:"period_rule_params,omitempty"`    // 周期管控规则参数，周期扣款产品必填
+	SignValidityPeriod  string                                      `json:"sign_validity_period,omitempty"`  // 当前用户签约请求的协议有效周期，例如2m、1d
+	ThirdPartyType      string                                      `json:"third_party_type,omitempty"`      // 签约第三方主体类型，默认为PARTNER
+	MerchantProcessURL  string                                      `json:"merchant_process_url,omitempty"`  // 签约成功后商户用于领取奖励的链接
+	PromoParams         string                                      `json:"promo_params,omitempty"`          // 签约营销参数，Json格式
+}
+
+func (param *UserAgreementPageSignParam) validate() error {
+	if len(param.PersonalProductCode) == 0 {
+		param.PersonalProductCode = UserAgreementPersonalProductCodeCyclePay
+	}
+
+	if len(param.ProductCode) == 0 {
+		param.ProductCode = UserAgreementProductCodeCyclePay
+	}
+
+	if len(param.SignScene) == 0 {
+		return errors.New("协议签约场景不能为空")
+	}
+
+	if param.AccessParams == nil || len(param.AccessParams.Channel) == 0 {
+		return errors.New("签约接入方式不能为空")
+	}
+
+	if param.ProductCode == UserAgreementProductCodeCyclePay {
+		if param.PeriodRuleParams == nil {
+			return errors.New("周期扣款的周期规则不能为空")
+		}
+		if err := param.PeriodRuleParams.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UserAgreementPageSign 页面签约，返回跳转到支付宝签约页面的地址，签约结果以异步通知为准
+func (alipay *Alipay) UserAgreementPageSign(param *UserAgreementPageSignParam, notifyURL string, returnURL string, fillList ...Fill) (string, error) {
+	if err := param.validate(); err != nil {
+		return "", err
+	}
+
+	paramStr, err := alipay.MakeParam(
+		param,
+		MethodAlipayUserAgreementPageSign,
+		append([]Fill{WithNotifyURL(notifyURL), WithReturnURL(returnURL)}, fillList...)...,
+	)
+	if err != nil {
+		return "", fmt.Errorf("支付宝协议签约构造参数失败: %w", err)
+	}
+	url, err := url.Parse(alipay.Gateway())
+	if err != nil {
+		return "", fmt.Errorf("解析支付宝网关失败: %w", err)
+	}
+	url.RawQuery = paramStr
+	return url.String(), nil
+}
+
+// UserAgreementAppSign App签约，返回在支付宝客户端中打开签约页面的地址，access_params.channel一般为ALIPAYAPP
+func (alipay *Alipay) UserAgreementAppSign(param *UserAgreementPageSignParam, notifyURL string, fillList ...Fill) (string, error) {
+	if err := param.validate(); err != nil {
+		return "", err
+	}
+
+	paramStr, err := alipay.MakeParam(
+		param,
+		MethodAlipayUserAgreementPageSign,
+		append([]Fill{WithNotifyURL(notifyURL)}, fillList...)...,
+	)
+	if err != nil {
+		return "", fmt.Errorf("支付宝协议签约构造参数失败: %w", err)
+	}
+	return AppSignScheme + url.QueryEscape(paramStr), nil
+}
@@ -0,0 +1,74 @@
+// Package alipay https://opendocs.alipay.com/apis/api_2/alipay.user.agreement.query
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// UserAgreementQueryParam agreement_no为空时，alipay_user_id/alipay_logon_id与external_agreement_no等组合查询
+type UserAgreementQueryParam struct {
+	PersonalProductCode string `json:"personal_product_code,omitempty"` // 协议产品码，周期扣款为CYCLE_PAY_AUTH_P
+	AlipayUserID        string `json:"alipay_user_id,omitempty"`        // 用户的支付宝账号对应的支付宝唯一用户号
+	AlipayLogonID       string `json:"alipay_logon_id,omitempty"`       // 用户的支付宝登录账号
+	SignScene           string `json:"sign_scene,omitempty"`            // 签约协议场景
+	ExternalAgreementNo string `json:"external_agreement_no,omitempty"` // 代扣协议中标示用户的唯一签约号
+	ThirdPartyType      string `json:"third_party_type,omitempty"`      // 签约第三方主体类型
+	AgreementNo         string `json:"agreement_no,omitempty"`          // 支付宝系统中用以唯一标识用户签约记录的编号
+}
+
+// UserAgreementQueryResponse ...
+type UserAgreementQueryResponse struct {
+	ResponseError
+	PrincipalID         string `json:"principal_id"`          // 签约主体标识
+	PrincipalOpenID     string `json:"principal_open_id"`     // 签约主体在应用下的唯一标识
+	ValidTime           string `json:"valid_time"`            // 用户代扣协议的实际生效时间
+	AlipayLogonID       string `json:"alipay_logon_id"`       // 返回脱敏的支付宝账号
+	InvalidTime         string `json:"invalid_time"`          // 用户代扣协议的失效时间
+	PricipalType        string `json:"pricipal_type"`         // 签约主体类型，CARD或CUSTOMER
+	DeviceID            string `json:"device_id"`             // 设备Id
+	SignScene           string `json:"sign_scene"`            // 签约协议的场景
+	AgreementNo         string `json:"agreement_no"`          // 用户签约成功后的协议号
+	ThirdPartyType      string `json:"third_party_type"`      // 签约第三方主体类型
+	Status              string `json:"status"`                // 协议当前状态，TEMP、NORMAL、STOP
+	SignTime            string `json:"sign_time"`             // 协议签约时间
+	PersonalProductCode string `json:"personal_product_code"` // 协议产品码
+	ExternalAgreementNo string `json:"external_agreement_no"` // 代扣协议中标示用户的唯一签约号
+	ZmOpenID            string `json:"zm_open_id"`            // 用户在芝麻信用的唯一标识
+	ExternalLogonID     string `json:"external_logon_id"`     // 外部登录Id
+	CreditAuthMode      string `json:"credit_auth_mode"`      // 授信模式
+	SingleQuota         string `json:"single_quota"`          // 单笔代扣额度
+	LastDeductTime      string `json:"last_deduct_time"`      // 周期扣协议，上次扣款成功时间
+	NextDeductTime      string `json:"next_deduct_time"`      // 周期扣协议，预计下次扣款时间
+}
+
+// IsNormal 协议生效中，可以扣款
+func (resp *UserAgreementQueryResponse) IsNormal() bool {
+	return resp.Success() && resp.Status == UserAgreementStatusNormal
+}
+
+// IsAgreementNotExist ...
+func (resp *UserAgreementQueryResponse) IsAgreementNotExist() bool {
+	return resp.SubCode == "USER_AGREEMENT_NOT_EXIST" || resp.SubCode == "AGREEMENT_NOT_EXIST"
+}
+
+// UserAgreementQuery ...
+func (alipay *Alipay) UserAgreementQuery(param *UserAgreementQueryParam, fillList ...Fill) (int, *UserAgreementQueryResponse, error) {
+	if len(param.AgreementNo) == 0 && len(param.ExternalAgreementNo) == 0 && len(param.AlipayUserID) == 0 && len(param.AlipayLogonID) == 0 {
+		return 0, nil, errors.New("协议号和用户信息不能同时为空")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayUserAgreementQuery,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	userAgreementQueryResponse := new(UserAgreementQueryResponse)
+	if err := json.Unmarshal(body, userAgreementQueryResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, userAgreementQueryResponse, nil
+}
@@ -0,0 +1,49 @@
+package alipay
+
+// UserAgreementStatus 协议状态
+const (
+	// UserAgreementStatusTemp 暂存，协议未生效过
+	UserAgreementStatusTemp = "TEMP"
+
+	// UserAgreementStatusNormal 正常
+	UserAgreementStatusNormal = "NORMAL"
+
+	// UserAgreementStatusStop 暂停
+	UserAgreementStatusStop = "STOP"
+)
+
+// UserAgreement ...
+const (
+	// UserAgreementPersonalProductCodeCyclePay 周期扣款个人签约产品码
+	UserAgreementPersonalProductCodeCyclePay = "CYCLE_PAY_AUTH_P"
+
+	// UserAgreementProductCodeCyclePay 周期扣款商家签约产品码
+	UserAgreementProductCodeCyclePay = "CYCLE_PAY_AUTH"
+
+	// UserAgreementProductCodeGeneralWithholding 商家扣款产品码，协议代扣时alipay.trade.pay使用
+	UserAgreementProductCodeGeneralWithholding = "GENERAL_WITHHOLDING"
+
+	// UserAgreementSignSceneDefault 默认签约场景
+	UserAgreementSignSceneDefault = "DEFAULT|DEFAULT"
+
+	// UserAgreementChannelAlipayApp 钱包h5页面签约
+	UserAgreementChannelAlipayApp = "ALIPAYAPP"
+
+	// UserAgreementChannelQRCode 扫码签约
+	UserAgreementChannelQRCode = "QRCODE"
+
+	// UserAgreementChannelQRCodeOrSMS 扫码或者短信页面签约
+	UserAgreementChannelQRCodeOrSMS = "QRCODEORSMS"
+
+	// UserAgreementPeriodTypeDay 周期类型为天，周期数不小于7
+	UserAgreementPeriodTypeDay = "DAY"
+
+	// UserAgreementPeriodTypeMonth 周期类型为月
+	UserAgreementPeriodTypeMonth = "MONTH"
+)
+
+// NotifyTypeDutUserSign ...
+const (
+	NotifyTypeDutUserSign   = "dut_user_sign"
+	NotifyTypeDutUserUnsign = "dut_user_unsign"
+)
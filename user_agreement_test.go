@@ -0,0 +1,218 @@
+package alipay
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func testAgreementSignParam() *UserAgreementPageSignParam {
+	return &UserAgreementPageSignParam{
+		SignScene:           UserAgreementSignSceneDefault,
+		ExternalAgreementNo: "E001",
+		AccessParams:        &UserAgreementPageSignParamAccessParams{Channel: UserAgreementChannelAlipayApp},
+		PeriodRuleParams: &UserAgreementPageSignParamPeriodRuleParams{
+			PeriodType:   UserAgreementPeriodTypeMonth,
+			Period:       1,
+			ExecuteTime:  "2020-01-02",
+			SingleAmount: "30.00",
+		},
+	}
+}
+
+func TestUserAgreementPeriodRuleValidate(t *testing.T) {
+	cases := []struct {
+		name  string
+		rule  UserAgreementPageSignParamPeriodRuleParams
+		valid bool
+	}{
+		{name: "month", rule: UserAgreementPageSignParamPeriodRuleParams{PeriodType: UserAgreementPeriodTypeMonth, Period: 1, ExecuteTime: "2020-01-02", SingleAmount: "30.00"}, valid: true},
+		{name: "day", rule: UserAgreementPageSignParamPeriodRuleParams{PeriodType: UserAgreementPeriodTypeDay, Period: 7, ExecuteTime: "2020-01-02", SingleAmount: "30.00", TotalAmount: "90.00"}, valid: true},
+		{name: "day less than 7", rule: UserAgreementPageSignParamPeriodRuleParams{PeriodType: UserAgreementPeriodTypeDay, Period: 6, ExecuteTime: "2020-01-02", SingleAmount: "30.00"}},
+		{name: "month zero", rule: UserAgreementPageSignParamPeriodRuleParams{PeriodType: UserAgreementPeriodTypeMonth, ExecuteTime: "2020-01-02", SingleAmount: "30.00"}},
+		{name: "unknown period type", rule: UserAgreementPageSignParamPeriodRuleParams{PeriodType: "YEAR", Period: 1, ExecuteTime: "2020-01-02", SingleAmount: "30.00"}},
+		{name: "execute time", rule: UserAgreementPageSignParamPeriodRuleParams{PeriodType: UserAgreementPeriodTypeMonth, Period: 1, ExecuteTime: "2020/01/02", SingleAmount: "30.00"}},
+		{name: "single amount", rule: UserAgreementPageSignParamPeriodRuleParams{PeriodType: UserAgreementPeriodTypeMonth, Period: 1, ExecuteTime: "2020-01-02", SingleAmount: "0"}},
+		{name: "total less than single", rule: UserAgreementPageSignParamPeriodRuleParams{PeriodType: UserAgreementPeriodTypeMonth, Period: 1, ExecuteTime: "2020-01-02", SingleAmount: "30.00", TotalAmount: "10.00"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.rule.Validate(); (err == nil) != c.valid {
+				t.Fatalf("err = %v, valid = %v", err, c.valid)
+			}
+		})
+	}
+}
+
+func TestUserAgreementPageSign(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	rawURL, err := alipay.UserAgreementPageSign(testAgreementSignParam(), "https://example.com/notify", "https://example.com/return")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := u.Query()
+	if values.Get("method") != MethodAlipayUserAgreementPageSign || values.Get("return_url") != "https://example.com/return" {
+		t.Fatalf("url = %s", rawURL)
+	}
+	bizContent := values.Get("biz_content")
+	if !strings.Contains(bizContent, `"personal_product_code":"CYCLE_PAY_AUTH_P"`) || !strings.Contains(bizContent, `"product_code":"CYCLE_PAY_AUTH"`) {
+		t.Fatalf("default product codes were not sent: %s", bizContent)
+	}
+
+	appURL, err := alipay.UserAgreementAppSign(testAgreementSignParam(), "https://example.com/notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(appURL, AppSignScheme) {
+		t.Fatalf("app url = %s", appURL)
+	}
+	signParams, err := url.QueryUnescape(strings.TrimPrefix(appURL, AppSignScheme))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err = url.ParseQuery(signParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values.Get("method") != MethodAlipayUserAgreementPageSign || len(values.Get("sign")) == 0 {
+		t.Fatalf("sign_params = %s", signParams)
+	}
+
+	param := testAgreementSignParam()
+	param.PeriodRuleParams = nil
+	if _, err := alipay.UserAgreementPageSign(param, "", ""); err == nil {
+		t.Fatal("cycle pay without period rule should be rejected")
+	}
+	param = testAgreementSignParam()
+	param.AccessParams = nil
+	if _, err := alipay.UserAgreementAppSign(param, ""); err == nil {
+		t.Fatal("sign without access params should be rejected")
+	}
+}
+
+func TestUserAgreementQuery(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayUserAgreementQuery, func(req *testRequest) map[string]interface{} {
+		if req.biz("external_agreement_no") != "E001" {
+			return map[string]interface{}{"code": "40004", "msg": "Business Failed", "sub_code": "USER_AGREEMENT_NOT_EXIST"}
+		}
+		return map[string]interface{}{"agreement_no": "20200102000001", "external_agreement_no": "E001", "status": UserAgreementStatusNormal}
+	})
+
+	_, resp, err := alipay.UserAgreementQuery(&UserAgreementQueryParam{ExternalAgreementNo: "E001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsNormal() || resp.AgreementNo != "20200102000001" {
+		t.Fatalf("resp: %+v", resp)
+	}
+
+	_, resp, err = alipay.UserAgreementQuery(&UserAgreementQueryParam{ExternalAgreementNo: "E002"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.IsNormal() || !resp.IsAgreementNotExist() {
+		t.Fatalf("resp: %+v", resp)
+	}
+
+	if _, _, err := alipay.UserAgreementQuery(&UserAgreementQueryParam{SignScene: UserAgreementSignSceneDefault}); err == nil {
+		t.Fatal("query without agreement or user should be rejected")
+	}
+}
+
+func TestUserAgreementUnsignAndModify(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayUserAgreementUnsign, func(req *testRequest) map[string]interface{} {
+		return nil
+	})
+	gateway.handle(MethodAlipayUserAgreementExecutionplanModify, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{"agreement_no": req.biz("agreement_no"), "deduct_time": req.biz("deduct_time")}
+	})
+
+	_, unsignResp, err := alipay.UserAgreementUnsign(&UserAgreementUnsignParam{AgreementNo: "20200102000001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !unsignResp.Success() {
+		t.Fatalf("unsign: %+v", unsignResp)
+	}
+	if _, _, err := alipay.UserAgreementUnsign(&UserAgreementUnsignParam{AlipayUserID: "2088000000000001"}); err == nil {
+		t.Fatal("unsign without agreement no should be rejected")
+	}
+
+	_, modifyResp, err := alipay.UserAgreementExecutionplanModify(&UserAgreementExecutionplanModifyParam{AgreementNo: "20200102000001", DeductTime: "2020-02-05"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !modifyResp.Success() || modifyResp.DeductTime != "2020-02-05" {
+		t.Fatalf("modify: %+v", modifyResp)
+	}
+	if _, _, err := alipay.UserAgreementExecutionplanModify(&UserAgreementExecutionplanModifyParam{AgreementNo: "20200102000001", DeductTime: "2020-02-05 10:00:00"}); err == nil {
+		t.Fatal("deduct time with clock should be rejected")
+	}
+}
+
+func TestPayAgreement(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayTradePay, func(req *testRequest) map[string]interface{} {
+		return map[string]interface{}{"out_trade_no": req.biz("out_trade_no"), "trade_no": "2020010222001"}
+	})
+
+	param := &PayParam{
+		OutTradeNo:      "T001",
+		Subject:         "会员月费",
+		TotalAmount:     "30.00",
+		AgreementParams: &PayParamAgreementParams{AgreementNo: "20200102000001"},
+	}
+	if _, _, err := alipay.PayAgreement(param, ""); err != nil {
+		t.Fatal(err)
+	}
+	req := gateway.sent(MethodAlipayTradePay)[0]
+	agreementParams, _ := req.Biz["agreement_params"].(map[string]interface{})
+	if req.biz("product_code") != UserAgreementProductCodeGeneralWithholding || agreementParams["agreement_no"] != "20200102000001" {
+		t.Fatalf("biz_content: %v", req.Biz)
+	}
+
+	if _, _, err := alipay.PayAgreement(&PayParam{OutTradeNo: "T002", TotalAmount: "30.00"}, ""); err == nil {
+		t.Fatal("pay without agreement no should be rejected")
+	}
+}
+
+func TestParseUserAgreementAsyncResponse(t *testing.T) {
+	alipay, _ := newTestAlipay(t)
+	values := testNotification(t, url.Values{
+		"notify_type":           {NotifyTypeDutUserSign},
+		"agreement_no":          {"20200102000001"},
+		"external_agreement_no": {"E001"},
+		"status":                {UserAgreementStatusNormal},
+	})
+	resp, err := alipay.ParseUserAgreementAsyncResponse(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsSign() || resp.IsUnsign() || resp.ExternalAgreementNo != "E001" {
+		t.Fatalf("resp: %+v", resp)
+	}
+
+	values = testNotification(t, url.Values{
+		"notify_type":  {NotifyTypeDutUserUnsign},
+		"agreement_no": {"20200102000001"},
+		"status":       {UserAgreementStatusStop},
+	})
+	resp, err = alipay.ParseUserAgreementAsyncResponse(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.IsSign() || !resp.IsUnsign() {
+		t.Fatalf("resp: %+v", resp)
+	}
+
+	values.Set("agreement_no", "20200102000002")
+	if _, err := alipay.ParseUserAgreementAsyncResponse(values); err != ErrAsyncVerify {
+		t.Fatalf("err = %v, want ErrAsyncVerify", err)
+	}
+}
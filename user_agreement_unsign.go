@@ -0,0 +1,46 @@
+// Package alipay https://opendocs.alipay.com/apis/api_2/alipay.user.agreement.unsign
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// UserAgreementUnsignParam ...
+type UserAgreementUnsignParam struct {
+	AlipayUserID        string `json:"alipay_user_id,omitempty"`        // 用户的支付宝账号对应的支付宝唯一用户号
+	AlipayLogonID       string `json:"alipay_logon_id,omitempty"`       // 用户的支付宝登录账号
+	PersonalProductCode string `json:"personal_product_code,omitempty"` // 协议产品码
+	SignScene           string `json:"sign_scene,omitempty"`            // 签约协议场景
+	ExternalAgreementNo string `json:"external_agreement_no,omitempty"` // 代扣协议中标示用户的唯一签约号
+	ThirdPartyType      string `json:"third_party_type,omitempty"`      // 签约第三方主体类型
+	AgreementNo         string `json:"agreement_no,omitempty"`          // 支付宝系统中用以唯一标识用户签约记录的编号
+	ExtendParams        string `json:"extend_params,omitempty"`         // 扩展参数，Json格式
+	OperateType         string `json:"operate_type,omitempty"`          // 操作类型，confirm（解约确认），invalid（解约作废）
+}
+
+// UserAgreementUnsignResponse ...
+type UserAgreementUnsignResponse struct {
+	ResponseError
+}
+
+// UserAgreementUnsign ...
+func (alipay *Alipay) UserAgreementUnsign(param *UserAgreementUnsignParam, fillList ...Fill) (int, *UserAgreementUnsignResponse, error) {
+	if len(param.AgreementNo) == 0 && len(param.ExternalAgreementNo) == 0 {
+		return 0, nil, errors.New("协议号和商户签约号不能同时为空")
+	}
+
+	statusCode, body, err := alipay.OnRequest(
+		param,
+		MethodAlipayUserAgreementUnsign,
+		fillList...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	userAgreementUnsignResponse := new(UserAgreementUnsignResponse)
+	if err := json.Unmarshal(body, userAgreementUnsignResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, userAgreementUnsignResponse, nil
+}
@@ -0,0 +1,43 @@
+// Package alipay https://opendocs.alipay.com/apis/api_2/alipay.user.info.share
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// UserInfoShareResponse ...
+type UserInfoShareResponse struct {
+	ResponseError
+	UserID             string `json:"user_id"`              // 支付宝用户的userId
+	OpenID             string `json:"open_id"`              // 支付宝用户在应用下的唯一标识
+	Avatar             string `json:"avatar"`               // 用户头像地址
+	Province           string `json:"province"`             // 省份名称
+	City               string `json:"city"`                 // 市名称
+	NickName           string `json:"nick_name"`            // 用户昵称
+	IsStudentCertified string `json:"is_student_certified"` // 是否是学生，T为是，F为否
+	UserType           string `json:"user_type"`            // 用户类型，1代表公司账户，2代表个人账户
+	UserStatus         string `json:"user_status"`          // 用户状态，Q代表快速注册用户，T代表已认证用户，B代表被冻结账户，W代表已注册未激活用户
+	IsCertified        string `json:"is_certified"`         // 是否通过实名认证，T是通过，F是没有实名认证
+	Gender             string `json:"gender"`               // 性别，F：女性；M：男性
+}
+
+// UserInfoShare accessToken为SystemOAuthToken换取的访问令牌，需要用户以auth_user授权
+func (alipay *Alipay) UserInfoShare(accessToken string, fillList ...Fill) (int, *UserInfoShareResponse, error) {
+	if len(accessToken) == 0 {
+		return 0, nil, errors.New("用户访问令牌不能为空")
+	}
+	statusCode, body, err := alipay.OnRequest(
+		nil,
+		MethodAlipayUserInfoShare,
+		append([]Fill{WithAuthToken(accessToken)}, fillList...)...,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	userInfoShareResponse := new(UserInfoShareResponse)
+	if err := json.Unmarshal(body, userInfoShareResponse); err != nil {
+		return 0, nil, err
+	}
+	return statusCode, userInfoShareResponse, nil
+}
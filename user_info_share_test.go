@@ -0,0 +1,33 @@
+package alipay
+
+import "testing"
+
+func TestUserInfoShare(t *testing.T) {
+	alipay, gateway := newTestAlipay(t)
+	gateway.handle(MethodAlipayUserInfoShare, func(req *testRequest) map[string]interface{} {
+		if req.Values.Get("auth_token") != "access-C1" {
+			return map[string]interface{}{"code": "20001", "msg": "Insufficient Token Permissions", "sub_code": "aop.invalid-auth-token"}
+		}
+		return map[string]interface{}{"user_id": "2088000000000001", "nick_name": "张三", "gender": "M"}
+	})
+
+	_, resp, err := alipay.UserInfoShare("access-C1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success() || resp.NickName != "张三" || resp.Gender != "M" {
+		t.Fatalf("resp: %+v", resp)
+	}
+
+	_, resp, err = alipay.UserInfoShare("other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Success() || resp.SubCode != "aop.invalid-auth-token" {
+		t.Fatalf("resp: %+v", resp)
+	}
+
+	if _, _, err := alipay.UserInfoShare(""); err == nil {
+		t.Fatal("empty access token should be rejected")
+	}
+}
@@ -2,8 +2,10 @@ package alipay
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/text/encoding/simplifiedchinese"
@@ -35,3 +37,62 @@ func GbkToUtf8(s []byte) ([]byte, error) {
 	}
 	return changed, nil
 }
+
+// Int64ifyCent 把以元为单位的金额精确转换成分，最多两位小数
+func Int64ifyCent(price string) (int64, error) {
+	price = strings.TrimSpace(price)
+	if len(price) == 0 {
+		return 0, nil
+	}
+
+	negative := false
+	if strings.HasPrefix(price, "-") || strings.HasPrefix(price, "+") {
+		negative = price[0] == '-'
+		price = price[1:]
+	}
+
+	yuan, fen := price, ""
+	if idx := strings.IndexByte(price, '.'); idx >= 0 {
+		yuan, fen = price[:idx], price[idx+1:]
+	}
+	if len(yuan) == 0 && len(fen) == 0 {
+		return 0, fmt.Errorf("金额格式错误: %s", price)
+	}
+	if len(fen) > 2 {
+		if strings.Trim(fen[2:], "0") != "" {
+			return 0, fmt.Errorf("金额精度超过分: %s", price)
+		}
+		fen = fen[:2]
+	}
+	for len(fen) < 2 {
+		fen += "0"
+	}
+	if len(yuan) == 0 {
+		yuan = "0"
+	}
+
+	y, err := strconv.ParseUint(yuan, 10, 62)
+	if err != nil {
+		return 0, fmt.Errorf("金额格式错误: %s", price)
+	}
+	f, err := strconv.ParseUint(fen, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("金额格式错误: %s", price)
+	}
+
+	cent := int64(y)*100 + int64(f)
+	if negative {
+		cent = -cent
+	}
+	return cent, nil
+}
+
+// StringifyCent 把分转换成以元为单位、两位小数的金额
+func StringifyCent(cent int64) string {
+	sign := ""
+	if cent < 0 {
+		sign = "-"
+		cent = -cent
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cent/100, cent%100)
+}
@@ -0,0 +1,35 @@
+package alipay
+
+import "testing"
+
+func TestInt64ifyCent(t *testing.T) {
+	cases := []struct {
+		price string
+		cent  int64
+		valid bool
+	}{
+		{price: "10.00", cent: 1000, valid: true},
+		{price: "0.01", cent: 1, valid: true},
+		{price: "3", cent: 300, valid: true},
+		{price: ".5", cent: 50, valid: true},
+		{price: "-1.20", cent: -120, valid: true},
+		{price: "1.230", cent: 123, valid: true},
+		{price: "", cent: 0, valid: true},
+		{price: "1.234"},
+		{price: "1,00"},
+		{price: "."},
+		{price: "abc"},
+	}
+	for _, c := range cases {
+		cent, err := Int64ifyCent(c.price)
+		if (err == nil) != c.valid || (c.valid && cent != c.cent) {
+			t.Errorf("Int64ifyCent(%q) = %d, %v", c.price, cent, err)
+		}
+	}
+
+	for cent, price := range map[int64]string{0: "0.00", 1: "0.01", 1000: "10.00", -120: "-1.20"} {
+		if StringifyCent(cent) != price {
+			t.Errorf("StringifyCent(%d) = %s, want %s", cent, StringifyCent(cent), price)
+		}
+	}
+}
@@ -45,7 +45,7 @@ type WapPayParam struct {
 }
 
 // WapPay ...
-func (alipay *Alipay) WapPay(param *WapPayParam, notifyURL string, returnURL string) (string, error) {
+func (alipay *Alipay) WapPay(param *WapPayParam, notifyURL string, returnURL string, fillList ...Fill) (string, error) {
 	param.ProductCode = "QUICK_WAP_WAY"
 
 	if len(param.OutTradeNo) == 0 {
@@ -67,13 +67,34 @@ func (alipay *Alipay) WapPay(param *WapPayParam, notifyURL string, returnURL str
 	paramStr, err := alipay.MakeParam(
 		param,
 		MethodAlipayTradeWapPay,
-		WithNotifyURL(notifyURL),
-		WithReturnURL(returnURL),
+		append([]Fill{WithNotifyURL(notifyURL), WithReturnURL(returnURL)}, fillList...)...,
 	)
 	if err != nil {
 		return "", fmt.Errorf("支付宝wap支付构造参数失败: %w", err)
 	}
-	url, err := url.Parse(AlipayGateway)
+	url, err := url.Parse(alipay.Gateway())
+	if err != nil {
+		return "", fmt.Errorf("解析支付宝网关失败: %w", err)
+	}
+	url.RawQuery = paramStr
+	return url.String(), nil
+}
+
+// WapMergePay 合并支付，preOrderNo为MergePrecreate返回的预下单号，各子订单的支付结果分别异步通知
+func (alipay *Alipay) WapMergePay(preOrderNo string, returnURL string, fillList ...Fill) (string, error) {
+	if len(preOrderNo) == 0 {
+		return "", errors.New("合并支付预下单号不能为空")
+	}
+
+	paramStr, err := alipay.MakeParam(
+		&MergePayParam{PreOrderNo: preOrderNo},
+		MethodAlipayTradeWapMergePay,
+		append([]Fill{WithReturnURL(returnURL)}, fillList...)...,
+	)
+	if err != nil {
+		return "", fmt.Errorf("支付宝wap合并支付构造参数失败: %w", err)
+	}
+	url, err := url.Parse(alipay.Gateway())
 	if err != nil {
 		return "", fmt.Errorf("解析支付宝网关失败: %w", err)
 	}